@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"xis-data-aggregator/config"
+	"xis-data-aggregator/internal/backup"
+	"xis-data-aggregator/internal/models"
+)
+
+// runBackup writes an archive of the records of all tenants, or of one, in the configured repository:
+//
+//	backup [-tenant t] file
+//
+// The archive is written next to the file and renamed once complete.
+func runBackup(cfg *config.XisDataAggregatorConfig, args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	tenant := fs.String("tenant", "", "back up only this tenant (default: all tenants)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: backup [-tenant t] file")
+	}
+	path := fs.Arg(0)
+
+	repo, err := openRepository(cfg)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	tenants := []string{*tenant}
+	if *tenant == "" {
+		if tenants, err = repo.Tenants(); err != nil {
+			return err
+		}
+	} else if err := models.ValidateTenant(*tenant); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // fails once renamed
+
+	m, err := backup.Write(f, repo, tenants)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %d records of %d tenants, sha256 %s\n", path, m.Records, len(m.Tenants), m.SHA256)
+	return nil
+}
+
+// runRestore verifies an archive and restores its records into the configured repository:
+//
+//	restore [-tenant t] [-verify] file
+//
+// Records are restored into their archived tenants, or all into the tenant of -tenant.
+// With -verify the archive is only verified.
+func runRestore(cfg *config.XisDataAggregatorConfig, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	tenant := fs.String("tenant", "", "restore all records into this tenant (default: their archived tenants)")
+	verify := fs.Bool("verify", false, "only verify the archive")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: restore [-tenant t] [-verify] file")
+	}
+	if err := models.ValidateTenant(*tenant); err != nil {
+		return err
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if *verify {
+		m, err := backup.Verify(f)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s: valid, version %d, created %s, %d records, tenants %v\n", fs.Arg(0), m.Version, m.Created, m.Records, m.Tenants)
+		return nil
+	}
+
+	repo, err := openRepository(cfg)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	var into func(string) string
+	if *tenant != "" {
+		into = func(string) string { return *tenant }
+	}
+	res, err := backup.Restore(f, repo, into)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %d of %d records restored, %d older than the retention skipped\n", fs.Arg(0), res.Restored, res.Records, res.Expired)
+	return nil
+}
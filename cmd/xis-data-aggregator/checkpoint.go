@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// readCheckpointFile reads the JSON checkpoint file at path into v, false if it does not exist.
+func readCheckpointFile(path string, v interface{}) (bool, error) {
+	b, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+
+	if err := json.Unmarshal(b, v); err != nil {
+		return false, fmt.Errorf("invalid checkpoint file %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// writeCheckpointFile replaces the checkpoint file at path with v as JSON. The file is written aside and renamed
+// over the previous one, so that an interrupted write leaves the previous checkpoint.
+func writeCheckpointFile(path string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"xis-data-aggregator/config"
+	"xis-data-aggregator/internal/repository"
+)
+
+// runCommand runs the command named by the first argument with the remaining arguments.
+func runCommand(cfg *config.XisDataAggregatorConfig, args []string) error {
+	switch args[0] {
+	case "import":
+		return runImport(cfg, args[1:])
+	case "backup":
+		return runBackup(cfg, args[1:])
+	case "restore":
+		return runRestore(cfg, args[1:])
+	case "migrate":
+		return runMigrate(cfg, args[1:])
+	}
+	return fmt.Errorf("unknown command %q, expected import, backup, restore or migrate", args[0])
+}
+
+// errNoRedisAddr is returned by commands run without a Redis server address: unlike the service, they
+// must not fall back to the embedded server, whose records are discarded when the command exits.
+var errNoRedisAddr = errors.New("no Redis server address, set -redisAddr")
+
+// openRepository connects to the configured Redis server and applies the configured retention.
+func openRepository(cfg *config.XisDataAggregatorConfig) (*repository.RedisRepository, error) {
+	return openRepositoryAt(cfg, cfg.RedisAddr)
+}
+
+// openRepositoryAt connects to the Redis server at addr and applies the configured retention.
+// Returns errNoRedisAddr if addr is empty.
+func openRepositoryAt(cfg *config.XisDataAggregatorConfig, addr string) (*repository.RedisRepository, error) {
+	if addr == "" {
+		return nil, errNoRedisAddr
+	}
+
+	retention, err := repository.ParseRetention(cfg.Retention)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := repository.NewRedisRepositoryAt(addr)
+	if err != nil {
+		return nil, fmt.Errorf("repository connection error: %w", err)
+	}
+	repo.SetRetention(retention)
+	return repo, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"xis-data-aggregator/config"
+	"xis-data-aggregator/internal/importer"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/repository"
+	"xis-data-aggregator/internal/service"
+
+	"github.com/golang/glog"
+)
+
+// importCheckpoint is the state of a file in the checkpoint file of the import command.
+type importCheckpoint struct {
+	Records int64 `json:"records"` // Records imported, skipped on resume
+	Done    bool  `json:"done"`    // The file is imported completely
+}
+
+// runImport imports Pack files into the configured repository:
+//
+//	import [-tenant t] [-format csv|ndjson] [-batch n] [-checkpoint file] [-dryRun] file...
+//
+// With a checkpoint file, the state of every file is saved after each stored batch, and a rerun with the same
+// checkpoint file skips the files and records imported before.
+func runImport(cfg *config.XisDataAggregatorConfig, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	tenant := fs.String("tenant", models.DefaultTenant, "tenant of the imported packs (default: the default tenant)")
+	format := fs.String("format", "", "file format: csv or ndjson (default: by file extension)")
+	batchSize := fs.Int("batch", importer.DefaultBatchSize, "packs stored per batch")
+	checkpointFile := fs.String("checkpoint", "", "checkpoint file to resume an interrupted import from")
+	dryRun := fs.Bool("dryRun", false, "validate the packs without storing them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("no files, usage: import [flags] file...")
+	}
+	if err := models.ValidateTenant(*tenant); err != nil {
+		return err
+	}
+
+	precision, err := models.ParseTimestampPrecision(cfg.TimestampPrecision)
+	if err != nil {
+		return err
+	}
+
+	checkpoints := make(map[string]importCheckpoint)
+	if *checkpointFile != "" && !*dryRun {
+		if _, err = readCheckpointFile(*checkpointFile, &checkpoints); err != nil {
+			return err
+		}
+	}
+
+	var repo *repository.RedisRepository
+	if *dryRun && cfg.RedisAddr == "" {
+		repo, err = repository.NewRedisRepository() // nothing is stored, the embedded server will do
+	} else {
+		repo, err = openRepository(cfg)
+	}
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	// One import for all files, so that the series value types of a dry run carry over between them
+	imp := service.NewDataService(repo).ForTenant(*tenant).NewImport(*dryRun)
+
+	var total importer.Progress
+	for _, path := range fs.Args() {
+		key, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		checkpoint := checkpoints[key]
+		if checkpoint.Done {
+			fmt.Printf("%s: imported before, skipped\n", path)
+			continue
+		}
+
+		progress, err := importFile(path, imp, *format, importer.Options{
+			Precision:  precision,
+			BatchSize:  *batchSize,
+			Checkpoint: checkpoint.Records,
+			Progress: func(p importer.Progress) error {
+				fmt.Printf("%s: %d records, %d imported, %d failed\n", path, p.Records, p.Imported, p.Failed)
+				if *checkpointFile == "" || *dryRun {
+					return nil
+				}
+				checkpoints[key] = importCheckpoint{Records: p.Records}
+				if err := writeCheckpointFile(*checkpointFile, checkpoints); err != nil {
+					glog.Errorf("Import checkpoint write error: %v", err)
+				}
+				return nil
+			},
+		})
+
+		for _, e := range progress.Errors {
+			fmt.Printf("%s: record %d: %s\n", path, e.Record, e.Error)
+		}
+		if progress.Failed > int64(len(progress.Errors)) {
+			fmt.Printf("%s: %d more failed records\n", path, progress.Failed-int64(len(progress.Errors)))
+		}
+		if err != nil {
+			return fmt.Errorf("%s: stopped after record %d: %w", path, progress.Records, err)
+		}
+
+		total.Records += progress.Records
+		total.Skipped += progress.Skipped
+		total.Imported += progress.Imported
+		total.Failed += progress.Failed
+
+		if *checkpointFile != "" && !*dryRun {
+			checkpoints[key] = importCheckpoint{Records: progress.Records, Done: true}
+			if err := writeCheckpointFile(*checkpointFile, checkpoints); err != nil {
+				return err
+			}
+		}
+	}
+
+	verb := "imported"
+	if *dryRun {
+		verb = "valid (dry run)"
+	}
+	fmt.Printf("%d records, %d skipped, %d %s, %d failed\n", total.Records, total.Skipped, total.Imported, verb, total.Failed)
+	return nil
+}
+
+// importFile imports a Pack file in the given format, or in the format of its extension if format is empty.
+func importFile(path string, sink importer.Sink, format string, opts importer.Options) (importer.Progress, error) {
+	var err error
+	if format != "" {
+		opts.Format, err = importer.ParseFormat(format)
+	} else {
+		opts.Format, err = importer.FormatOf(path)
+	}
+	if err != nil {
+		return importer.Progress{}, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return importer.Progress{}, err
+	}
+	defer f.Close()
+
+	return importer.Import(f, sink, opts)
+}
@@ -1,24 +1,36 @@
 package main
 
 import (
+	"crypto/tls"
+	"errors"
 	"flag"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"runtime/debug"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 	"xis-data-aggregator/config"
 	_ "xis-data-aggregator/docs"
+	"xis-data-aggregator/internal/alerting"
+	"xis-data-aggregator/internal/anomaly"
 	grpcapi "xis-data-aggregator/internal/api/grpc"
 	"xis-data-aggregator/internal/api/rest"
+	"xis-data-aggregator/internal/auth"
+	"xis-data-aggregator/internal/hub"
 	"xis-data-aggregator/internal/metrics"
 	"xis-data-aggregator/internal/mocks"
 	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/ratelimit"
 	"xis-data-aggregator/internal/repository"
 	"xis-data-aggregator/internal/service"
+	"xis-data-aggregator/internal/tlsreload"
+	"xis-data-aggregator/internal/webhook"
+	"xis-data-aggregator/internal/windowing"
 	"xis-data-aggregator/pkg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -26,6 +38,7 @@ import (
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // @title           XIS Data Aggregator API
@@ -33,6 +46,12 @@ import (
 // @description     This is the API for the XIS Data Aggregator service.
 // @host      localhost:8080 // Or your actual host and port
 // @BasePath  /api/v1 // Base path for your API endpoints
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 // Entry point for the XIS Data Aggregator service
 func main() {
 	// Defer a panic handler to log any unexpected errors and flush logs on exit
@@ -45,19 +64,39 @@ func main() {
 		glog.Flush() // Flush logs.
 	}()
 
-	flag.Parse() // Parse command-line flags
-
 	// Read configuration from file/environment and handle errors
 	cfg, err := config.GetXisDataAggregatorConfig()
 	if err != nil {
 		glog.Fatalf("init fail, config.GetXisDataAggregatorConfig() error: %v", err)
 	}
 
-	// Optionally override config values with command-line flags
+	// Optionally override config values with command-line flags (also parses glog flags)
 	cfg.UpdateConfigFromFlags()
 
+	// Commands run instead of the service, e.g. `xis-data-aggregator -redisAddr=localhost:6379 import packs.csv`
+	if flag.NArg() > 0 {
+		if err := runCommand(cfg, flag.Args()); err != nil {
+			glog.Errorf("%s: %v", flag.Arg(0), err) // also written to stderr
+			glog.Flush()
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Initialize API authentication (nil when no keys are configured)
+	authenticator, err := auth.NewAuthenticator(cfg)
+	if err != nil {
+		glog.Fatalf("init fail, auth.NewAuthenticator() error: %v", err)
+	}
+	if authenticator == nil {
+		glog.Warningln("Authentication is disabled: no API keys or JWT keys configured")
+	}
+
 	// Initialize Redis repository (database connection)
-	repo, err := repository.NewRedisRepository()
+	repo, err := repository.NewRedisRepositoryAt(cfg.RedisAddr)
+	if err != nil {
+		glog.Fatalf("init fail, repository.NewRedisRepositoryAt() error: %v", err)
+	}
 	defer func(repo *repository.RedisRepository) {
 		err := repo.Close()
 		if err != nil {
@@ -66,19 +105,97 @@ func main() {
 	}(repo)
 	glog.Infoln("Connected to DB")
 
+	// Apply per tenant retention
+	retention, err := repository.ParseRetention(cfg.Retention)
+	if err != nil {
+		glog.Fatalf("init fail, repository.ParseRetention() error: %v", err)
+	}
+	repo.SetRetention(retention)
+
+	// Integer timestamps exchanged with clients are converted from/to the stored Unix microseconds
+	precision, err := models.ParseTimestampPrecision(cfg.TimestampPrecision)
+	if err != nil {
+		glog.Fatalf("init fail, models.ParseTimestampPrecision() error: %v", err)
+	}
+
 	// Create the main data service with the repository
 	var dataService = service.NewDataService(repo)
+	dataService.SetTenantStats(metrics.NewTenantStats())
+	dataService.SetMaxQuerySpan(cfg.MaxQuerySpan)
+	dataService.SetHub(hub.New(cfg.SubscriberBuffer))
+	dataService.SetDetector(anomaly.New(cfg.AnomalyWindow, cfg.AnomalyAlpha, cfg.AnomalyThreshold))
+
+	// Alert rules from the rules file and those created via the API
+	var configRules []models.Rule
+	if cfg.AlertRulesFile != "" {
+		configRules, err = alerting.ReadRulesFile(cfg.AlertRulesFile)
+		if err != nil {
+			glog.Fatalf("init fail, alerting.ReadRulesFile() error: %v", err)
+		}
+	}
+	alerts := alerting.New(repo)
+	if err := alerts.Load(configRules); err != nil {
+		glog.Fatalf("init fail, alerting.Engine.Load() error: %v", err)
+	}
+	dataService.SetAlerting(alerts)
+
+	// Webhook notifications of stored records (disabled without an endpoints file)
+	var webhooks *webhook.Dispatcher
+	if cfg.WebhooksFile != "" {
+		endpoints, err := webhook.ReadEndpointsFile(cfg.WebhooksFile)
+		if err != nil {
+			glog.Fatalf("init fail, webhook.ReadEndpointsFile() error: %v", err)
+		}
+		webhooks, err = webhook.New(endpoints, repo, cfg.WebhookQueue, cfg.WebhookMaxAttempts)
+		if err != nil {
+			glog.Fatalf("init fail, webhook.New() error: %v", err)
+		}
+		dataService.SetWebhooks(webhooks)
+	}
+
+	// Sliding and session window aggregation (disabled without a window size or session gap)
+	if cfg.WindowSize > 0 || cfg.SessionGap > 0 {
+		windows, err := windowing.New(repo, cfg.WindowSize, cfg.WindowSlide, cfg.SessionGap, cfg.AllowedLateness)
+		if err != nil {
+			glog.Fatalf("init fail, windowing.New() error: %v", err)
+		}
+		dataService.SetWindows(windows)
+	}
+
+	// Per-client rate limiters for reads and ingestion (nil when disabled)
+	readLimiter := ratelimit.NewLimiter(float64(cfg.ReadRatePerSec), cfg.ReadBurst)
+	ingestLimiter := ratelimit.NewLimiter(float64(cfg.IngestRatePerSec), cfg.IngestBurst)
 
 	// Initialize channels for inter-goroutine communication
 	inputPacks := make(chan *models.Pack)
 	metricsChan := make(chan bool)
 	stopChan := make(chan struct{})
-	sigChan := make(chan os.Signal)
+	sigChan := make(chan os.Signal, 1)
 	glog.Infoln("Channels created")
 
 	// Set up signal handling
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
+	// Open the input recording to replay, or to record the simulated packs to. Closed once the workers
+	// have drained the input, so after the last recorded pack.
+	var recording *os.File
+	switch {
+	case cfg.InputReplayFile != "" && cfg.InputRecordFile != "":
+		glog.Fatalln("init fail, an input recording can't be replayed and recorded at once")
+	case cfg.InputReplaySpeed < 0:
+		glog.Fatalln("init fail, the input replay speed must not be negative")
+	case cfg.InputReplayFile != "":
+		recording, err = os.Open(cfg.InputReplayFile)
+	case cfg.InputRecordFile != "":
+		recording, err = os.Create(cfg.InputRecordFile)
+	}
+	if err != nil {
+		glog.Fatalf("init fail, input recording error: %v", err)
+	}
+	if recording != nil {
+		defer recording.Close()
+	}
+
 	// Use a WaitGroup to manage goroutines and ensure clean shutdown
 	var wg sync.WaitGroup
 	defer func() {
@@ -102,25 +219,82 @@ func main() {
 		wg.Add(1)
 	}
 
-	// Create and start the mock input pack generator (simulates incoming data)
-	inputPacksGenerator := mocks.InputPacksGenerator{
-		Interval:   time.Duration(cfg.InputIntervalMs) * time.Millisecond,
-		PackLength: cfg.PackLength,
-		OutputChan: inputPacks,
-		StopChan:   stopChan}
+	if cfg.InputReplayFile != "" {
+		// Replay a recording of the generator instead of simulating new packs
+		replayer := mocks.Replayer{
+			Recording:  recording,
+			Speed:      cfg.InputReplaySpeed,
+			OutputChan: inputPacks,
+			StopChan:   stopChan}
 
-	go inputPacksGenerator.Start(cfg)
-	glog.Infoln("Pack generator started")
+		go replayer.Start()
+		glog.Infof("Pack replay of %s started", cfg.InputReplayFile)
+	} else {
+		// Create and start the mock input pack generator (simulates incoming data)
+		inputPacksGenerator := mocks.InputPacksGenerator{
+			Interval:   time.Duration(cfg.InputIntervalMs) * time.Millisecond,
+			PackLength: cfg.PackLength,
+			OutputChan: inputPacks,
+			StopChan:   stopChan}
+		if cfg.InputSeed != nil {
+			inputPacksGenerator.Source = mocks.NewPackSource(*cfg.InputSeed, nil)
+		}
+		if recording != nil {
+			inputPacksGenerator.Recorder = recording
+		}
+
+		go inputPacksGenerator.Start(cfg)
+		glog.Infoln("Pack generator started")
+	}
+
+	if webhooks != nil {
+		go webhooks.Run(cfg.WebhookWorkers, stopChan)
+		glog.Infoln("Webhook dispatcher started")
+	}
+
+	// Load TLS certificates and watch them for rotation (nil when TLS is not configured)
+	var certReloader *tlsreload.Reloader
+	if cfg.TLSEnabled() {
+		certReloader, err = tlsreload.NewReloader(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSClientCAFile)
+		if err != nil {
+			glog.Fatalf("init fail, tlsreload.NewReloader() error: %v", err)
+		}
+		go certReloader.Watch(time.Duration(cfg.TLSReloadIntervalSec)*time.Second, stopChan)
+		glog.Infoln("TLS enabled, certificate watcher started")
+	}
 
 	// Start the gRPC server in a separate goroutine
+	// Deletes share the ingestion (write) limits
+	rateLimits := grpcapi.RateLimits{
+		auth.ScopeRead:   readLimiter,
+		auth.ScopeIngest: ingestLimiter,
+		auth.ScopeDelete: ingestLimiter,
+		auth.ScopeAdmin:  ingestLimiter,
+	}
 	go func() {
 		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GrpcPort)) // /api/v2
 		if err != nil {
 			glog.Fatalf("failed to listen: %v", err)
 		}
 
-		s := grpc.NewServer()
-		grpcapi.RegisterDataServiceServer(s, dataService)
+		opts := []grpc.ServerOption{
+			grpc.ChainUnaryInterceptor(
+				grpcapi.UnaryAuthInterceptor(authenticator),
+				grpcapi.UnaryRateLimitInterceptor(rateLimits),
+			),
+			grpc.ChainStreamInterceptor(
+				grpcapi.StreamAuthInterceptor(authenticator),
+				grpcapi.StreamRateLimitInterceptor(rateLimits),
+			),
+		}
+		if certReloader != nil {
+			clientAuth := tlsreload.ClientAuthType(cfg.TLSClientCAFile, cfg.TLSRequireClientCert)
+			opts = append(opts, grpc.Creds(credentials.NewTLS(certReloader.ServerConfig(clientAuth))))
+		}
+
+		s := grpc.NewServer(opts...)
+		grpcapi.RegisterDataServiceServer(s, dataService, precision)
+		grpcapi.RegisterHealthServer(s, dataService)
 
 		glog.Infof("gRPC Server started at %v", lis.Addr())
 		if err := s.Serve(lis); err != nil {
@@ -130,20 +304,85 @@ func main() {
 
 	// Set up and start the REST API server using Gin
 	gin.SetMode(gin.ReleaseMode)
-	h := rest.NewDataServiceServer(dataService)
-	r := gin.Default()
+	h := rest.NewDataServiceServer(dataService, precision)
+	h.SetIngestLimiter(ingestLimiter)
+	h.SetMaxUploadSize(cfg.MaxUploadMB << 20)
+	r := gin.New()
+	r.Use(rest.LoggerMiddleware(), gin.Recovery())
+
+	// Without trusted proxies the client IP keying the rate limits is the connection address and can't be
+	// spoofed with X-Forwarded-For headers
+	var trustedProxies []string
+	for _, proxy := range strings.Split(cfg.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		glog.Fatalf("init fail, invalid trusted proxies: %v", err)
+	}
 
 	v1 := r.Group("/api/v1")
-	v1.GET("data/:id", h.GetByID)
-	v1.GET("data", h.ListByTimeRange)
+	v1.GET("health", h.Health)
+
+	readAuth, readLimit := rest.AuthMiddleware(authenticator, auth.ScopeRead), rest.RateLimitMiddleware(readLimiter)
+	read := v1.Group("", readAuth, readLimit)
+	read.GET("data/:id", h.GetByID)
+	read.GET("data", h.ListByTimeRange)
+	read.GET("data/downsample", h.Downsample)
+	read.GET("stats", h.Stats)
+	read.GET("rules", h.ListRules)
+	read.GET("rules/:id", h.GetRule)
+	read.GET("alerts", h.ListAlerts)
+	read.GET("anomalies", h.ListAnomalies)
+	read.GET("windows", h.ListWindows)
+
+	// Live feeds also take the credential from the query, browsers can't set headers on them
+	feeds := v1.Group("data", rest.QueryTokenMiddleware(), readAuth, readLimit)
+	feeds.GET("stream", h.Stream)
+	feeds.GET("ws", h.WebSocket)
+
+	ingest := v1.Group("", rest.AuthMiddleware(authenticator, auth.ScopeIngest), rest.RateLimitMiddleware(ingestLimiter))
+	ingest.POST("packs", h.IngestPacks)
+	ingest.POST("import", h.Import)
+
+	del := v1.Group("", rest.AuthMiddleware(authenticator, auth.ScopeDelete), rest.RateLimitMiddleware(ingestLimiter))
+	del.DELETE("data/:id", h.Delete)
+
+	admin := v1.Group("admin", rest.AuthMiddleware(authenticator, auth.ScopeAdmin), rest.RateLimitMiddleware(ingestLimiter))
+	admin.DELETE("data", h.DeleteByTimeRange)
+	admin.GET("backup", h.Backup)
+	admin.POST("restore", h.Restore)
+	admin.GET("webhooks", h.ListWebhooks)
+	admin.GET("webhooks/deliveries", h.ListWebhookDeliveries)
+	admin.POST("webhooks/:id/test", h.TestWebhook)
+
+	rules := v1.Group("rules", rest.AuthMiddleware(authenticator, auth.ScopeAdmin), rest.RateLimitMiddleware(ingestLimiter))
+	rules.POST("", h.CreateRule)
+	rules.PUT(":id", h.UpdateRule)
+	rules.DELETE(":id", h.DeleteRule)
+
+	// Custom methods (e.g. POST /api/v1/data:batchGet), each with the middlewares of its scope
+	v1.POST(":"+rest.CustomMethodParam, rest.CustomMethods(map[string]gin.HandlersChain{
+		"data:batchGet": {readAuth, readLimit, h.BatchGet},
+	}))
+
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// Start the REST server and listen for HTTP requests
+	// Start the REST server and listen for HTTP(S) requests
+	srv := &http.Server{Addr: fmt.Sprintf(":%d", cfg.RestPort), Handler: r}
 	go func() {
-		glog.Infof("REST Server starting on port %d", cfg.RestPort)
-		err = r.Run(fmt.Sprintf(":%d", cfg.RestPort))
-		if err != nil {
-			glog.Fatalf("gin.Run() error: %v", err)
+		glog.Infof("REST Server starting on port %d (TLS: %v)", cfg.RestPort, certReloader != nil)
+
+		var err error
+		if certReloader != nil {
+			srv.TLSConfig = certReloader.ServerConfig(tls.NoClientCert)
+			err = srv.ListenAndServeTLS("", "") // certificates come from TLSConfig
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			glog.Fatalf("http.Server.ListenAndServe() error: %v", err)
 		}
 	}()
 
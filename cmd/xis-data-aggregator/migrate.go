@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+	"xis-data-aggregator/config"
+	"xis-data-aggregator/internal/api"
+	"xis-data-aggregator/internal/migrate"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/golang/glog"
+)
+
+// migrateCheckpoint is the checkpoint file of the migrate command: the migrated range and its verified chunks.
+type migrateCheckpoint struct {
+	From   int64                     `json:"from"`
+	To     int64                     `json:"to"`
+	Chunk  int64                     `json:"chunk"`
+	Chunks map[string]migrate.Result `json:"chunks"` // Verified chunks by key, skipped on resume
+}
+
+// runMigrate copies the records of all tenants, or of one, from the configured repository to another Redis server:
+//
+//	migrate -to addr [-tenant t] [-from ts] [-until ts] [-chunk d] [-parallel n] [-checkpoint file]
+//
+// The range [from, until) is copied in chunks of the chunk duration by parallel workers; every chunk is verified
+// by reading it back from the destination and comparing the record count and checksum. With a checkpoint file,
+// verified chunks are saved and a rerun with the same checkpoint file resumes the migration of the same range.
+func runMigrate(cfg *config.XisDataAggregatorConfig, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	to := fs.String("to", "", "address of the destination Redis server")
+	tenant := fs.String("tenant", "", "migrate only this tenant (default: all tenants)")
+	fromTs := fs.String("from", "", "start of the range, inclusive (default: the earliest record)")
+	untilTs := fs.String("until", "", "end of the range, exclusive (default: now)")
+	chunk := fs.Duration("chunk", time.Hour, "time range of a chunk")
+	parallel := fs.Int("parallel", 4, "chunks migrated in parallel")
+	checkpointFile := fs.String("checkpoint", "", "checkpoint file to resume an interrupted migration from")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	switch {
+	case fs.NArg() != 0:
+		return errors.New("usage: migrate -to addr [flags]")
+	case *to == "":
+		return errors.New("no destination, set -to")
+	case *to == cfg.RedisAddr:
+		return errors.New("the destination is the source repository")
+	case *chunk <= 0:
+		return errors.New("invalid chunk, must be positive")
+	}
+
+	precision, err := models.ParseTimestampPrecision(cfg.TimestampPrecision)
+	if err != nil {
+		return err
+	}
+
+	checkpoint := migrateCheckpoint{Chunk: chunk.Microseconds(), Chunks: make(map[string]migrate.Result)}
+	resumed := false
+	if *checkpointFile != "" {
+		if resumed, err = readCheckpointFile(*checkpointFile, &checkpoint); err != nil {
+			return err
+		}
+		if checkpoint.Chunks == nil {
+			checkpoint.Chunks = make(map[string]migrate.Result)
+		}
+		if resumed && checkpoint.Chunk != chunk.Microseconds() {
+			return fmt.Errorf("checkpoint %s has chunks of %s", *checkpointFile, time.Duration(checkpoint.Chunk)*time.Microsecond)
+		}
+	}
+
+	src, err := openRepository(cfg)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tenants := []string{*tenant}
+	if *tenant == "" {
+		if tenants, err = src.Tenants(); err != nil {
+			return err
+		}
+	} else if err := models.ValidateTenant(*tenant); err != nil {
+		return err
+	}
+
+	// The range of a resumed migration is the checkpointed one, explicit bounds must match it
+	from, until := checkpoint.From, checkpoint.To
+	if !resumed {
+		until = models.Timestamp(time.Now())
+	}
+	if *fromTs != "" {
+		ts, err := api.ParseTimestamp(*fromTs, precision)
+		if err != nil {
+			return fmt.Errorf("invalid -from: %w", err)
+		}
+		if resumed && ts != from {
+			return fmt.Errorf("-from differs from the range of checkpoint %s", *checkpointFile)
+		}
+		from = ts
+	} else if !resumed {
+		earliest, found, err := migrate.Earliest(src, tenants)
+		if err != nil {
+			return err
+		}
+		if !found {
+			fmt.Println("no records to migrate")
+			return nil
+		}
+		from = earliest
+	}
+	if *untilTs != "" {
+		ts, err := api.ParseTimestamp(*untilTs, precision)
+		if err != nil {
+			return fmt.Errorf("invalid -until: %w", err)
+		}
+		if resumed && ts != until {
+			return fmt.Errorf("-until differs from the range of checkpoint %s", *checkpointFile)
+		}
+		until = ts
+	}
+	checkpoint.From, checkpoint.To = from, until
+
+	chunks, err := migrate.Chunks(tenants, from, until, checkpoint.Chunk)
+	if err != nil {
+		return err
+	}
+	pending := chunks[:0]
+	for _, c := range chunks {
+		if _, ok := checkpoint.Chunks[c.Key()]; !ok {
+			pending = append(pending, c)
+		}
+	}
+
+	dst, err := openRepositoryAt(cfg, *to)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	fmt.Printf("migrating %d tenants from %s until %s: %d chunks, %d verified before\n", len(tenants),
+		formatTimestamp(from), formatTimestamp(until), len(chunks), len(chunks)-len(pending))
+
+	var records int64
+	err = migrate.Run(src, dst, pending, *parallel, func(r migrate.Result) {
+		records += r.Records
+		if r.Records > 0 {
+			fmt.Printf("tenant %q %s: %d records, checksum %s\n", r.Tenant, formatTimestamp(r.From), r.Records, r.Checksum)
+		}
+		if *checkpointFile == "" {
+			return
+		}
+		checkpoint.Chunks[r.Key()] = r
+		if err := writeCheckpointFile(*checkpointFile, checkpoint); err != nil {
+			glog.Errorf("Migrate checkpoint write error: %v", err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d records migrated and verified\n", records)
+	return nil
+}
+
+// formatTimestamp formats a stored timestamp as RFC3339.
+func formatTimestamp(ts int64) string {
+	return time.UnixMicro(ts).UTC().Format(time.RFC3339Nano)
+}
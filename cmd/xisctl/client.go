@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/pb"
+
+	"github.com/google/uuid"
+)
+
+// Transports of the client.
+const (
+	transportREST = "rest"
+	transportGRPC = "grpc"
+)
+
+// errUnhealthy is returned by Health when the service responds but reports that it can't serve.
+var errUnhealthy = errors.New("service unavailable")
+
+// query selects records or window results: a time range in stored units (inclusive) and a series and label filter.
+type query struct {
+	From, To int64
+	Series   string
+	Labels   []string // Label matchers: name=value, name!=value, name=~regexp or name!~regexp
+}
+
+// matchers converts the label matchers of the query to their protobuf representation.
+func (q *query) matchers() ([]*pb.LabelMatcher, error) {
+	pbMatchers := make([]*pb.LabelMatcher, 0, len(q.Labels))
+	for _, s := range q.Labels {
+		m, err := models.ParseLabelMatcher(s)
+		if err != nil {
+			return nil, err
+		}
+		pbMatchers = append(pbMatchers, &pb.LabelMatcher{Name: m.Name, Type: pb.LabelMatcher_Type(m.Type), Value: m.Value})
+	}
+	return pbMatchers, nil
+}
+
+// client is the part of the REST and gRPC APIs used by the commands. Timestamps are in stored units
+// (Unix microseconds) on both sides, whatever the server precision.
+type client interface {
+	// Get returns the records with the IDs, in the order of ids, and the IDs without a record.
+	Get(ctx context.Context, ids []uuid.UUID) ([]models.Data, []uuid.UUID, error)
+
+	// List passes the records in the range of the query to fn in ascending timestamp order.
+	List(ctx context.Context, q query, fn func(*models.Data) error) error
+
+	// Rollups returns the window results of the kind ("" - any) starting within the range of the query.
+	Rollups(ctx context.Context, q query, kind models.WindowKind) ([]models.WindowResult, error)
+
+	// Tail passes records stored from now on that match the query filter and, if set, the minimum max
+	// to fn, until ctx is done or fn returns an error.
+	Tail(ctx context.Context, q query, minMax *float64, fn func(*models.Data) error) error
+
+	// Ingest submits packs, returning the per-pack errors (nil for stored packs) or an error that stopped
+	// the submission; packs after it are not stored.
+	Ingest(ctx context.Context, packs []*models.Pack) ([]error, error)
+
+	// Health returns nil if the service and its repository respond, errUnhealthy if the service reports
+	// that it can't serve, or the error reaching it.
+	Health(ctx context.Context) error
+
+	Close() error
+}
+
+// credentials of the caller; the token takes precedence over the API key.
+type credentials struct {
+	APIKey string
+	Token  string
+}
+
+// clientOptions configure a client.
+type clientOptions struct {
+	Transport   string                    // rest or grpc
+	Addr        string                    // REST base URL (scheme://host:port) or gRPC host:port
+	Credentials credentials               //
+	Precision   models.TimestampPrecision // Precision of integer timestamps exchanged with the server
+	TLS         bool                      // gRPC over TLS, implied for REST by an https URL
+	CAFile      string                    // PEM CA certificates to verify the server with, default the system ones
+}
+
+// newClient creates the client of the transport.
+func newClient(opts clientOptions) (client, error) {
+	switch strings.ToLower(opts.Transport) {
+	case transportREST:
+		return newRESTClient(opts)
+	case transportGRPC:
+		return newGRPCClient(opts)
+	}
+	return nil, fmt.Errorf("invalid API %q, expected rest or grpc", opts.Transport)
+}
+
+// importSink submits the packs of an import with a client, see importer.Sink.
+type importSink struct {
+	ctx    context.Context
+	client client
+}
+
+func (o importSink) ImportPacks(packs []*models.Pack) ([]error, error) {
+	return o.client.Ingest(o.ctx, packs)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"xis-data-aggregator/internal/api/grpc"
+	"xis-data-aggregator/internal/api/rest"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/repository/repotest"
+	"xis-data-aggregator/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	grpclib "google.golang.org/grpc"
+)
+
+// startServers serves a service in the precision over REST and gRPC, returning their addresses.
+func startServers(t *testing.T, precision models.TimestampPrecision) (string, string) {
+	svc := service.NewDataService(repotest.New(t))
+
+	gin.SetMode(gin.TestMode)
+	h := rest.NewDataServiceServer(svc, precision)
+	r := gin.New()
+	v1 := r.Group("/api/v1")
+	v1.GET("health", h.Health)
+	v1.GET("data", h.ListByTimeRange)
+	v1.POST("packs", h.IngestPacks)
+	v1.POST(":"+rest.CustomMethodParam, rest.CustomMethods(map[string]gin.HandlersChain{"data:batchGet": {h.BatchGet}}))
+	ts := httptest.NewServer(r)
+	t.Cleanup(ts.Close)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpclib.NewServer()
+	grpc.RegisterDataServiceServer(s, svc, precision)
+	grpc.RegisterHealthServer(s, svc)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	return ts.URL, lis.Addr().String()
+}
+
+// TestClients tests that both transports store and read the same records whatever the server precision.
+func TestClients(t *testing.T) {
+	restAddr, grpcAddr := startServers(t, models.PrecisionMilliseconds)
+
+	tests := []struct {
+		transport string
+		addr      string
+	}{
+		{transportREST, restAddr},
+		{transportGRPC, grpcAddr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.transport, func(t *testing.T) {
+			c, err := newClient(clientOptions{Transport: tt.transport, Addr: tt.addr, Precision: models.PrecisionMilliseconds})
+			require.NoError(t, err)
+			defer c.Close()
+			ctx := context.Background()
+
+			require.NoError(t, c.Health(ctx))
+
+			series := "cpu-" + tt.transport
+			packs := []*models.Pack{
+				{ID: uuid.New(), Timestamp: 1_000_000, Data: models.IntValues([]int64{1, 7}), Series: series, Labels: map[string]string{"host": "a"}},
+				{ID: uuid.New(), Timestamp: 2_000_000, Series: series}, // no samples
+				{ID: uuid.New(), Timestamp: 3_000_000, Data: models.IntValues([]int64{3}), Series: series},
+			}
+			errs, err := c.Ingest(ctx, packs)
+			require.NoError(t, err)
+			require.Len(t, errs, 3)
+			assert.NoError(t, errs[0])
+			assert.Error(t, errs[1], "the invalid pack is reported")
+			assert.NoError(t, errs[2], "the packs after an invalid one are stored")
+
+			var listed []models.Data
+			err = c.List(ctx, query{From: 0, To: 10_000_000, Series: series}, func(d *models.Data) error {
+				listed = append(listed, *d)
+				return nil
+			})
+			require.NoError(t, err)
+			require.Len(t, listed, 2)
+			assert.Equal(t, int64(1_000_000), listed[0].Timestamp)
+			assert.Equal(t, models.IntValue(7), listed[0].Max)
+			assert.Equal(t, map[string]string{"host": "a"}, listed[0].Labels)
+			assert.Equal(t, int64(3_000_000), listed[1].Timestamp)
+
+			missing := uuid.New()
+			found, notFound, err := c.Get(ctx, []uuid.UUID{listed[1].ID, missing})
+			require.NoError(t, err)
+			assert.Equal(t, []models.Data{listed[1]}, found)
+			assert.Equal(t, []uuid.UUID{missing}, notFound)
+		})
+	}
+}
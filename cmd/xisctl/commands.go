@@ -0,0 +1,350 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"xis-data-aggregator/internal/api"
+	"xis-data-aggregator/internal/importer"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// command runs a command with its arguments.
+type command func(ctx context.Context, c client, g *globals, args []string) error
+
+// commands by name.
+var commands = map[string]command{
+	"get":     runGet,
+	"list":    runList,
+	"rollups": runRollups,
+	"tail":    runTail,
+	"ingest":  runIngest,
+	"health":  runHealth,
+	"load":    runLoad,
+}
+
+// defaultRange is the range of list and rollups without -from, ending at -to.
+const defaultRange = time.Hour
+
+// labelsFlag collects the label matchers of repeated -label flags.
+type labelsFlag []string
+
+func (f *labelsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *labelsFlag) Set(s string) error {
+	if _, err := models.ParseLabelMatcher(s); err != nil {
+		return err
+	}
+	*f = append(*f, s)
+	return nil
+}
+
+// queryFlags are the flags of a query: the range (unless tailing) and the filter.
+type queryFlags struct {
+	from, to string
+	series   string
+	labels   labelsFlag
+}
+
+func (o *queryFlags) register(fs *flag.FlagSet, withRange bool) {
+	if withRange {
+		fs.StringVar(&o.from, "from", "", "start, inclusive: RFC3339, unit-suffixed (1704067200s) or integer in -precision (default: -to - 1h)")
+		fs.StringVar(&o.to, "to", "", "end, inclusive, like -from (default: now)")
+	}
+	fs.StringVar(&o.series, "series", "", "series name")
+	fs.Var(&o.labels, "label", "label matcher: name=value, name!=value, name=~regexp or name!~regexp; repeatable")
+}
+
+// query returns the query of the flags, with the range bounds parsed in the given precision.
+func (o *queryFlags) query(precision models.TimestampPrecision) (query, error) {
+	q := query{To: models.Timestamp(time.Now()), Series: o.series, Labels: o.labels}
+
+	var err error
+	if o.to != "" {
+		if q.To, err = api.ParseTimestamp(o.to, precision); err != nil {
+			return q, fmt.Errorf("invalid -to: %w", err)
+		}
+	}
+	q.From = q.To - defaultRange.Microseconds()
+	if o.from != "" {
+		if q.From, err = api.ParseTimestamp(o.from, precision); err != nil {
+			return q, fmt.Errorf("invalid -from: %w", err)
+		}
+	}
+	if q.From > q.To {
+		return q, errors.New("-from is after -to")
+	}
+	return q, nil
+}
+
+// runGet prints records by ID:
+//
+//	get id...
+//
+// Fails if an ID has no record, after printing the others.
+func runGet(ctx context.Context, c client, g *globals, args []string) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: get id...")
+	}
+
+	ids := make([]uuid.UUID, fs.NArg())
+	for i, s := range fs.Args() {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return fmt.Errorf("invalid ID %q", s)
+		}
+		ids[i] = id
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+	found, missing, err := c.Get(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	p, err := newPrinter(g.output, os.Stdout, dataHeader...)
+	if err != nil {
+		return err
+	}
+	for i := range found {
+		if err := printData(p, &found[i]); err != nil {
+			return err
+		}
+	}
+	if err := p.flush(); err != nil {
+		return err
+	}
+
+	for _, id := range missing {
+		fmt.Fprintf(os.Stderr, "%s: not found\n", id)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%d of %d records not found", len(missing), len(ids))
+	}
+	return nil
+}
+
+// runList prints the records of a time range:
+//
+//	list [-from ts] [-to ts] [-series name] [-label matcher]...
+func runList(ctx context.Context, c client, g *globals, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	var qf queryFlags
+	qf.register(fs, true)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	q, err := qf.query(g.client.Precision)
+	if err != nil {
+		return err
+	}
+
+	p, err := newPrinter(g.output, os.Stdout, dataHeader...)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+	if err := c.List(ctx, q, func(d *models.Data) error { return printData(p, d) }); err != nil {
+		_ = p.flush()
+		return err
+	}
+	return p.flush()
+}
+
+// runRollups prints the sliding and session window aggregates starting within a time range:
+//
+//	rollups [-from ts] [-to ts] [-kind sliding|session] [-series name] [-label matcher]...
+func runRollups(ctx context.Context, c client, g *globals, args []string) error {
+	fs := flag.NewFlagSet("rollups", flag.ContinueOnError)
+	var qf queryFlags
+	qf.register(fs, true)
+	kindFlag := fs.String("kind", "", "window kind: sliding or session (default: any)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	q, err := qf.query(g.client.Precision)
+	if err != nil {
+		return err
+	}
+	kind, err := models.ParseWindowKind(*kindFlag)
+	if err != nil {
+		return err
+	}
+
+	p, err := newPrinter(g.output, os.Stdout, windowHeader...)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+	windows, err := c.Rollups(ctx, q, kind)
+	if err != nil {
+		return err
+	}
+	for i := range windows {
+		if err := printWindow(p, &windows[i]); err != nil {
+			return err
+		}
+	}
+	return p.flush()
+}
+
+// runTail prints records as they are stored, until interrupted:
+//
+//	tail [-series name] [-label matcher]... [-minMax value]
+func runTail(ctx context.Context, c client, g *globals, args []string) error {
+	fs := flag.NewFlagSet("tail", flag.ContinueOnError)
+	var qf queryFlags
+	qf.register(fs, false)
+	minMaxFlag := fs.String("minMax", "", "only records with max >= minMax")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	q, err := qf.query(g.client.Precision)
+	if err != nil {
+		return err
+	}
+
+	var minMax *float64
+	if *minMaxFlag != "" {
+		v, err := strconv.ParseFloat(*minMaxFlag, 64)
+		if err != nil {
+			return fmt.Errorf("invalid -minMax %q", *minMaxFlag)
+		}
+		minMax = &v
+	}
+
+	p, err := newPrinter(g.output, os.Stdout, dataHeader...)
+	if err != nil {
+		return err
+	}
+	p.live = true
+	p.widths = []int{36, 27} // UUID and RFC3339 timestamp with microseconds, so that the header lines up
+
+	err = c.Tail(ctx, q, minMax, func(d *models.Data) error { return printData(p, d) })
+	if ctx.Err() != nil {
+		return nil // interrupted
+	}
+	return err
+}
+
+// runIngest submits the packs of CSV or NDJSON files, in the formats of the import command:
+//
+//	ingest [-format csv|ndjson] [-batch n] file...
+//
+// Invalid records are reported and skipped; a failed batch stops the ingestion.
+func runIngest(ctx context.Context, c client, g *globals, args []string) error {
+	fs := flag.NewFlagSet("ingest", flag.ContinueOnError)
+	format := fs.String("format", "", "file format: csv or ndjson (default: by file extension)")
+	batchSize := fs.Int("batch", importer.DefaultBatchSize, "packs submitted per request")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: ingest [flags] file...")
+	}
+
+	p, err := newPrinter(g.output, os.Stdout, "file", "records", "ingested", "failed")
+	if err != nil {
+		return err
+	}
+	defer p.flush()
+
+	for _, path := range fs.Args() {
+		progress, err := ingestFile(ctx, c, path, *format, *batchSize, g.client.Precision)
+
+		for _, e := range progress.Errors {
+			fmt.Fprintf(os.Stderr, "%s: record %d: %s\n", path, e.Record, e.Error)
+		}
+		if progress.Failed > int64(len(progress.Errors)) {
+			fmt.Fprintf(os.Stderr, "%s: %d more failed records\n", path, progress.Failed-int64(len(progress.Errors)))
+		}
+		if err != nil {
+			return fmt.Errorf("%s: stopped after record %d: %w", path, progress.Records, err)
+		}
+
+		row := struct {
+			File string `json:"file"`
+			importer.Progress
+		}{File: path, Progress: progress}
+		row.Errors = nil
+		if err := p.print(row, path, strconv.FormatInt(progress.Records, 10),
+			strconv.FormatInt(progress.Imported, 10), strconv.FormatInt(progress.Failed, 10)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ingestFile submits the packs of a file in the given format, or in the format of its extension if format is empty.
+func ingestFile(ctx context.Context, c client, path, format string, batchSize int, precision models.TimestampPrecision) (importer.Progress, error) {
+	opts := importer.Options{Precision: precision, BatchSize: batchSize}
+	var err error
+	if format != "" {
+		opts.Format, err = importer.ParseFormat(format)
+	} else {
+		opts.Format, err = importer.FormatOf(path)
+	}
+	if err != nil {
+		return importer.Progress{}, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return importer.Progress{}, err
+	}
+	defer f.Close()
+
+	return importer.Import(f, importSink{ctx: ctx, client: c}, opts)
+}
+
+// runHealth checks that the service and its repository respond:
+//
+//	health
+//
+// Fails if the service is unavailable.
+func runHealth(ctx context.Context, c client, g *globals, args []string) error {
+	fs := flag.NewFlagSet("health", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := newPrinter(g.output, os.Stdout, "api", "addr", "status")
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+	health := c.Health(ctx)
+
+	status := "ok"
+	if health != nil {
+		status = "unavailable"
+	}
+	row := map[string]string{"api": g.client.Transport, "addr": g.client.Addr, "status": status}
+	if err := p.print(row, row["api"], row["addr"], row["status"]); err != nil {
+		return err
+	}
+	if err := p.flush(); err != nil {
+		return err
+	}
+	return health
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"xis-data-aggregator/internal/api"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/pb"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	grpccreds "google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+// grpcClient is the client of the gRPC API. Responses carry protobuf timestamps and packs are sent with them,
+// so only range bounds depend on the server precision.
+type grpcClient struct {
+	conn      *grpc.ClientConn
+	data      pb.DataServiceClient
+	health    healthpb.HealthClient
+	creds     credentials
+	precision models.TimestampPrecision
+}
+
+func newGRPCClient(opts clientOptions) (*grpcClient, error) {
+	transport := insecure.NewCredentials()
+	if opts.TLS || opts.CAFile != "" {
+		config := &tls.Config{}
+		if opts.CAFile != "" {
+			pool, err := readCAFile(opts.CAFile)
+			if err != nil {
+				return nil, err
+			}
+			config.RootCAs = pool
+		}
+		transport = grpccreds.NewTLS(config)
+	}
+
+	conn, err := grpc.NewClient(opts.Addr, grpc.WithTransportCredentials(transport))
+	if err != nil {
+		return nil, err
+	}
+	return &grpcClient{
+		conn:      conn,
+		data:      pb.NewDataServiceClient(conn),
+		health:    healthpb.NewHealthClient(conn),
+		creds:     opts.Credentials,
+		precision: opts.Precision,
+	}, nil
+}
+
+// outgoing adds the credentials to the metadata of ctx.
+func (o *grpcClient) outgoing(ctx context.Context) context.Context {
+	switch {
+	case o.creds.Token != "":
+		return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+o.creds.Token)
+	case o.creds.APIKey != "":
+		return metadata.AppendToOutgoingContext(ctx, "x-api-key", o.creds.APIKey)
+	}
+	return ctx
+}
+
+// filter converts the series and label filter of the query.
+func (o *grpcClient) filter(q query) (*pb.Filter, error) {
+	matchers, err := q.matchers()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Filter{Series: q.Series, Matchers: matchers}, nil
+}
+
+// toData converts a response record, taking the timestamp from `time` rather than the server precision.
+func toData(pbData *pb.Data) (*models.Data, error) {
+	data, err := api.ProtoToData(pbData)
+	if err != nil {
+		return nil, err
+	}
+	if pbData.Time != nil {
+		data.Timestamp = models.Timestamp(pbData.Time.AsTime())
+	}
+	return data, nil
+}
+
+func (o *grpcClient) Get(ctx context.Context, ids []uuid.UUID) ([]models.Data, []uuid.UUID, error) {
+	req := pb.BatchGetDataRequest{Ids: make([]string, len(ids))}
+	for i, id := range ids {
+		req.Ids[i] = id.String()
+	}
+
+	resp, err := o.data.BatchGetData(o.outgoing(ctx), &req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var found []models.Data
+	var missing []uuid.UUID
+	for _, item := range resp.Items {
+		switch item.Status {
+		case pb.ItemStatus_ITEM_STATUS_OK:
+			data, err := toData(item)
+			if err != nil {
+				return nil, nil, err
+			}
+			found = append(found, *data)
+		case pb.ItemStatus_ITEM_STATUS_NOT_FOUND:
+			id, err := uuid.Parse(item.Id)
+			if err != nil {
+				return nil, nil, err
+			}
+			missing = append(missing, id)
+		default:
+			return nil, nil, fmt.Errorf("%s: %s %s", item.Id, item.Status, item.Error)
+		}
+	}
+	return found, missing, nil
+}
+
+func (o *grpcClient) List(ctx context.Context, q query, fn func(*models.Data) error) error {
+	filter, err := o.filter(q)
+	if err != nil {
+		return err
+	}
+
+	resp, err := o.data.ListData(o.outgoing(ctx), &pb.ListDataByTimeRangeRequestV2{
+		From:   o.precision.FromStored(q.From),
+		To:     o.precision.FromStored(q.To),
+		Filter: filter,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range resp.DataItems {
+		data, err := toData(item)
+		if err != nil {
+			return err
+		}
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *grpcClient) Rollups(ctx context.Context, q query, kind models.WindowKind) ([]models.WindowResult, error) {
+	filter, err := o.filter(q)
+	if err != nil {
+		return nil, err
+	}
+
+	pbKind := pb.WindowKind_WINDOW_KIND_UNSPECIFIED
+	switch kind {
+	case models.WindowSliding:
+		pbKind = pb.WindowKind_WINDOW_KIND_SLIDING
+	case models.WindowSession:
+		pbKind = pb.WindowKind_WINDOW_KIND_SESSION
+	}
+
+	resp, err := o.data.ListWindows(o.outgoing(ctx), &pb.ListWindowsRequest{
+		From:   o.precision.FromStored(q.From),
+		To:     o.precision.FromStored(q.To),
+		Filter: filter,
+		Kind:   pbKind,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	windows := make([]models.WindowResult, len(resp.Windows))
+	for i, pbWindow := range resp.Windows {
+		w, err := api.ProtoToWindow(pbWindow)
+		if err != nil {
+			return nil, err
+		}
+		windows[i] = *w
+	}
+	return windows, nil
+}
+
+func (o *grpcClient) Tail(ctx context.Context, q query, minMax *float64, fn func(*models.Data) error) error {
+	filter, err := o.filter(q)
+	if err != nil {
+		return err
+	}
+
+	stream, err := o.data.SubscribeData(o.outgoing(ctx), &pb.SubscribeDataRequest{Filter: filter, MinMax: minMax})
+	if err != nil {
+		return err
+	}
+
+	for {
+		item, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		data, err := toData(item)
+		if err != nil {
+			return err
+		}
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+}
+
+// Ingest sends the packs over an IngestPacks stream, receiving the acknowledgement of every pack.
+func (o *grpcClient) Ingest(ctx context.Context, packs []*models.Pack) ([]error, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := o.data.IngestPacks(o.outgoing(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	sent := make(chan error, 1)
+	go func() {
+		for _, pack := range packs {
+			pbPack, err := api.PackToProto(pack)
+			if err == nil {
+				err = stream.Send(pbPack)
+			}
+			if err != nil {
+				sent <- err
+				return
+			}
+		}
+		sent <- stream.CloseSend()
+	}()
+
+	errs := make([]error, len(packs))
+	for i := range packs {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return errs, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return errs, err // the server status, a failed Send only reports io.EOF
+		}
+		if resp.Status != pb.ItemStatus_ITEM_STATUS_OK {
+			errs[i] = errors.New(resp.Error)
+		}
+	}
+
+	return errs, <-sent
+}
+
+func (o *grpcClient) Health(ctx context.Context) error {
+	resp, err := o.health.Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return errUnhealthy
+	}
+	return nil
+}
+
+func (o *grpcClient) Close() error {
+	return o.conn.Close()
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+	"xis-data-aggregator/internal/loadgen"
+	"xis-data-aggregator/internal/models"
+)
+
+// loadHeader are the columns of the load report.
+var loadHeader = []string{"elapsed", "packs", "requests", "failed", "missed", "packs/s", "p50", "p90", "p95", "p99", "max"}
+
+// runLoad sends generated packs at a target rate and reports the achieved throughput and request latency:
+//
+//	load [-rate n] [-rampUp d] [-burstEvery d -burstLength d -burstFactor f] [-duration d] [-count n]
+//	     [-length dist] [-values dist] [-float] [-series name] [-seriesCount n] [-seed n] [-replayIds]
+//	     [-batch n] [-workers n] [-report d]
+//
+// Progress is printed to stderr every -report interval, the report of the whole run to stdout when the duration
+// elapsed, the count is reached or the run is interrupted.
+func runLoad(ctx context.Context, c client, g *globals, args []string) error {
+	fs := flag.NewFlagSet("load", flag.ContinueOnError)
+	var profile loadgen.Profile
+	fs.Float64Var(&profile.Rate, "rate", 100, "target packs per second")
+	fs.DurationVar(&profile.RampUp, "rampUp", 0, "duration of the linear increase of the rate from 0")
+	fs.DurationVar(&profile.BurstEvery, "burstEvery", 0, "period of rate bursts (default: none)")
+	fs.DurationVar(&profile.BurstLength, "burstLength", 5*time.Second, "duration of a burst, at the end of each period")
+	fs.Float64Var(&profile.BurstFactor, "burstFactor", 5, "rate multiplier during a burst")
+	duration := fs.Duration("duration", time.Minute, "duration of the run, 0 for until interrupted or -count")
+	count := fs.Int64("count", 0, "packs to send (default: unlimited)")
+	lengthFlag := fs.String("length", "10", "samples per pack: N, uniform:MIN-MAX or normal:MEAN,STDDEV")
+	valuesFlag := fs.String("values", "normal:500,50", "sample values: normal:MEAN,STDDEV, spikes:MEAN,STDDEV,PROB,FACTOR or trend:START,SLOPE,STDDEV (slope per second)")
+	float := fs.Bool("float", false, "send float64 values instead of rounding them to int64")
+	series := fs.String("series", "load", "series name of the packs")
+	seriesCount := fs.Int("seriesCount", 1, "number of series the packs are spread over, named -series with a -0, -1... suffix if more than 1")
+	seed := fs.Int64("seed", 1, "random seed; the same seed generates the same pack lengths and values")
+	replayIDs := fs.Bool("replayIds", false, "generate the pack IDs from -seed too, so that a rerun overwrites the packs of the previous one")
+	batch := fs.Int("batch", 1, "maximum packs per request")
+	workers := fs.Int("workers", 4, "concurrent requests")
+	reportEvery := fs.Duration("report", 5*time.Second, "progress interval, 0 for none")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	length, err := loadgen.ParseLengthDist(*lengthFlag)
+	if err != nil {
+		return err
+	}
+	values, err := loadgen.ParseValueDist(*valuesFlag)
+	if err != nil {
+		return err
+	}
+	if *seriesCount < 1 {
+		return errors.New("-seriesCount must be positive")
+	}
+	names := []string{*series}
+	if *seriesCount > 1 {
+		names = make([]string, *seriesCount)
+		for i := range names {
+			names[i] = *series + "-" + strconv.Itoa(i)
+		}
+	}
+
+	p, err := newPrinter(g.output, os.Stdout, loadHeader...)
+	if err != nil {
+		return err
+	}
+
+	send := func(ctx context.Context, packs []*models.Pack) ([]error, error) {
+		ctx, cancel := context.WithTimeout(ctx, g.timeout)
+		defer cancel()
+		return c.Ingest(ctx, packs)
+	}
+	report, err := loadgen.Run(ctx, send, loadgen.Options{
+		Profile:   profile,
+		Generator: loadgen.NewGenerator(*seed, length, values, names, *float, *replayIDs),
+		Duration:  *duration,
+		Count:     *count,
+		Batch:     *batch,
+		Workers:   *workers,
+		Report:    *reportEvery,
+		OnReport: func(r loadgen.Report) {
+			fmt.Fprintf(os.Stderr, "%d packs, %.1f/s, %d failed, %d missed, p50 %s, p99 %s\n",
+				r.Packs, r.Throughput, r.Failed, r.Missed, round(r.Latency.P50), round(r.Latency.P99))
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	messages := make([]string, 0, len(report.Errors))
+	for msg := range report.Errors {
+		messages = append(messages, msg)
+	}
+	sort.Slice(messages, func(i, j int) bool { return report.Errors[messages[i]] > report.Errors[messages[j]] })
+	for _, msg := range messages {
+		fmt.Fprintf(os.Stderr, "%d packs failed: %s\n", report.Errors[msg], msg)
+	}
+
+	row := map[string]string{
+		"elapsed":  round(report.Elapsed).String(),
+		"packs":    strconv.FormatInt(report.Packs, 10),
+		"requests": strconv.FormatInt(report.Requests, 10),
+		"failed":   strconv.FormatInt(report.Failed, 10),
+		"missed":   strconv.FormatInt(report.Missed, 10),
+		"packs/s":  strconv.FormatFloat(report.Throughput, 'f', 1, 64),
+		"p50":      round(report.Latency.P50).String(),
+		"p90":      round(report.Latency.P90).String(),
+		"p95":      round(report.Latency.P95).String(),
+		"p99":      round(report.Latency.P99).String(),
+		"max":      round(report.Latency.Max).String(),
+	}
+	cells := make([]string, len(loadHeader))
+	for i, column := range loadHeader {
+		cells[i] = row[column]
+	}
+	if err := p.print(row, cells...); err != nil {
+		return err
+	}
+	return p.flush()
+}
+
+// round rounds a latency to 3 significant digits, at least microseconds.
+func round(d time.Duration) time.Duration {
+	unit := time.Microsecond
+	for d >= 1000*unit {
+		unit *= 10
+	}
+	return d.Round(unit)
+}
@@ -0,0 +1,95 @@
+// Command xisctl queries and feeds the XIS Data Aggregator over its REST or gRPC API:
+//
+//	xisctl [global flags] command [flags] [args]
+//
+// Commands: get, list, rollups, tail, ingest, health and load; run a command with -h for its flags.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"xis-data-aggregator/internal/models"
+)
+
+// Default server addresses of the transports.
+const (
+	defaultRESTAddr = "localhost:8080"
+	defaultGRPCAddr = "localhost:50051"
+)
+
+// globals are the flags shared by the commands.
+type globals struct {
+	client    clientOptions
+	output    string
+	timeout   time.Duration
+	precision string
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "xisctl: %v\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	var g globals
+	fs := flag.NewFlagSet("xisctl", flag.ContinueOnError)
+	fs.StringVar(&g.client.Transport, "api", transportREST, "API to use: rest or grpc")
+	fs.StringVar(&g.client.Addr, "addr", "", "server address, host:port or a REST base URL (default localhost:8080 for rest, localhost:50051 for grpc)")
+	fs.StringVar(&g.client.Credentials.APIKey, "apiKey", os.Getenv("XIS_API_KEY"), "API key (default $XIS_API_KEY)")
+	fs.StringVar(&g.client.Credentials.Token, "token", os.Getenv("XIS_TOKEN"), "JWT bearer token, takes precedence over the API key (default $XIS_TOKEN)")
+	fs.BoolVar(&g.client.TLS, "tls", false, "connect over TLS (REST: implied by an https URL)")
+	fs.StringVar(&g.client.CAFile, "caFile", "", "PEM CA certificates to verify the server with (default: system roots)")
+	fs.StringVar(&g.output, "o", outputTable, "output format: table, json or csv")
+	fs.DurationVar(&g.timeout, "timeout", 30*time.Second, "timeout of a request, except tail")
+	fs.StringVar(&g.precision, "precision", string(models.PrecisionMicroseconds), "server timestamp precision (-tsPrecision): s, ms, us or ns; also the unit of integer timestamps in arguments and files")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: xisctl [global flags] get|list|rollups|tail|ingest|health|load [flags] [args]\n\nGlobal flags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("no command")
+	}
+
+	precision, err := models.ParseTimestampPrecision(g.precision)
+	if err != nil {
+		return err
+	}
+	g.client.Precision = precision
+	if g.client.Addr == "" {
+		g.client.Addr = defaultRESTAddr
+		if g.client.Transport == transportGRPC {
+			g.client.Addr = defaultGRPCAddr
+		}
+	}
+
+	command, ok := commands[fs.Arg(0)]
+	if !ok {
+		return fmt.Errorf("unknown command %q, expected get, list, rollups, tail, ingest, health or load", fs.Arg(0))
+	}
+
+	c, err := newClient(g.client)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	// Interrupts end a tail, an ingestion or a load run cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return command(ctx, c, &g, fs.Args()[1:])
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"xis-data-aggregator/internal/api"
+	"xis-data-aggregator/internal/models"
+)
+
+// Output formats.
+const (
+	outputTable = "table" // Aligned columns under a header
+	outputJSON  = "json"  // One JSON object per line
+	outputCSV   = "csv"   // Header row and a row per object
+)
+
+// printer writes objects as rows in the output format. Tables are aligned when flushed; a live printer flushes
+// every row, so that a tail is not delayed, and pads table columns to the widest cell so far instead.
+type printer struct {
+	format string
+	header []string
+	live   bool
+	tw     *tabwriter.Writer
+	cw     *csv.Writer
+	enc    *json.Encoder
+	rows   int
+	widths []int // Column widths of a live table
+}
+
+// newPrinter creates a printer of rows with the columns of header.
+func newPrinter(format string, w io.Writer, header ...string) (*printer, error) {
+	p := &printer{format: format, header: header}
+	switch format {
+	case outputTable:
+		p.tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	case outputCSV:
+		p.cw = csv.NewWriter(w)
+	case outputJSON:
+		p.enc = json.NewEncoder(w)
+	default:
+		return nil, fmt.Errorf("invalid output %q, expected table, json or csv", format)
+	}
+	return p, nil
+}
+
+// print writes v as a JSON object, or its cells in the order of the header.
+func (p *printer) print(v interface{}, cells ...string) error {
+	if p.enc != nil {
+		return p.enc.Encode(v)
+	}
+
+	if p.rows == 0 {
+		if err := p.write(p.header); err != nil {
+			return err
+		}
+	}
+	p.rows++
+	if err := p.write(cells); err != nil {
+		return err
+	}
+	if p.live {
+		return p.flush()
+	}
+	return nil
+}
+
+func (p *printer) write(cells []string) error {
+	if p.cw != nil {
+		return p.cw.Write(cells)
+	}
+
+	if p.rows == 0 {
+		upper := make([]string, len(cells))
+		for i, cell := range cells {
+			upper[i] = strings.ToUpper(cell)
+		}
+		cells = upper
+	}
+	if !p.live {
+		_, err := fmt.Fprintln(p.tw, strings.Join(cells, "\t"))
+		return err
+	}
+
+	var line strings.Builder
+	for i, cell := range cells {
+		if i == len(p.widths) {
+			p.widths = append(p.widths, 0)
+		}
+		p.widths[i] = max(p.widths[i], len(cell))
+		if i < len(cells)-1 {
+			fmt.Fprintf(&line, "%-*s  ", p.widths[i], cell)
+		} else {
+			line.WriteString(cell)
+		}
+	}
+	_, err := fmt.Fprintln(p.tw, line.String())
+	return err
+}
+
+// flush writes the buffered rows.
+func (p *printer) flush() error {
+	switch {
+	case p.tw != nil:
+		return p.tw.Flush()
+	case p.cw != nil:
+		p.cw.Flush()
+		return p.cw.Error()
+	}
+	return nil
+}
+
+// dataHeader are the columns of printed records.
+var dataHeader = []string{"id", "ts", "series", "max", "anomaly_score", "anomalous"}
+
+// dataView is the JSON output of a record, with the timestamp as RFC3339.
+type dataView struct {
+	models.Data
+	Timestamp string `json:"ts"` // Shadows Data.Timestamp
+}
+
+// printData prints a record; the series column is the series name with its labels.
+func printData(p *printer, d *models.Data) error {
+	ts := api.FormatTimestamp(d.Timestamp)
+	return p.print(dataView{Data: *d, Timestamp: ts},
+		d.ID.String(), ts, seriesColumn(d.Series, d.Labels), d.Max.String(),
+		strconv.FormatFloat(d.AnomalyScore, 'f', 2, 64), strconv.FormatBool(d.Anomalous))
+}
+
+// seriesColumn renders a series as `name{label="value",...}`, empty for records without series and labels.
+func seriesColumn(series string, labels map[string]string) string {
+	if series == "" && len(labels) == 0 {
+		return ""
+	}
+	return (&models.Data{Series: series, Labels: labels}).SeriesKey()
+}
+
+// windowHeader are the columns of printed window results.
+var windowHeader = []string{"kind", "ts", "end", "series", "count", "min", "max", "avg", "sum", "revision"}
+
+// windowView is the JSON output of a window result, with the bounds as RFC3339.
+type windowView struct {
+	models.WindowResult
+	Start string `json:"ts"`  // Shadows WindowResult.Start
+	End   string `json:"end"` // Shadows WindowResult.End
+}
+
+// printWindow prints a window result; the series column is the series name with its labels.
+func printWindow(p *printer, w *models.WindowResult) error {
+	start, end := api.FormatTimestamp(w.Start), api.FormatTimestamp(w.End)
+	return p.print(windowView{WindowResult: *w, Start: start, End: end},
+		string(w.Kind), start, end, seriesColumn(w.Series, w.Labels), strconv.FormatInt(w.Count, 10), w.Min.String(), w.Max.String(),
+		strconv.FormatFloat(w.Avg, 'g', -1, 64), strconv.FormatFloat(w.Sum, 'g', -1, 64), strconv.Itoa(w.Revision))
+}
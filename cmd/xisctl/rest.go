@@ -0,0 +1,379 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// restClient is the client of the REST API (/api/v1). Responses are requested with RFC3339 timestamps,
+// range bounds are sent unit-suffixed, so only ingested packs depend on the server precision.
+type restClient struct {
+	base      string // API base URL
+	http      *http.Client
+	creds     credentials
+	precision models.TimestampPrecision
+}
+
+func newRESTClient(opts clientOptions) (*restClient, error) {
+	addr := opts.Addr
+	if !strings.Contains(addr, "://") {
+		scheme := "http://"
+		if opts.TLS {
+			scheme = "https://"
+		}
+		addr = scheme + addr
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.CAFile != "" {
+		pool, err := readCAFile(opts.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	return &restClient{
+		base:      strings.TrimSuffix(addr, "/") + "/api/v1",
+		http:      &http.Client{Transport: transport},
+		creds:     opts.Credentials,
+		precision: opts.Precision,
+	}, nil
+}
+
+// readCAFile reads PEM CA certificates into a pool.
+func readCAFile(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates in %s", path)
+	}
+	return pool, nil
+}
+
+// restData is a record of a response with the timestamp rendered as RFC3339.
+type restData struct {
+	models.Data
+	Timestamp string `json:"ts"` // Shadows Data.Timestamp
+}
+
+func (o *restData) data() (models.Data, error) {
+	t, err := time.Parse(time.RFC3339Nano, o.Timestamp)
+	o.Data.Timestamp = models.Timestamp(t)
+	return o.Data, err
+}
+
+// restWindow is a window result of a response with the bounds rendered as RFC3339.
+type restWindow struct {
+	models.WindowResult
+	Start string `json:"ts"`  // Shadows WindowResult.Start
+	End   string `json:"end"` // Shadows WindowResult.End
+}
+
+// stamp renders a stored timestamp as a unit-suffixed query value.
+func stamp(ts int64) string {
+	return strconv.FormatInt(ts, 10) + string(models.PrecisionMicroseconds)
+}
+
+// rangeValues returns the query parameters of a range query.
+func rangeValues(q query) url.Values {
+	values := url.Values{"from": {stamp(q.From)}, "to": {stamp(q.To)}, "ts_format": {"rfc3339"}}
+	if q.Series != "" {
+		values.Set("series", q.Series)
+	}
+	for _, m := range q.Labels {
+		values.Add("label", m)
+	}
+	return values
+}
+
+// apiError is a response with an unexpected status.
+type apiError struct {
+	Status  string // HTTP status line
+	Code    int    // HTTP status code
+	Message string // The "error" of the body, if any
+	Body    []byte
+}
+
+func (e *apiError) Error() string {
+	if e.Message != "" {
+		return e.Status + ": " + e.Message
+	}
+	return e.Status
+}
+
+// do sends a request with the credentials. A response with another than the expected status is read and
+// returned as an *apiError.
+func (o *restClient) do(ctx context.Context, method, path string, values url.Values, body interface{}, expected int) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	u := o.base + path
+	if len(values) > 0 {
+		u += "?" + values.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	switch {
+	case o.creds.Token != "":
+		req.Header.Set("Authorization", "Bearer "+o.creds.Token)
+	case o.creds.APIKey != "":
+		req.Header.Set("X-API-Key", o.creds.APIKey)
+	}
+
+	resp, err := o.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == expected {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	apiErr := &apiError{Status: resp.Status, Code: resp.StatusCode}
+	apiErr.Body, _ = io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	var msg struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(apiErr.Body, &msg) == nil {
+		apiErr.Message = msg.Error
+	}
+	return nil, apiErr
+}
+
+func (o *restClient) Get(ctx context.Context, ids []uuid.UUID) ([]models.Data, []uuid.UUID, error) {
+	body := struct {
+		IDs []uuid.UUID `json:"ids"`
+	}{IDs: ids}
+	resp, err := o.do(ctx, http.MethodPost, "/data:batchGet", url.Values{"ts_format": {"rfc3339"}}, body, http.StatusOK)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data    []restData  `json:"data"`
+		Missing []uuid.UUID `json:"missing"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, nil, err
+	}
+
+	found := make([]models.Data, len(result.Data))
+	for i := range result.Data {
+		if found[i], err = result.Data[i].data(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return found, result.Missing, nil
+}
+
+// List streams the NDJSON export of the range, so the records are not held in memory.
+func (o *restClient) List(ctx context.Context, q query, fn func(*models.Data) error) error {
+	values := rangeValues(q)
+	values.Set("format", "ndjson")
+	resp, err := o.do(ctx, http.MethodGet, "/data", values, nil, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var record restData
+		switch err := dec.Decode(&record); {
+		case errors.Is(err, io.EOF):
+			return nil
+		case err != nil:
+			return err
+		}
+
+		data, err := record.data()
+		if err != nil {
+			return err
+		}
+		if err := fn(&data); err != nil {
+			return err
+		}
+	}
+}
+
+func (o *restClient) Rollups(ctx context.Context, q query, kind models.WindowKind) ([]models.WindowResult, error) {
+	values := rangeValues(q)
+	if kind != "" {
+		values.Set("kind", string(kind))
+	}
+	resp, err := o.do(ctx, http.MethodGet, "/windows", values, nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var views []restWindow
+	if err := json.NewDecoder(resp.Body).Decode(&views); err != nil {
+		return nil, err
+	}
+
+	windows := make([]models.WindowResult, len(views))
+	for i, view := range views {
+		start, err := time.Parse(time.RFC3339Nano, view.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := time.Parse(time.RFC3339Nano, view.End)
+		if err != nil {
+			return nil, err
+		}
+		windows[i] = view.WindowResult
+		windows[i].Start, windows[i].End = models.Timestamp(start), models.Timestamp(end)
+	}
+	return windows, nil
+}
+
+// Tail reads the Server-Sent Events feed.
+func (o *restClient) Tail(ctx context.Context, q query, minMax *float64, fn func(*models.Data) error) error {
+	values := url.Values{"ts_format": {"rfc3339"}}
+	if q.Series != "" {
+		values.Set("series", q.Series)
+	}
+	for _, m := range q.Labels {
+		values.Add("label", m)
+	}
+	if minMax != nil {
+		values.Set("min_max", strconv.FormatFloat(*minMax, 'g', -1, 64))
+	}
+
+	resp, err := o.do(ctx, http.MethodGet, "/data/stream", values, nil, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Events are "field: value" lines ended by a blank line; comment lines (": ping") are skipped
+	var event, payload string
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" {
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "event":
+				event = value
+			case "data":
+				payload += value
+			}
+			continue
+		}
+
+		switch event {
+		case "data":
+			var record restData
+			if err := json.Unmarshal([]byte(payload), &record); err != nil {
+				return err
+			}
+			data, err := record.data()
+			if err != nil {
+				return err
+			}
+			if err := fn(&data); err != nil {
+				return err
+			}
+		case "error":
+			var body struct {
+				Error string `json:"error"`
+			}
+			_ = json.Unmarshal([]byte(payload), &body)
+			return fmt.Errorf("feed ended: %s", body.Error)
+		}
+		event, payload = "", ""
+	}
+
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// Ingest posts the packs. The server stops at the first invalid pack, reporting the packs stored before it,
+// so the packs after it are posted again.
+func (o *restClient) Ingest(ctx context.Context, packs []*models.Pack) ([]error, error) {
+	errs := make([]error, len(packs))
+
+	for start := 0; start < len(packs); {
+		body := make([]models.Pack, len(packs)-start)
+		for i, pack := range packs[start:] {
+			body[i] = *pack
+			body[i].Timestamp = o.precision.FromStored(pack.Timestamp)
+		}
+
+		resp, err := o.do(ctx, http.MethodPost, "/packs", nil, body, http.StatusCreated)
+		var apiErr *apiError
+		switch {
+		case err == nil:
+			return errs, resp.Body.Close()
+		case !errors.As(err, &apiErr) || apiErr.Code != http.StatusBadRequest:
+			return errs, err
+		}
+
+		var rejected struct {
+			Error  string             `json:"error"`
+			Stored *[]json.RawMessage `json:"stored"`
+		}
+		if json.Unmarshal(apiErr.Body, &rejected) != nil || rejected.Stored == nil {
+			return errs, err // the whole request was rejected
+		}
+		failed := start + len(*rejected.Stored)
+		errs[failed] = errors.New(rejected.Error)
+		start = failed + 1
+	}
+	return errs, nil
+}
+
+func (o *restClient) Health(ctx context.Context) error {
+	resp, err := o.do(ctx, http.MethodGet, "/health", nil, nil, http.StatusOK)
+	var apiErr *apiError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.Code == http.StatusServiceUnavailable:
+		return errUnhealthy
+	case err != nil:
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (o *restClient) Close() error {
+	o.http.CloseIdleConnections()
+	return nil
+}
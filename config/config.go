@@ -1,7 +1,11 @@
 // Package config provides configuration structures and functions for the XIS Data Aggregator service.
 package config
 
-import "flag"
+import (
+	"flag"
+	"os"
+	"time"
+)
 
 // workersCount is the default number of workers for reading, aggregating, and saving to the database (tuned for weak test DB).
 // metricsBatchSize is the default number of metrics to batch before processing.
@@ -9,13 +13,39 @@ import "flag"
 // grpcPort is the default port for the gRPC server.
 // inputIntervalMs is the default interval (in milliseconds) for input simulation (tuned for weak test DB).
 // packLength is the default length of a data pack.
+// inputReplaySpeed is the default speed factor of a replayed input recording (original speed).
+// tlsReloadIntervalSec is the default interval (in seconds) for checking TLS certificate files for changes.
+// readRatePerSec, readBurst, ingestRatePerSec and ingestBurst are the default per-client token bucket limits.
+// maxQuerySpan is the default maximum `to - from` span of a range query (1 day in Unix microseconds).
+// subscriberBuffer is the default maximum number of records buffered per live subscriber.
+// timestampPrecision is the default unit of integer timestamps exchanged with clients (Unix microseconds, as stored).
+// webhookQueue, webhookWorkers and webhookMaxAttempts are the default webhook delivery queue size, concurrency and attempts.
+// anomalyWindow, anomalyAlpha and anomalyThreshold are the default anomaly detector window, EWMA factor and score threshold.
+// allowedLateness is the default lateness of records merged into their windows behind the series watermark.
 const (
-	workersCount     = 5 // for weak test db
-	metricsBatchSize = 10
-	restPort         = 8080
-	grpcPort         = 50051
-	inputIntervalMs  = 555 // for weak test db
-	packLength       = 10
+	workersCount         = 5 // for weak test db
+	metricsBatchSize     = 10
+	restPort             = 8080
+	grpcPort             = 50051
+	inputIntervalMs      = 555 // for weak test db
+	packLength           = 10
+	inputReplaySpeed     = 1
+	tlsReloadIntervalSec = 30
+	readRatePerSec       = 20
+	readBurst            = 40
+	ingestRatePerSec     = 200
+	ingestBurst          = 400
+	maxUploadMB          = 1024
+	maxQuerySpan         = 24 * 60 * 60 * 1_000_000
+	timestampPrecision   = "us"
+	subscriberBuffer     = 256
+	webhookQueue         = 1024
+	webhookWorkers       = 4
+	webhookMaxAttempts   = 5
+	anomalyWindow        = 60
+	anomalyAlpha         = 0.1
+	anomalyThreshold     = 3
+	allowedLateness      = 10 * time.Second
 )
 
 // XisDataAggregatorConfig holds all configuration parameters for the XIS Data Aggregator service.
@@ -36,6 +66,101 @@ type XisDataAggregatorConfig struct {
 	InputIntervalMs int
 	// PackLength is the length of a data pack.
 	PackLength int
+	// InputSeed seeds the IDs and values of simulated packs, so that runs generate the same packs. nil - a random seed.
+	InputSeed *int64
+	// InputRecordFile is the path of a file the simulated packs are written to, one JSON object per line. Empty records nothing.
+	InputRecordFile string
+	// InputReplayFile is the path of a recording replayed instead of simulating packs. Empty simulates packs.
+	InputReplayFile string
+	// InputReplaySpeed is the speed factor of the replay: 1 - the original pace of the recording, 10 - ten times faster,
+	// 0 - without delays.
+	InputReplaySpeed float64
+
+	// Auth parameters. Authentication is disabled when no API keys and no JWT keys are configured.
+	// APIKeys is a list of static API keys in the form "key=scope1,scope2;key2=scope1".
+	APIKeys string
+	// JWTHS256Secret is the shared secret used to verify HS256 signed JWTs.
+	JWTHS256Secret string
+	// JWTRS256PublicKeyFile is the path to a PEM encoded RSA public key used to verify RS256 signed JWTs.
+	JWTRS256PublicKeyFile string
+
+	// TLS parameters. Both listeners serve plain text when no certificate is configured.
+	// TLSCertFile is the path to the PEM encoded server certificate (chain).
+	TLSCertFile string
+	// TLSKeyFile is the path to the PEM encoded server private key.
+	TLSKeyFile string
+	// TLSClientCAFile is the path to a PEM CA bundle used to verify gRPC client certificates (mTLS).
+	TLSClientCAFile string
+	// TLSRequireClientCert rejects gRPC clients without a valid certificate when TLSClientCAFile is set.
+	TLSRequireClientCert bool
+	// TLSReloadIntervalSec is the interval (in seconds) for checking certificate files for changes.
+	TLSReloadIntervalSec int
+
+	// Rate limit parameters, applied per client (API key subject or IP address). Zero disables the limit.
+	// ReadRatePerSec is the number of read requests per second allowed for a client.
+	ReadRatePerSec int
+	// ReadBurst is the number of read requests a client may issue at once, zero - the rate.
+	ReadBurst int
+	// IngestRatePerSec is the number of ingest requests (REST calls or streamed gRPC packs) per second allowed for a client.
+	IngestRatePerSec int
+	// IngestBurst is the number of ingest requests a client may issue at once, zero - the rate.
+	IngestBurst int
+	// TrustedProxies is a comma separated list of the proxy IP addresses or CIDRs whose X-Forwarded-For and
+	// X-Real-IP headers are trusted for the client IP of REST requests, which keys the rate limits of
+	// unauthenticated clients. Empty trusts no proxy and uses the connection address.
+	TrustedProxies string
+	// MaxUploadMB is the maximum body size of REST file uploads (imports and restores) in MiB.
+	MaxUploadMB int64
+	// MaxQuerySpan is the maximum `to - from` span of a range query in Unix microseconds. Zero disables the cap.
+	MaxQuerySpan int64
+
+	// Retention is the per tenant retention of stored records in the form "*=720h;tenant1=72h".
+	// The "*" entry applies to tenants without an own entry; empty keeps records forever.
+	Retention string
+
+	// TimestampPrecision is the unit (s, ms, us or ns) of integer timestamps in ingested packs, query
+	// parameters and responses. Records are always stored in Unix microseconds.
+	TimestampPrecision string
+
+	// SubscriberBuffer is the maximum number of records buffered per live subscriber before the
+	// subscriber's slow consumer policy applies. Subscribers may request a smaller buffer.
+	SubscriberBuffer int
+
+	// AlertRulesFile is the path to a JSON array of read-only alert rules loaded at startup.
+	// Rules managed via the API are persisted in the repository. Empty loads no config rules.
+	AlertRulesFile string
+
+	// Webhook parameters. Webhooks are disabled when no endpoints file is configured.
+	// WebhooksFile is the path to a JSON array of webhook endpoints.
+	WebhooksFile string
+	// WebhookQueue is the number of deliveries queued before new ones are dropped.
+	WebhookQueue int
+	// WebhookWorkers is the number of concurrent webhook deliveries.
+	WebhookWorkers int
+	// WebhookMaxAttempts is the number of attempts of a delivery, retried with exponential backoff.
+	WebhookMaxAttempts int
+
+	// Anomaly detection parameters.
+	// AnomalyWindow is the number of previous records of a series the rolling z-score is computed against.
+	AnomalyWindow int
+	// AnomalyAlpha is the EWMA smoothing factor in (0, 1]; higher values adapt faster.
+	AnomalyAlpha float64
+	// AnomalyThreshold is the score (absolute z-score) from which a record is flagged as anomalous.
+	AnomalyThreshold float64
+
+	// Window aggregation parameters. Window aggregation is disabled when neither a window size nor a session gap is configured.
+	// WindowSize is the size of the sliding windows, 0 - no sliding windows.
+	WindowSize time.Duration
+	// WindowSlide is the interval between sliding window starts, 0 - tumbling windows of WindowSize.
+	WindowSlide time.Duration
+	// SessionGap is the gap between records of a series that closes a session window, 0 - no session windows.
+	SessionGap time.Duration
+	// AllowedLateness is how far behind the series watermark records are still merged into their windows.
+	AllowedLateness time.Duration
+
+	// RedisAddr is the address of the Redis server storing the records. Empty runs an embedded in-memory
+	// server, whose records are lost on exit and not shared with commands run in other processes.
+	RedisAddr string
 }
 
 // GetXisDataAggregatorConfig initializes and returns a default XisDataAggregatorConfig.
@@ -49,43 +174,290 @@ func GetXisDataAggregatorConfig() (*XisDataAggregatorConfig, error) {
 		MetricsBatchSize: metricsBatchSize,
 		InputIntervalMs:  inputIntervalMs,
 		PackLength:       packLength,
+		InputReplaySpeed: inputReplaySpeed,
+
+		TLSReloadIntervalSec: tlsReloadIntervalSec,
+
+		ReadRatePerSec:   readRatePerSec,
+		ReadBurst:        readBurst,
+		IngestRatePerSec: ingestRatePerSec,
+		IngestBurst:      ingestBurst,
+		MaxUploadMB:      maxUploadMB,
+		MaxQuerySpan:     maxQuerySpan,
+
+		TimestampPrecision: timestampPrecision,
+		SubscriberBuffer:   subscriberBuffer,
+
+		WebhookQueue:       webhookQueue,
+		WebhookWorkers:     webhookWorkers,
+		WebhookMaxAttempts: webhookMaxAttempts,
+
+		AnomalyWindow:    anomalyWindow,
+		AnomalyAlpha:     anomalyAlpha,
+		AnomalyThreshold: anomalyThreshold,
+
+		AllowedLateness: allowedLateness,
 	}
 
 	return &config, nil
 }
 
-// UpdateConfigFromFlags updates the configuration fields from command-line flags if provided.
-// Only non-zero flag values will override the existing config values.
+// AuthEnabled reports whether any API key or JWT verification key is configured.
+func (cfg *XisDataAggregatorConfig) AuthEnabled() bool {
+	return cfg.APIKeys != "" || cfg.JWTHS256Secret != "" || cfg.JWTRS256PublicKeyFile != ""
+}
+
+// TLSEnabled reports whether a server certificate and key are configured.
+func (cfg *XisDataAggregatorConfig) TLSEnabled() bool {
+	return cfg.TLSCertFile != "" && cfg.TLSKeyFile != ""
+}
+
+// UpdateConfigFromFlags registers the command-line flags, parses them and updates the configuration fields.
+// Only non-zero flag values will override the existing config values, except for flags whose zero value is a
+// valid setting, which apply whenever they are passed.
 func (cfg *XisDataAggregatorConfig) UpdateConfigFromFlags() {
+	_ = cfg.updateFromFlags(flag.CommandLine, os.Args[1:]) // the command line exits on invalid flags
+}
+
+// updateFromFlags registers the flags on fs, parses the arguments and updates the configuration fields.
+func (cfg *XisDataAggregatorConfig) updateFromFlags(fs *flag.FlagSet, args []string) error {
 	var workersCount, metricsBatchSize, restPort, grpcPort, inputIntervalMs, packLength int
+	var inputSeed int64
+	var inputRecordFile, inputReplayFile string
+	var inputReplaySpeed float64
+	var apiKeys, jwtHS256Secret, jwtRS256PublicKeyFile string
+	var tlsCertFile, tlsKeyFile, tlsClientCAFile string
+	var tlsRequireClientCert bool
+	var tlsReloadIntervalSec int
+	var readRatePerSec, readBurst, ingestRatePerSec, ingestBurst int
+	var trustedProxies string
+	var maxUpload int64
+	var maxQuerySpan int64
+	var retention string
+	var tsPrecision string
+	var subscriberBuffer int
+	var alertRulesFile string
+	var webhooksFile string
+	var webhookQueue, webhookWorkers, webhookMaxAttempts int
+	var anomalyWindow int
+	var anomalyAlpha, anomalyThreshold float64
+	var windowSize, windowSlide, sessionGap, lateness time.Duration
+	var redisAddr string
+
+	fs.IntVar(&workersCount, "workersCount", 0, "workers count")
+	fs.IntVar(&metricsBatchSize, "b", 0, "metrics batch size")
+	fs.IntVar(&restPort, "r", 0, "rest port")
+	fs.IntVar(&grpcPort, "g", 0, "grpc port")
+	fs.IntVar(&inputIntervalMs, "n", 0, "input interval")
+	fs.IntVar(&packLength, "l", 0, "input pack length")
+	fs.Int64Var(&inputSeed, "seed", 0, "input simulation random seed (default: random)")
+	fs.StringVar(&inputRecordFile, "record", "", "file to record the simulated input packs to (NDJSON)")
+	fs.StringVar(&inputReplayFile, "replay", "", "recorded input file to replay instead of simulating packs")
+	fs.Float64Var(&inputReplaySpeed, "replaySpeed", 0, "replay speed factor, e.g. 10 for ten times faster, 0 without delays (default 1)")
+	fs.StringVar(&apiKeys, "apiKeys", "", "static API keys, e.g. \"key1=read,ingest;key2=read\"")
+	fs.StringVar(&jwtHS256Secret, "jwtSecret", "", "HS256 JWT shared secret")
+	fs.StringVar(&jwtRS256PublicKeyFile, "jwtPublicKey", "", "RS256 JWT public key file (PEM)")
+	fs.StringVar(&tlsCertFile, "tlsCert", "", "TLS certificate file (PEM)")
+	fs.StringVar(&tlsKeyFile, "tlsKey", "", "TLS private key file (PEM)")
+	fs.StringVar(&tlsClientCAFile, "tlsClientCA", "", "gRPC client CA bundle (PEM) for mTLS")
+	fs.BoolVar(&tlsRequireClientCert, "tlsRequireClientCert", false, "require gRPC client certificates")
+	fs.IntVar(&tlsReloadIntervalSec, "tlsReload", 0, "TLS certificate reload check interval (s)")
+	fs.IntVar(&readRatePerSec, "readRate", 0, "read requests per second per client, 0 for no limit")
+	fs.IntVar(&readBurst, "readBurst", 0, "read burst per client, 0 for the rate")
+	fs.IntVar(&ingestRatePerSec, "ingestRate", 0, "ingest requests per second per client, 0 for no limit")
+	fs.IntVar(&ingestBurst, "ingestBurst", 0, "ingest burst per client, 0 for the rate")
+	fs.StringVar(&trustedProxies, "trustedProxies", "", "comma separated proxy IPs or CIDRs trusted for the client IP (default: none)")
+	fs.Int64Var(&maxUpload, "maxUpload", 0, "max REST upload (import, restore) size (MiB)")
+	fs.Int64Var(&maxQuerySpan, "maxSpan", 0, "max range query span (us), 0 for no cap")
+	fs.StringVar(&retention, "retention", "", "per tenant retention, e.g. \"*=720h;tenant1=72h\"")
+	fs.StringVar(&tsPrecision, "tsPrecision", "", "integer timestamp precision: s, ms, us or ns")
+	fs.IntVar(&subscriberBuffer, "subBuffer", 0, "max records buffered per live subscriber")
+	fs.StringVar(&alertRulesFile, "alertRules", "", "alert rules file (JSON array)")
+	fs.StringVar(&webhooksFile, "webhooks", "", "webhook endpoints file (JSON array)")
+	fs.IntVar(&webhookQueue, "webhookQueue", 0, "webhook delivery queue size")
+	fs.IntVar(&webhookWorkers, "webhookWorkers", 0, "concurrent webhook deliveries")
+	fs.IntVar(&webhookMaxAttempts, "webhookAttempts", 0, "webhook delivery attempts")
+	fs.IntVar(&anomalyWindow, "anomalyWindow", 0, "anomaly detector rolling window (records)")
+	fs.Float64Var(&anomalyAlpha, "anomalyAlpha", 0, "anomaly detector EWMA smoothing factor (0, 1]")
+	fs.Float64Var(&anomalyThreshold, "anomalyThreshold", 0, "anomaly score threshold (z-score)")
+	fs.DurationVar(&windowSize, "windowSize", 0, "sliding window size, e.g. 1m")
+	fs.DurationVar(&windowSlide, "windowSlide", 0, "sliding window slide, e.g. 15s")
+	fs.DurationVar(&sessionGap, "sessionGap", 0, "session window gap, e.g. 30s")
+	fs.DurationVar(&lateness, "lateness", 0, "allowed lateness of windowed records, e.g. 10s")
+	fs.StringVar(&redisAddr, "redisAddr", "", "Redis server address, e.g. localhost:6379 (default: embedded in-memory server)")
+
+	// Flags must be registered before parsing, otherwise they are rejected as unknown.
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Flags whose zero value is a valid setting apply whenever they are passed
+	passed := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { passed[f.Name] = true })
 
-	flag.IntVar(&workersCount, "workersCount", 0, "workers count")
 	if workersCount > 0 {
 		cfg.WorkersCount = workersCount
 	}
 
-	flag.IntVar(&metricsBatchSize, "b", 0, "metrics batch size")
 	if metricsBatchSize > 0 {
 		cfg.MetricsBatchSize = metricsBatchSize
 	}
 
-	flag.IntVar(&restPort, "r", 0, "rest port")
 	if restPort > 0 {
 		cfg.RestPort = restPort
 	}
 
-	flag.IntVar(&grpcPort, "g", 0, "grpc port")
 	if grpcPort > 0 {
 		cfg.GrpcPort = grpcPort
 	}
 
-	flag.IntVar(&inputIntervalMs, "n", 0, "input interval")
 	if inputIntervalMs > 0 {
 		cfg.InputIntervalMs = inputIntervalMs
 	}
 
-	flag.IntVar(&packLength, "l", 0, "input pack length")
 	if packLength > 0 {
 		cfg.PackLength = packLength
 	}
+
+	if passed["seed"] {
+		cfg.InputSeed = &inputSeed
+	}
+
+	if inputRecordFile != "" {
+		cfg.InputRecordFile = inputRecordFile
+	}
+
+	if inputReplayFile != "" {
+		cfg.InputReplayFile = inputReplayFile
+	}
+
+	if passed["replaySpeed"] {
+		cfg.InputReplaySpeed = inputReplaySpeed
+	}
+
+	if apiKeys != "" {
+		cfg.APIKeys = apiKeys
+	}
+
+	if jwtHS256Secret != "" {
+		cfg.JWTHS256Secret = jwtHS256Secret
+	}
+
+	if jwtRS256PublicKeyFile != "" {
+		cfg.JWTRS256PublicKeyFile = jwtRS256PublicKeyFile
+	}
+
+	if tlsCertFile != "" {
+		cfg.TLSCertFile = tlsCertFile
+	}
+
+	if tlsKeyFile != "" {
+		cfg.TLSKeyFile = tlsKeyFile
+	}
+
+	if tlsClientCAFile != "" {
+		cfg.TLSClientCAFile = tlsClientCAFile
+	}
+
+	if tlsRequireClientCert {
+		cfg.TLSRequireClientCert = true
+	}
+
+	if tlsReloadIntervalSec > 0 {
+		cfg.TLSReloadIntervalSec = tlsReloadIntervalSec
+	}
+
+	if passed["readRate"] {
+		cfg.ReadRatePerSec = readRatePerSec
+	}
+
+	if passed["readBurst"] {
+		cfg.ReadBurst = readBurst
+	}
+
+	if passed["ingestRate"] {
+		cfg.IngestRatePerSec = ingestRatePerSec
+	}
+
+	if passed["ingestBurst"] {
+		cfg.IngestBurst = ingestBurst
+	}
+
+	if trustedProxies != "" {
+		cfg.TrustedProxies = trustedProxies
+	}
+
+	if maxUpload > 0 {
+		cfg.MaxUploadMB = maxUpload
+	}
+
+	if passed["maxSpan"] {
+		cfg.MaxQuerySpan = maxQuerySpan
+	}
+
+	if retention != "" {
+		cfg.Retention = retention
+	}
+
+	if tsPrecision != "" {
+		cfg.TimestampPrecision = tsPrecision
+	}
+
+	if subscriberBuffer > 0 {
+		cfg.SubscriberBuffer = subscriberBuffer
+	}
+
+	if alertRulesFile != "" {
+		cfg.AlertRulesFile = alertRulesFile
+	}
+
+	if webhooksFile != "" {
+		cfg.WebhooksFile = webhooksFile
+	}
+
+	if webhookQueue > 0 {
+		cfg.WebhookQueue = webhookQueue
+	}
+
+	if webhookWorkers > 0 {
+		cfg.WebhookWorkers = webhookWorkers
+	}
+
+	if webhookMaxAttempts > 0 {
+		cfg.WebhookMaxAttempts = webhookMaxAttempts
+	}
+
+	if anomalyWindow > 0 {
+		cfg.AnomalyWindow = anomalyWindow
+	}
+
+	if anomalyAlpha > 0 {
+		cfg.AnomalyAlpha = anomalyAlpha
+	}
+
+	if anomalyThreshold > 0 {
+		cfg.AnomalyThreshold = anomalyThreshold
+	}
+
+	if windowSize > 0 {
+		cfg.WindowSize = windowSize
+	}
+
+	if windowSlide > 0 {
+		cfg.WindowSlide = windowSlide
+	}
+
+	if sessionGap > 0 {
+		cfg.SessionGap = sessionGap
+	}
+
+	if lateness > 0 {
+		cfg.AllowedLateness = lateness
+	}
+
+	if redisAddr != "" {
+		cfg.RedisAddr = redisAddr
+	}
+
+	return nil
 }
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateFromFlags(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		check func(t *testing.T, cfg *XisDataAggregatorConfig)
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, cfg *XisDataAggregatorConfig) {
+				assert.Equal(t, readRatePerSec, cfg.ReadRatePerSec)
+				assert.Equal(t, ingestRatePerSec, cfg.IngestRatePerSec)
+				assert.Equal(t, int64(maxQuerySpan), cfg.MaxQuerySpan)
+				assert.Nil(t, cfg.InputSeed)
+			},
+		},
+		{
+			name: "zero disables the limits",
+			args: []string{"-readRate=0", "-readBurst=0", "-ingestRate=0", "-ingestBurst=0", "-maxSpan=0", "-seed=0"},
+			check: func(t *testing.T, cfg *XisDataAggregatorConfig) {
+				assert.Zero(t, cfg.ReadRatePerSec)
+				assert.Zero(t, cfg.ReadBurst)
+				assert.Zero(t, cfg.IngestRatePerSec)
+				assert.Zero(t, cfg.IngestBurst)
+				assert.Zero(t, cfg.MaxQuerySpan)
+				require.NotNil(t, cfg.InputSeed)
+				assert.Zero(t, *cfg.InputSeed)
+			},
+		},
+		{
+			name: "values",
+			args: []string{"-readRate", "5", "-maxSpan", "1000", "-workersCount", "0"},
+			check: func(t *testing.T, cfg *XisDataAggregatorConfig) {
+				assert.Equal(t, 5, cfg.ReadRatePerSec)
+				assert.Equal(t, int64(1000), cfg.MaxQuerySpan)
+				assert.Equal(t, workersCount, cfg.WorkersCount, "zero keeps the default")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := GetXisDataAggregatorConfig()
+			require.NoError(t, err)
+			require.NoError(t, cfg.updateFromFlags(flag.NewFlagSet("test", flag.ContinueOnError), tt.args))
+			tt.check(t, cfg)
+		})
+	}
+}
@@ -15,26 +15,96 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/data": {
+        "/admin/backup": {
             "get": {
-                "description": "get data by time range",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "download a versioned, checksummed archive of all data records of the caller's tenant (admin)",
+                "produces": [
+                    "application/octet-stream"
+                ],
                 "tags": [
-                    "data"
+                    "admin"
                 ],
-                "summary": "List data by time range",
+                "summary": "Back up data",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/data": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "delete all data records of the caller's tenant within a time range (admin)",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete data by time range",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "From timestamp",
+                        "type": "string",
+                        "description": "From timestamp (inclusive): RFC3339, unit-suffixed or integer in the declared precision",
                         "name": "from",
                         "in": "query",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "To timestamp",
+                        "type": "string",
+                        "description": "To timestamp (inclusive): RFC3339, unit-suffixed or integer in the declared precision",
                         "name": "to",
                         "in": "query",
                         "required": true
@@ -44,9 +114,10 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Data"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer",
+                                "format": "int64"
                             }
                         }
                     },
@@ -59,8 +130,117 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "restore the records of a backup archive into the caller's tenant (admin); records with the IDs of existing ones overwrite them, others are kept. The archive is verified before anything is stored.",
+                "consumes": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Restore data",
+                "parameters": [
+                    {
+                        "description": "Backup archive",
+                        "name": "archive",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/backup.Result"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -80,27 +260,107 @@ const docTemplate = `{
                 }
             }
         },
-        "/data/{id}": {
+        "/admin/webhooks": {
             "get": {
-                "description": "get data by UUID",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the webhook endpoints of the caller's tenant, without secrets (admin)",
                 "tags": [
-                    "data"
+                    "admin"
                 ],
-                "summary": "Get data by ID",
+                "summary": "List webhook endpoints",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookEndpoint"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the most recent webhook delivery attempts of the caller's tenant, newest first (admin)",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List webhook deliveries",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Data ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Only attempts to this endpoint ID",
+                        "name": "endpoint",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of attempts (default 100, at most 1000)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Data"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDelivery"
+                            }
                         }
                     },
                     "400": {
@@ -112,8 +372,26 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -129,25 +407,2495 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.Data": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "string"
-                },
-                "max": {
-                    "type": "integer"
-                },
-                "ts": {
-                    "type": "integer"
-                }
-            }
+        },
+        "/admin/webhooks/{id}/test": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "send a sample ` + "`" + `test` + "`" + ` event to the endpoint once, without retries, and return the attempt (admin)",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Test webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Endpoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDelivery"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/alerts": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the alert states of the caller's tenant, one per rule and series",
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "List alerts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only alerts in this state: firing or resolved",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only alerts of this rule ID",
+                        "name": "rule",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Alert"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/anomalies": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the records flagged by the anomaly detector within a time range, with their anomaly_score",
+                "tags": [
+                    "data"
+                ],
+                "summary": "List anomalies by time range",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "From timestamp: RFC3339, unit-suffixed (e.g. 1704067200s, 1704067200000ms) or integer in the declared precision",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "To timestamp: RFC3339, unit-suffixed (e.g. 1704153600s, 1704153600000ms) or integer in the declared precision",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to rfc3339 to render ts as an RFC3339 string",
+                        "name": "ts_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Series name",
+                        "name": "series",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Label matchers (name=value, name!=value, name=~regexp, name!~regexp)",
+                        "name": "label",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Data"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/data": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get data by time range",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.apache.parquet"
+                ],
+                "tags": [
+                    "data"
+                ],
+                "summary": "List data by time range",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "From timestamp: RFC3339, unit-suffixed (e.g. 1704067200s, 1704067200000ms) or integer in the declared precision",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "To timestamp: RFC3339, unit-suffixed (e.g. 1704153600s, 1704153600000ms) or integer in the declared precision",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to rfc3339 to render ts as an RFC3339 string",
+                        "name": "ts_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Series name",
+                        "name": "series",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Label matchers (name=value, name!=value, name=~regexp, name!~regexp)",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default), csv, ndjson or parquet; overrides the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Data"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/data/downsample": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get at most ` + "`" + `points` + "`" + ` records per series within a time range, selected by Largest-Triangle-Three-Buckets (lttb) or the minimum and maximum per time bucket (minmax)",
+                "tags": [
+                    "data"
+                ],
+                "summary": "Downsample data by time range",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "From timestamp: RFC3339, unit-suffixed (e.g. 1704067200s, 1704067200000ms) or integer in the declared precision",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "To timestamp: RFC3339, unit-suffixed (e.g. 1704153600s, 1704153600000ms) or integer in the declared precision",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum records per series, 3 to 10000 (default 500)",
+                        "name": "points",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "lttb (default) or minmax",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to rfc3339 to render ts as an RFC3339 string",
+                        "name": "ts_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Series name",
+                        "name": "series",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Label matchers (name=value, name!=value, name=~regexp, name!~regexp)",
+                        "name": "label",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Data"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/data/stream": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "stream records as they are stored, as ` + "`" + `data` + "`" + ` events with a resumable ID",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "data"
+                ],
+                "summary": "Live feed (Server-Sent Events)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resume after this event ID",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Series name",
+                        "name": "series",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Label matchers (name=value, name!=value, name=~regexp, name!~regexp)",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Only records with max \u003e= min_max",
+                        "name": "min_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Slow consumer policy: drop (default) or disconnect",
+                        "name": "policy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Records buffered for the client, 0 - server maximum",
+                        "name": "buffer",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to rfc3339 to render ts as an RFC3339 string",
+                        "name": "ts_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Data"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/data/ws": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "stream records as they are stored, as JSON messages {\"id\": event ID, \"data\": record}",
+                "tags": [
+                    "data"
+                ],
+                "summary": "Live feed (WebSocket)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resume after this event ID",
+                        "name": "last_event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Series name",
+                        "name": "series",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Label matchers (name=value, name!=value, name=~regexp, name!~regexp)",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Only records with max \u003e= min_max",
+                        "name": "min_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Slow consumer policy: drop (default) or disconnect",
+                        "name": "policy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Records buffered for the client, 0 - server maximum",
+                        "name": "buffer",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to rfc3339 to render ts as an RFC3339 string",
+                        "name": "ts_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/data/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get data by UUID",
+                "tags": [
+                    "data"
+                ],
+                "summary": "Get data by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Data ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to rfc3339 to render ts as an RFC3339 string",
+                        "name": "ts_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Data"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "delete a data record by UUID",
+                "tags": [
+                    "data"
+                ],
+                "summary": "Delete data by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Data ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/data:batchGet": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get several data records by UUID in one call",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "data"
+                ],
+                "summary": "Get data by IDs",
+                "parameters": [
+                    {
+                        "description": "IDs",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.BatchGetRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to rfc3339 to render ts as an RFC3339 string",
+                        "name": "ts_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.BatchGetResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "check that the service and its repository respond; no credentials required",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "bulk import of historical packs from a CSV file (header row with ts, data as ';'-separated samples and optionally id, type, series, labels as a JSON object) or NDJSON (one pack per line). Invalid records are reported and skipped. The response streams one progress line per stored batch; ` + "`" + `records` + "`" + ` of the last one is the checkpoint to resume from.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "data"
+                ],
+                "summary": "Import packs from a file",
+                "parameters": [
+                    {
+                        "description": "Pack file, ts in the declared precision",
+                        "name": "file",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv or ndjson, overrides the Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate the packs without storing them",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Records of the file already imported, skipped",
+                        "name": "checkpoint",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Packs stored per batch, 1 to 5000 (default 500)",
+                        "name": "batch_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/rest.importStatus"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/packs": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "submit raw packs for aggregation and storage",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "data"
+                ],
+                "summary": "Ingest packs",
+                "parameters": [
+                    {
+                        "description": "Packs, ts in the declared precision",
+                        "name": "packs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Pack"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to rfc3339 to render ts as an RFC3339 string",
+                        "name": "ts_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Data"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rules": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the alert rules of the caller's tenant",
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "List alert rules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Rule"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "create an alert rule evaluated on every stored record of the caller's tenant (admin)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Create alert rule",
+                "parameters": [
+                    {
+                        "description": "Rule",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Rule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Rule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rules/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get an alert rule by ID",
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Get alert rule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Rule"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "replace an alert rule, its windows and counters restart (admin)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Replace alert rule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rule, the ID is taken from the path",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Rule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Rule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "delete an alert rule and resolve its firing alerts (admin)",
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Delete alert rule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get ingestion and query counters of the caller's tenant",
+                "tags": [
+                    "data"
+                ],
+                "summary": "Get tenant stats",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/metrics.TenantCounters"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/windows": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get the sliding and session window aggregates of the series starting within a time range, ordered by start",
+                "tags": [
+                    "data"
+                ],
+                "summary": "List window results by time range",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "From window start: RFC3339, unit-suffixed (e.g. 1704067200s, 1704067200000ms) or integer in the declared precision",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "To window start: RFC3339, unit-suffixed (e.g. 1704153600s, 1704153600000ms) or integer in the declared precision",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Window kind: sliding or session",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to rfc3339 to render ts and end as RFC3339 strings",
+                        "name": "ts_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Series name",
+                        "name": "series",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Label matchers (name=value, name!=value, name=~regexp, name!~regexp)",
+                        "name": "label",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WindowResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "backup.Result": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "string"
+                },
+                "expired": {
+                    "description": "Records older than the retention of their tenant, not stored",
+                    "type": "integer"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "records": {
+                    "type": "integer"
+                },
+                "restored": {
+                    "description": "Records stored",
+                    "type": "integer"
+                },
+                "sha256": {
+                    "type": "string"
+                },
+                "tenants": {
+                    "description": "Records per tenant, \"\" - the default tenant",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "importer.RecordError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "record": {
+                    "type": "integer"
+                }
+            }
+        },
+        "metrics.TenantCounters": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "description": "Failed is the number of packs that failed to be processed or stored.",
+                    "type": "integer"
+                },
+                "ingested": {
+                    "description": "Ingested is the number of successfully stored packs.",
+                    "type": "integer"
+                },
+                "queries": {
+                    "description": "Queries is the number of read queries.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Alert": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "description": "Rule ID and series, unique within the tenant",
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "Labels of the records",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "resolved_at": {
+                    "description": "Time of the record that resolved the alert",
+                    "type": "string"
+                },
+                "rule_id": {
+                    "description": "Rule that raised the alert",
+                    "type": "string"
+                },
+                "series": {
+                    "description": "Series of the records",
+                    "type": "string"
+                },
+                "starts_at": {
+                    "description": "Time of the record that fired the alert",
+                    "type": "string"
+                },
+                "state": {
+                    "description": "firing or resolved",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.AlertState"
+                        }
+                    ]
+                },
+                "tenant": {
+                    "description": "Owner tenant",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Evaluated value of the last transition",
+                    "type": "number"
+                }
+            }
+        },
+        "models.AlertState": {
+            "type": "string",
+            "enum": [
+                "firing",
+                "resolved"
+            ],
+            "x-enum-varnames": [
+                "AlertFiring",
+                "AlertResolved"
+            ]
+        },
+        "models.Data": {
+            "type": "object",
+            "properties": {
+                "anomalous": {
+                    "description": "AnomalyScore reached the detection threshold",
+                    "type": "boolean"
+                },
+                "anomaly_score": {
+                    "description": "Deviation of Max from the recent series behavior in standard deviations, 0 during warm-up",
+                    "type": "number"
+                },
+                "id": {
+                    "description": "Unique identifier for the data record",
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "Source labels copied from the Pack",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "max": {
+                    "description": "Maximum value extracted from the original data array, typed as the series",
+                    "type": "number"
+                },
+                "series": {
+                    "description": "Source/series name copied from the Pack",
+                    "type": "string"
+                },
+                "tenant": {
+                    "description": "Owner tenant",
+                    "type": "string"
+                },
+                "ts": {
+                    "description": "Unix timestamp when the data was recorded",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Pack": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Array of sample values representing the raw data points",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "id": {
+                    "description": "UUID RFC9562 (psql 16 bytes) - Unique identifier for the data pack",
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "Arbitrary key-value metadata of the source",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "series": {
+                    "description": "Source/series name, e.g. the sensor or producer",
+                    "type": "string"
+                },
+                "ts": {
+                    "description": "Unix timestamp indicating when the data was collected",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "Declared sample value type, defaults to the series type or int64",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ValueType"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.Rule": {
+            "type": "object",
+            "properties": {
+                "aggregation": {
+                    "description": "Window aggregation: avg (default), min, max, sum or count",
+                    "type": "string"
+                },
+                "for": {
+                    "description": "Consecutive records the condition must hold, 0 or 1 - one record",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "Unique within the tenant",
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "Label matchers (name=value, name!=value, name=~regexp, name!~regexp)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "Human readable name",
+                    "type": "string"
+                },
+                "op": {
+                    "description": "Comparison: \u003e, \u003e=, \u003c or \u003c=",
+                    "type": "string"
+                },
+                "series": {
+                    "description": "Series name, empty - any series",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "config or api, set by the server",
+                    "type": "string"
+                },
+                "tenant": {
+                    "description": "Owner tenant",
+                    "type": "string"
+                },
+                "threshold": {
+                    "description": "Compared value",
+                    "type": "number"
+                },
+                "window": {
+                    "description": "Rolling window, e.g. \"5m\", 0 - the record alone",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ValueType": {
+            "type": "string",
+            "enum": [
+                "int64",
+                "float64"
+            ],
+            "x-enum-comments": {
+                "ValueTypeFloat64": "IEEE 754 double precision floating-point numbers",
+                "ValueTypeInt64": "Signed 64-bit integers (default)"
+            },
+            "x-enum-descriptions": [
+                "Signed 64-bit integers (default)",
+                "IEEE 754 double precision floating-point numbers"
+            ],
+            "x-enum-varnames": [
+                "ValueTypeInt64",
+                "ValueTypeFloat64"
+            ]
+        },
+        "models.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "description": "Attempt number, starting at 1",
+                    "type": "integer"
+                },
+                "duration_ms": {
+                    "description": "Duration of the attempt",
+                    "type": "integer"
+                },
+                "endpoint_id": {
+                    "description": "Target endpoint",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Failure reason",
+                    "type": "string"
+                },
+                "final": {
+                    "description": "No further attempt follows",
+                    "type": "boolean"
+                },
+                "id": {
+                    "description": "Delivery ID, the same for all attempts of a record",
+                    "type": "string"
+                },
+                "record_id": {
+                    "description": "Delivered record",
+                    "type": "string"
+                },
+                "status_code": {
+                    "description": "HTTP status code, 0 if no response",
+                    "type": "integer"
+                },
+                "success": {
+                    "description": "2xx response",
+                    "type": "boolean"
+                },
+                "tenant": {
+                    "description": "Owner tenant",
+                    "type": "string"
+                },
+                "test": {
+                    "description": "Test delivery",
+                    "type": "boolean"
+                },
+                "time": {
+                    "description": "Start of the attempt",
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookEndpoint": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "Unique within the tenant",
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "Label matchers (name=value, name!=value, name=~regexp, name!~regexp)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "min_max": {
+                    "description": "Only records with max \u003e= min_max",
+                    "type": "number"
+                },
+                "secret": {
+                    "description": "HMAC-SHA256 signing key, never returned by the API",
+                    "type": "string"
+                },
+                "series": {
+                    "description": "Series name, empty - any series",
+                    "type": "string"
+                },
+                "tenant": {
+                    "description": "Owner tenant",
+                    "type": "string"
+                },
+                "test": {
+                    "description": "Test mode: allows plain HTTP and loopback targets such as a local stand-in",
+                    "type": "boolean"
+                },
+                "url": {
+                    "description": "Target URL, https unless Test",
+                    "type": "string"
+                }
+            }
+        },
+        "models.WindowKind": {
+            "type": "string",
+            "enum": [
+                "sliding",
+                "session"
+            ],
+            "x-enum-comments": {
+                "WindowSession": "Records of a series separated by less than the session gap",
+                "WindowSliding": "Fixed size windows starting every slide"
+            },
+            "x-enum-descriptions": [
+                "Fixed size windows starting every slide",
+                "Records of a series separated by less than the session gap"
+            ],
+            "x-enum-varnames": [
+                "WindowSliding",
+                "WindowSession"
+            ]
+        },
+        "models.WindowResult": {
+            "type": "object",
+            "properties": {
+                "avg": {
+                    "description": "Average of the record Max values",
+                    "type": "number"
+                },
+                "count": {
+                    "description": "Records within the window",
+                    "type": "integer"
+                },
+                "end": {
+                    "description": "Window end, exclusive",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "Derived from tenant, series, kind and start, stable across revisions",
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "sliding or session",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.WindowKind"
+                        }
+                    ]
+                },
+                "labels": {
+                    "description": "Series labels",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "max": {
+                    "description": "Maximum record Max, typed as the series",
+                    "type": "number"
+                },
+                "min": {
+                    "description": "Minimum record Max, typed as the series",
+                    "type": "number"
+                },
+                "revision": {
+                    "description": "0 for the first emission, incremented by every late update",
+                    "type": "integer"
+                },
+                "series": {
+                    "description": "Series name",
+                    "type": "string"
+                },
+                "sum": {
+                    "description": "Sum of the record Max values",
+                    "type": "number"
+                },
+                "tenant": {
+                    "description": "Owner tenant",
+                    "type": "string"
+                },
+                "ts": {
+                    "description": "Window start, inclusive, stored Unix microseconds",
+                    "type": "integer"
+                }
+            }
+        },
+        "rest.BatchGetRequest": {
+            "type": "object",
+            "required": [
+                "ids"
+            ],
+            "properties": {
+                "ids": {
+                    "description": "Record UUIDs, at most 1000",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "rest.BatchGetResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Records found, in request order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rest.dataView"
+                    }
+                },
+                "missing": {
+                    "description": "IDs without a record, in request order",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "rest.dataView": {
+            "type": "object",
+            "properties": {
+                "anomalous": {
+                    "description": "AnomalyScore reached the detection threshold",
+                    "type": "boolean"
+                },
+                "anomaly_score": {
+                    "description": "Deviation of Max from the recent series behavior in standard deviations, 0 during warm-up",
+                    "type": "number"
+                },
+                "id": {
+                    "description": "Unique identifier for the data record",
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "Source labels copied from the Pack",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "max": {
+                    "description": "Maximum value extracted from the original data array, typed as the series",
+                    "type": "number"
+                },
+                "series": {
+                    "description": "Source/series name copied from the Pack",
+                    "type": "string"
+                },
+                "tenant": {
+                    "description": "Owner tenant",
+                    "type": "string"
+                },
+                "ts": {
+                    "description": "Integer in the declared precision or RFC3339 string, shadows Data.Timestamp"
+                }
+            }
+        },
+        "rest.importStatus": {
+            "type": "object",
+            "properties": {
+                "done": {
+                    "type": "boolean"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Errors of the first failed records",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/importer.RecordError"
+                    }
+                },
+                "failed": {
+                    "description": "Malformed or invalid records",
+                    "type": "integer"
+                },
+                "imported": {
+                    "description": "Valid records passed to the sink",
+                    "type": "integer"
+                },
+                "records": {
+                    "description": "Records read, including skipped ones: the checkpoint to resume from",
+                    "type": "integer"
+                },
+                "skipped": {
+                    "description": "Records skipped up to the checkpoint",
+                    "type": "integer"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
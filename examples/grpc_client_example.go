@@ -29,6 +29,10 @@ func main() {
 	// Example 2: List data by time range
 	fmt.Println("\n=== ListDataByTimeRange Example ===")
 	listDataByTimeRangeExample(client)
+
+	// Example 3: List data by typed time range
+	fmt.Println("\n=== ListDataByTimeRangeV2 Example ===")
+	listDataByTimeRangeV2Example(client)
 }
 
 func getDataByIDExample(client pb.DataServiceClient) {
@@ -56,8 +60,13 @@ func getDataByIDExample(client pb.DataServiceClient) {
 		log.Fatalf("Failed to receive response: %v", err)
 	}
 
-	fmt.Printf("Received data: ID=%s, Timestamp=%d, Max=%d\n",
-		response.Id, response.Timestamp, response.Max)
+	// Missing or invalid IDs are reported in the item status, the stream stays open
+	if response.Status != pb.ItemStatus_ITEM_STATUS_OK {
+		fmt.Printf("Data not received: ID=%s, Status=%s, Error=%s\n", response.Id, response.Status, response.Error)
+	} else {
+		fmt.Printf("Received data: ID=%s, Timestamp=%d, Max=%d\n",
+			response.Id, response.Timestamp, response.Max)
+	}
 
 	// Close the stream
 	if err := stream.CloseSend(); err != nil {
@@ -77,8 +86,52 @@ func listDataByTimeRangeExample(client pb.DataServiceClient) {
 
 	// Send request
 	request := &pb.ListDataByTimeRangeRequest{
-		From: "1640995200", // 2022-01-01 00:00:00 UTC
-		To:   "1641081600", // 2022-01-02 00:00:00 UTC
+		From: "1640995200s", // 2022-01-01 00:00:00 UTC
+		To:   "1641081600s", // 2022-01-02 00:00:00 UTC
+	}
+
+	if err := stream.Send(request); err != nil {
+		log.Fatalf("Failed to send request: %v", err)
+	}
+
+	// Receive response
+	response, err := stream.Recv()
+	if err != nil {
+		log.Fatalf("Failed to receive response: %v", err)
+	}
+
+	if response.Status != pb.ItemStatus_ITEM_STATUS_OK {
+		fmt.Printf("No data received: Status=%s, Error=%s\n", response.Status, response.Error)
+	}
+
+	fmt.Printf("Received %d data items:\n", len(response.DataItems))
+	for i, data := range response.DataItems {
+		fmt.Printf("  [%d] ID=%s, Timestamp=%d, Max=%d\n",
+			i+1, data.Id, data.Timestamp, data.Max)
+	}
+
+	// Close the stream
+	if err := stream.CloseSend(); err != nil {
+		log.Fatalf("Failed to close stream: %v", err)
+	}
+}
+
+func listDataByTimeRangeV2Example(client pb.DataServiceClient) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Create bidirectional stream
+	stream, err := client.ListDataByTimeRangeV2(ctx)
+	if err != nil {
+		log.Fatalf("Failed to create stream: %v", err)
+	}
+
+	// Send request: the latest 10 items of the day, timestamps in the server precision (Unix microseconds by default)
+	request := &pb.ListDataByTimeRangeRequestV2{
+		From:  time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC).UnixMicro(),
+		To:    time.Date(2022, time.January, 2, 0, 0, 0, 0, time.UTC).UnixMicro(),
+		Limit: 10,
+		Order: pb.Order_ORDER_DESC,
 	}
 
 	if err := stream.Send(request); err != nil {
@@ -91,6 +144,10 @@ func listDataByTimeRangeExample(client pb.DataServiceClient) {
 		log.Fatalf("Failed to receive response: %v", err)
 	}
 
+	if response.Status != pb.ItemStatus_ITEM_STATUS_OK {
+		fmt.Printf("No data received: Status=%s, Error=%s\n", response.Status, response.Error)
+	}
+
 	fmt.Printf("Received %d data items:\n", len(response.DataItems))
 	for i, data := range response.DataItems {
 		fmt.Printf("  [%d] ID=%s, Timestamp=%d, Max=%d\n",
@@ -0,0 +1,441 @@
+// Package alerting evaluates threshold rules on stored Data records and tracks the resulting alerts.
+package alerting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/golang/glog"
+)
+
+var (
+	ErrInvalidRule  = errors.New("invalid rule")
+	ErrRuleNotFound = errors.New("rule not found")
+	ErrRuleExists   = errors.New("rule already exists")
+	ErrRuleReadOnly = errors.New("rule is read-only")
+)
+
+const (
+	// maxWindowSamples caps the records kept per rule window and series, the earliest are evicted first.
+	maxWindowSamples = 100_000
+	// idleSeriesTTL is how long the state of a series without records is kept, at least the rule window.
+	idleSeriesTTL = time.Hour
+)
+
+// seriesState is the evaluation state of a rule for one series.
+type seriesState struct {
+	window   window // Records within the rule window
+	latest   int64  // Latest record timestamp, the end of the window
+	matched  int    // Consecutive records satisfying the condition
+	firing   bool
+	lastSeen time.Time // Last record arrival
+}
+
+// rule is a validated rule with its compiled selector and per series state.
+type rule struct {
+	models.Rule
+	filter *models.Filter
+	series map[string]*seriesState // By series key
+}
+
+// Engine evaluates the rules of a record's tenant on every stored record. Rule windows and consecutive
+// counters are kept in memory, those of series idle for idleSeriesTTL are evicted. Alert states are persisted
+// in the store on every transition, after the evaluation releases the lock.
+type Engine struct {
+	mu        sync.Mutex
+	store     models.AlertStore
+	rules     map[string]map[string]*rule         // By tenant and rule ID
+	alerts    map[string]map[string]*models.Alert // Last known alert states by tenant and key, loaded on first use
+	pending   []models.Alert                      // Transitions to persist, in order
+	lastSweep time.Time
+	now       func() time.Time // Clock, replaceable in tests
+
+	writeMu sync.Mutex // Held while persisting transitions, so that concurrent writes keep their order
+}
+
+// New creates an engine persisting rules and alerts in the store. Call Load before use.
+func New(store models.AlertStore) *Engine {
+	return &Engine{
+		store:  store,
+		rules:  make(map[string]map[string]*rule),
+		alerts: make(map[string]map[string]*models.Alert),
+		now:    time.Now,
+	}
+}
+
+// ReadRulesFile reads a JSON array of rules, e.g. [{"id": "cpu-high", "series": "cpu", "op": ">", "threshold": 900}].
+func ReadRulesFile(path string) ([]models.Rule, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []models.Rule
+	if err := json.Unmarshal(b, &rules); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// Load registers the read-only config rules and the rules stored in the store.
+// A stored rule with the tenant and ID of a config rule is ignored.
+func (e *Engine) Load(config []models.Rule) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, r := range config {
+		r.Source = models.RuleSourceConfig
+		if _, err := e.add(r); err != nil {
+			return fmt.Errorf("config rule %q: %w", r.ID, err)
+		}
+	}
+
+	stored, err := e.store.ListRules()
+	if err != nil {
+		return err
+	}
+	for _, r := range stored {
+		if e.rules[r.Tenant][r.ID] != nil {
+			glog.Warningf("Stored alert rule %q of tenant %q is shadowed by a config rule", r.ID, r.Tenant)
+			continue
+		}
+		r.Source = models.RuleSourceAPI
+		if _, err := e.add(r); err != nil {
+			glog.Errorf("Stored alert rule %q of tenant %q skipped: %v", r.ID, r.Tenant, err)
+		}
+	}
+
+	return nil
+}
+
+// add validates and registers a rule, replacing the state of a rule with the same tenant and ID.
+func (e *Engine) add(r models.Rule) (*rule, error) {
+	if err := r.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	filter, _ := r.Filter() // checked by Validate
+
+	compiled := &rule{Rule: r, filter: filter, series: make(map[string]*seriesState)}
+	if e.rules[r.Tenant] == nil {
+		e.rules[r.Tenant] = make(map[string]*rule)
+	}
+	e.rules[r.Tenant][r.ID] = compiled
+	return compiled, nil
+}
+
+// Rules returns the rules of the tenant sorted by ID.
+func (e *Engine) Rules(tenant string) []models.Rule {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	rules := make([]models.Rule, 0, len(e.rules[tenant]))
+	for _, r := range e.rules[tenant] {
+		rules = append(rules, r.Rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
+
+// Rule returns a rule of the tenant. Returns ErrRuleNotFound if there is no such rule.
+func (e *Engine) Rule(tenant, id string) (models.Rule, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	r := e.rules[tenant][id]
+	if r == nil {
+		return models.Rule{}, ErrRuleNotFound
+	}
+	return r.Rule, nil
+}
+
+// CreateRule validates, persists and registers a new rule.
+// Returns ErrInvalidRule if the rule is invalid or ErrRuleExists if the tenant has a rule with the ID.
+func (e *Engine) CreateRule(r models.Rule) (models.Rule, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.rules[r.Tenant][r.ID] != nil {
+		return models.Rule{}, ErrRuleExists
+	}
+	return e.put(r)
+}
+
+// UpdateRule validates, persists and registers a rule replacing the one with the same ID.
+// The windows and consecutive counters of the rule restart, firing alerts stay firing until they resolve.
+// Returns ErrInvalidRule if the rule is invalid, ErrRuleNotFound if there is no such rule,
+// or ErrRuleReadOnly if it is a config rule.
+func (e *Engine) UpdateRule(r models.Rule) (models.Rule, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	switch old := e.rules[r.Tenant][r.ID]; {
+	case old == nil:
+		return models.Rule{}, ErrRuleNotFound
+	case old.Source == models.RuleSourceConfig:
+		return models.Rule{}, ErrRuleReadOnly
+	}
+	return e.put(r)
+}
+
+// put validates, persists and registers an API rule.
+func (e *Engine) put(r models.Rule) (models.Rule, error) {
+	r.Source = models.RuleSourceAPI
+	if err := r.Validate(); err != nil {
+		return models.Rule{}, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	if err := e.store.PutRule(&r); err != nil {
+		return models.Rule{}, err
+	}
+
+	compiled, err := e.add(r)
+	if err != nil {
+		return models.Rule{}, err
+	}
+	return compiled.Rule, nil
+}
+
+// DeleteRule removes a rule and resolves its firing alerts.
+// Returns ErrRuleNotFound if there is no such rule or ErrRuleReadOnly if it is a config rule.
+func (e *Engine) DeleteRule(tenant, id string) error {
+	if err := e.lockAlerts(tenant); err != nil {
+		return err
+	}
+
+	switch r := e.rules[tenant][id]; {
+	case r == nil:
+		e.mu.Unlock()
+		return ErrRuleNotFound
+	case r.Source == models.RuleSourceConfig:
+		e.mu.Unlock()
+		return ErrRuleReadOnly
+	}
+
+	if err := e.store.DeleteRule(tenant, id); err != nil {
+		e.mu.Unlock()
+		return err
+	}
+	delete(e.rules[tenant], id)
+
+	now := e.now()
+	for _, alert := range e.alerts[tenant] {
+		if alert.RuleID == id && alert.State == models.AlertFiring {
+			e.transition(alert, models.AlertResolved, alert.Value, now)
+		}
+	}
+	e.mu.Unlock()
+
+	e.flush()
+	return nil
+}
+
+// Alerts returns the alerts of the tenant sorted by key, optionally only those in the state or of the rule.
+func (e *Engine) Alerts(tenant string, state models.AlertState, ruleID string) ([]models.Alert, error) {
+	stored, err := e.store.ListAlerts(tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := stored[:0]
+	for _, alert := range stored {
+		if (state == "" || alert.State == state) && (ruleID == "" || alert.RuleID == ruleID) {
+			alerts = append(alerts, alert)
+		}
+	}
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].Key < alerts[j].Key })
+	return alerts, nil
+}
+
+// Evaluate evaluates the rules of the record's tenant that select the record and persists the resulting
+// transitions. Safe to call on a nil engine.
+func (e *Engine) Evaluate(data *models.Data) {
+	if e == nil || data == nil {
+		return
+	}
+
+	if err := e.lockAlerts(data.Tenant); err != nil {
+		glog.Errorf("Alerts of tenant %q load error: %v", data.Tenant, err)
+		return
+	}
+
+	now := e.now()
+	e.sweep(now)
+	for _, r := range e.rules[data.Tenant] {
+		if r.filter.Matches(data) {
+			e.evaluate(r, data, now)
+		}
+	}
+	queued := len(e.pending) > 0
+	e.mu.Unlock()
+
+	if queued {
+		e.flush()
+	}
+}
+
+// evaluate updates the state of the rule for the record's series and queues a resulting transition.
+// Must be called with mu held and the alerts of the tenant loaded.
+func (e *Engine) evaluate(r *rule, data *models.Data, now time.Time) {
+	alerts := e.alerts[data.Tenant]
+	series := data.SeriesKey()
+	key := r.ID + "/" + series
+
+	st := r.series[series]
+	if st == nil {
+		// Alerts firing before a restart, a rule update or the eviction of the series stay firing until they resolve
+		st = &seriesState{firing: alerts[key] != nil && alerts[key].State == models.AlertFiring}
+		r.series[series] = st
+	}
+	st.lastSeen = now
+
+	value := data.Max.Float64()
+	if r.Window > 0 {
+		var ok bool
+		if value, ok = st.aggregate(r, data.Timestamp, value); !ok {
+			return // a late record outside the window
+		}
+	}
+
+	if compare(value, r.Op, r.Threshold) {
+		st.matched++
+	} else {
+		st.matched = 0
+	}
+
+	firing := st.matched >= max(r.For, 1)
+	if firing == st.firing {
+		return
+	}
+
+	alert := alerts[key]
+	if alert == nil {
+		alert = &models.Alert{Key: key, RuleID: r.ID, Tenant: data.Tenant, Series: data.Series, Labels: data.Labels}
+	}
+	state := models.AlertResolved
+	if firing {
+		state = models.AlertFiring
+	}
+	e.transition(alert, state, value, models.TimeOf(data.Timestamp))
+	st.firing = firing
+}
+
+// transition updates the cached state of the alert and queues it for flush. Must be called with mu held.
+func (e *Engine) transition(alert *models.Alert, state models.AlertState, value float64, at time.Time) {
+	at = at.UTC()
+	next := *alert
+	next.State = state
+	next.Value = value
+	if state == models.AlertFiring {
+		next.StartsAt = at
+		next.ResolvedAt = nil
+	} else {
+		next.ResolvedAt = &at
+	}
+
+	e.alerts[next.Tenant][next.Key] = &next
+	e.pending = append(e.pending, next)
+
+	glog.Infof("Alert %q of tenant %q %s, value %v", next.Key, next.Tenant, state, value)
+}
+
+// flush persists the queued transitions in order. Must be called without mu held. A failed write and the ones
+// queued after it are kept for the next flush.
+func (e *Engine) flush() {
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+
+	e.mu.Lock()
+	pending := e.pending
+	e.pending = nil
+	e.mu.Unlock()
+
+	for i := range pending {
+		if err := e.store.PutAlert(&pending[i]); err != nil {
+			glog.Errorf("Alert %q of tenant %q write error: %v", pending[i].Key, pending[i].Tenant, err)
+
+			e.mu.Lock()
+			e.pending = append(pending[i:], e.pending...)
+			e.mu.Unlock()
+			return
+		}
+	}
+}
+
+// lockAlerts locks mu with the alert states of the tenant loaded if it has rules, reading them from the store
+// on first use without mu held. As a first rule may be added to the tenant meanwhile, the check is repeated
+// until it holds under the lock. Returns with mu unlocked on error.
+func (e *Engine) lockAlerts(tenant string) error {
+	e.mu.Lock()
+	for len(e.rules[tenant]) > 0 && e.alerts[tenant] == nil {
+		e.mu.Unlock()
+
+		stored, err := e.store.ListAlerts(tenant)
+		if err != nil {
+			return err
+		}
+		alerts := make(map[string]*models.Alert, len(stored))
+		for i := range stored {
+			alerts[stored[i].Key] = &stored[i]
+		}
+
+		e.mu.Lock()
+		if e.alerts[tenant] == nil {
+			e.alerts[tenant] = alerts
+		}
+	}
+	return nil
+}
+
+// sweep evicts the series states idle for idleSeriesTTL, or the rule window if longer, at most once per
+// idleSeriesTTL. Must be called with mu held.
+func (e *Engine) sweep(now time.Time) {
+	if now.Sub(e.lastSweep) < idleSeriesTTL {
+		return
+	}
+	e.lastSweep = now
+
+	for _, rules := range e.rules {
+		for _, r := range rules {
+			ttl := max(idleSeriesTTL, time.Duration(r.Window))
+			for series, st := range r.series {
+				if now.Sub(st.lastSeen) > ttl {
+					delete(r.series, series)
+				}
+			}
+		}
+	}
+}
+
+// aggregate adds the record to the window and returns the window aggregation.
+// Returns false if the record is older than the window.
+func (st *seriesState) aggregate(r *rule, ts int64, value float64) (float64, bool) {
+	st.latest = max(st.latest, ts)
+	cutoff := st.latest - time.Duration(r.Window).Microseconds()
+	if ts <= cutoff {
+		return 0, false
+	}
+
+	st.window.add(r.Aggregation, ts, value)
+	st.window.evict(cutoff, maxWindowSamples)
+	return st.window.aggregate(r.Aggregation), true
+}
+
+// compare reports whether `value op threshold` holds.
+func compare(value float64, op string, threshold float64) bool {
+	switch op {
+	case models.OpGreater:
+		return value > threshold
+	case models.OpGreaterEqual:
+		return value >= threshold
+	case models.OpLess:
+		return value < threshold
+	case models.OpLessEqual:
+		return value <= threshold
+	}
+	return false
+}
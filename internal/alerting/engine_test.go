@@ -0,0 +1,285 @@
+package alerting
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/repository"
+	"xis-data-aggregator/internal/repository/repotest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// t0 is the timestamp of the first test record.
+var t0 = models.Timestamp(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+func newTestEngine(t *testing.T, config ...models.Rule) (*Engine, *repository.RedisRepository) {
+	repo := repotest.New(t)
+
+	e := New(repo)
+	require.NoError(t, e.Load(config))
+	return e, repo
+}
+
+func record(ts int64, max int64) *models.Data {
+	return &models.Data{ID: uuid.New(), Timestamp: ts, Max: models.IntValue(max), Series: "cpu", Labels: map[string]string{"host": "a"}}
+}
+
+// states returns the alert states of the default tenant by key.
+func states(t *testing.T, e *Engine) map[string]models.AlertState {
+	alerts, err := e.Alerts(models.DefaultTenant, "", "")
+	require.NoError(t, err)
+
+	got := make(map[string]models.AlertState)
+	for _, a := range alerts {
+		got[a.Key] = a.State
+	}
+	return got
+}
+
+func TestEvaluate(t *testing.T) {
+	const key = `r/cpu{host="a"}`
+	minute := time.Minute.Microseconds()
+
+	tests := []struct {
+		name   string
+		rule   models.Rule
+		values []int64 // One record per minute
+		want   []models.AlertState
+	}{
+		{
+			name:   "per record",
+			rule:   models.Rule{ID: "r", Series: "cpu", Op: ">", Threshold: 900},
+			values: []int64{100, 950, 950, 100},
+			want:   []models.AlertState{"", models.AlertFiring, models.AlertFiring, models.AlertResolved},
+		},
+		{
+			name:   "consecutive",
+			rule:   models.Rule{ID: "r", Series: "cpu", Op: ">", Threshold: 900, For: 3},
+			values: []int64{950, 950, 100, 950, 950, 950, 100},
+			want:   []models.AlertState{"", "", "", "", "", models.AlertFiring, models.AlertResolved},
+		},
+		{
+			name:   "window avg",
+			rule:   models.Rule{ID: "r", Op: ">=", Threshold: 50, Window: models.Duration(3 * time.Minute)},
+			values: []int64{0, 100, 50, 0, 0},
+			want:   []models.AlertState{"", models.AlertFiring, models.AlertFiring, models.AlertFiring, models.AlertResolved},
+		},
+		{
+			name:   "window count",
+			rule:   models.Rule{ID: "r", Labels: []string{"host=a"}, Op: ">", Threshold: 2, Window: models.Duration(150 * time.Second), Aggregation: models.AggCount},
+			values: []int64{1, 1, 1, 1},
+			want:   []models.AlertState{"", "", models.AlertFiring, models.AlertFiring},
+		},
+		{
+			name:   "other series",
+			rule:   models.Rule{ID: "r", Series: "mem", Op: ">", Threshold: 0},
+			values: []int64{1, 1},
+			want:   []models.AlertState{"", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := newTestEngine(t)
+			_, err := e.CreateRule(tt.rule)
+			require.NoError(t, err)
+
+			for i, v := range tt.values {
+				e.Evaluate(record(t0+int64(i)*minute, v))
+				assert.Equal(t, tt.want[i], states(t, e)[key], "record %d", i)
+			}
+		})
+	}
+}
+
+func TestWindow(t *testing.T) {
+	type rec struct{ ts, value int64 }
+	tests := []struct {
+		name    string
+		agg     string
+		records []rec // Added in order, each evicting up to ts-10
+		limit   int
+		want    []float64
+	}{
+		{
+			name:    "max",
+			agg:     models.AggMax,
+			records: []rec{{1, 5}, {2, 3}, {3, 4}, {12, 1}, {14, 2}, {25, 0}},
+			want:    []float64{5, 5, 5, 4, 2, 0},
+		},
+		{
+			name:    "min with late records",
+			agg:     models.AggMin,
+			records: []rec{{1, 5}, {10, 7}, {5, 2}, {9, 1}, {16, 8}, {20, 9}},
+			want:    []float64{5, 5, 2, 1, 1, 8},
+		},
+		{
+			name:    "sum",
+			agg:     models.AggSum,
+			records: []rec{{1, 1}, {5, 2}, {3, 4}, {12, 8}, {16, 16}},
+			want:    []float64{1, 3, 7, 14, 24},
+		},
+		{
+			name:    "avg capped",
+			agg:     models.AggAvg,
+			records: []rec{{1, 1}, {2, 3}, {3, 8}, {4, 4}},
+			limit:   2,
+			want:    []float64{1, 2, 5.5, 6},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit := tt.limit
+			if limit == 0 {
+				limit = maxWindowSamples
+			}
+
+			var w window
+			var latest int64
+			for i, r := range tt.records {
+				latest = max(latest, r.ts)
+				w.add(tt.agg, r.ts, float64(r.value))
+				w.evict(latest-10, limit)
+				assert.Equal(t, tt.want[i], w.aggregate(tt.agg), "record %d", i)
+			}
+		})
+	}
+}
+
+func TestSweep(t *testing.T) {
+	e, _ := newTestEngine(t)
+	now := time.Now()
+	e.now = func() time.Time { return now }
+	_, err := e.CreateRule(models.Rule{ID: "r", Op: ">", Threshold: 900, For: 2})
+	require.NoError(t, err)
+
+	other := record(t0, 950)
+	other.Labels = map[string]string{"host": "b"}
+	e.Evaluate(other)
+	now = now.Add(idleSeriesTTL + time.Second)
+	e.Evaluate(record(t0, 950))
+	e.Evaluate(record(t0+1, 950))
+	assert.Equal(t, models.AlertFiring, states(t, e)[`r/cpu{host="a"}`])
+	assert.Len(t, e.rules[models.DefaultTenant]["r"].series, 1, "the idle series is evicted")
+
+	// An evicted series starts over
+	e.Evaluate(other)
+	assert.Empty(t, states(t, e)[`r/cpu{host="b"}`])
+}
+
+func TestRuleOfNewTenant(t *testing.T) {
+	e, _ := newTestEngine(t)
+
+	data := record(t0, 950)
+	data.Tenant = "t1"
+	e.Evaluate(data)
+	_, err := e.CreateRule(models.Rule{ID: "r", Tenant: "t1", Op: ">", Threshold: 900})
+	require.NoError(t, err)
+	e.Evaluate(data)
+
+	firing, err := e.Alerts("t1", models.AlertFiring, "r")
+	require.NoError(t, err)
+	assert.Len(t, firing, 1)
+
+	// Rules created while records of their tenant are evaluated
+	for i := range 20 {
+		tenant := "c" + strconv.Itoa(i)
+		created := make(chan struct{})
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				data := record(t0, 950)
+				data.Tenant = tenant
+				for {
+					select {
+					case <-created:
+						e.Evaluate(data)
+						return
+					default:
+						e.Evaluate(data)
+					}
+				}
+			}()
+		}
+		_, err := e.CreateRule(models.Rule{ID: "r", Tenant: tenant, Op: ">", Threshold: 900})
+		require.NoError(t, err)
+		close(created)
+		wg.Wait()
+	}
+}
+
+func TestAlertStatePersisted(t *testing.T) {
+	e, repo := newTestEngine(t)
+	_, err := e.CreateRule(models.Rule{ID: "r", Op: ">", Threshold: 900})
+	require.NoError(t, err)
+
+	e.Evaluate(record(t0, 950))
+	firing, err := e.Alerts(models.DefaultTenant, models.AlertFiring, "r")
+	require.NoError(t, err)
+	require.Len(t, firing, 1)
+	assert.Equal(t, models.TimeOf(t0).UTC(), firing[0].StartsAt.UTC())
+	assert.Equal(t, float64(950), firing[0].Value)
+
+	// A restarted engine loads the stored rule and resolves the alert that fired before
+	restarted := New(repo)
+	require.NoError(t, restarted.Load(nil))
+	rule, err := restarted.Rule(models.DefaultTenant, "r")
+	require.NoError(t, err)
+	assert.Equal(t, models.RuleSourceAPI, rule.Source)
+
+	restarted.Evaluate(record(t0+1, 100))
+	resolved, err := restarted.Alerts(models.DefaultTenant, models.AlertResolved, "")
+	require.NoError(t, err)
+	require.Len(t, resolved, 1)
+	assert.Equal(t, models.TimeOf(t0).UTC(), resolved[0].StartsAt.UTC())
+	require.NotNil(t, resolved[0].ResolvedAt)
+	assert.Equal(t, models.TimeOf(t0+1).UTC(), resolved[0].ResolvedAt.UTC())
+}
+
+func TestRuleManagement(t *testing.T) {
+	e, _ := newTestEngine(t, models.Rule{ID: "cfg", Op: ">", Threshold: 1})
+
+	_, err := e.CreateRule(models.Rule{ID: "bad id", Op: ">"})
+	assert.ErrorIs(t, err, ErrInvalidRule)
+	_, err = e.CreateRule(models.Rule{ID: "r", Op: "=="})
+	assert.ErrorIs(t, err, ErrInvalidRule)
+	_, err = e.CreateRule(models.Rule{ID: "r", Op: ">", Aggregation: models.AggMax})
+	assert.ErrorIs(t, err, ErrInvalidRule)
+
+	_, err = e.CreateRule(models.Rule{ID: "cfg", Op: ">"})
+	assert.ErrorIs(t, err, ErrRuleExists)
+	_, err = e.UpdateRule(models.Rule{ID: "cfg", Op: ">"})
+	assert.ErrorIs(t, err, ErrRuleReadOnly)
+	assert.ErrorIs(t, e.DeleteRule(models.DefaultTenant, "cfg"), ErrRuleReadOnly)
+	_, err = e.UpdateRule(models.Rule{ID: "missing", Op: ">"})
+	assert.ErrorIs(t, err, ErrRuleNotFound)
+
+	rule, err := e.CreateRule(models.Rule{ID: "r", Op: ">", Threshold: 900, Window: models.Duration(time.Minute)})
+	require.NoError(t, err)
+	assert.Equal(t, models.AggAvg, rule.Aggregation)
+	assert.Equal(t, []string{"cfg", "r"}, ids(e.Rules(models.DefaultTenant)))
+	assert.Empty(t, e.Rules("other"))
+
+	// Deleting a rule resolves its firing alerts
+	e.Evaluate(record(t0, 950))
+	require.NoError(t, e.DeleteRule(models.DefaultTenant, "r"))
+	assert.Equal(t, models.AlertResolved, states(t, e)[`r/cpu{host="a"}`])
+	_, err = e.Rule(models.DefaultTenant, "r")
+	assert.ErrorIs(t, err, ErrRuleNotFound)
+}
+
+func ids(rules []models.Rule) []string {
+	var ids []string
+	for _, r := range rules {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
@@ -0,0 +1,100 @@
+package alerting
+
+import (
+	"sort"
+	"xis-data-aggregator/internal/models"
+)
+
+// sample is a record value kept in a rule window.
+type sample struct {
+	ts    int64
+	value float64
+	seq   uint64 // Arrival number, identifies the sample in extremes
+}
+
+// window holds the records of a rule window and series in timestamp order with their running sum and, for min
+// and max, a monotonic deque of the candidate extremes, so that adding a record in order and evicting the
+// expired ones take amortized constant time. A late record is inserted in place, at the cost of a copy.
+type window struct {
+	samples  []sample // By timestamp
+	sum      float64  // Sum of the sample values
+	evicted  int      // Samples evicted since the sum was recomputed
+	extremes []sample // Subsequence of samples whose values strictly improve on all later ones, min or max rules only
+	seq      uint64
+}
+
+// add adds the record value to the window, by the rule aggregation.
+func (w *window) add(agg string, ts int64, value float64) {
+	w.seq++
+	s := sample{ts: ts, value: value, seq: w.seq}
+	w.sum += value
+
+	if n := len(w.samples); n == 0 || w.samples[n-1].ts <= ts {
+		w.samples = append(w.samples, s)
+		w.pushExtreme(agg, s)
+		return
+	}
+
+	i := sort.Search(len(w.samples), func(i int) bool { return w.samples[i].ts > ts })
+	w.samples = append(w.samples, sample{})
+	copy(w.samples[i+1:], w.samples[i:])
+	w.samples[i] = s
+
+	if agg == models.AggMin || agg == models.AggMax {
+		w.extremes = w.extremes[:0]
+		for _, s := range w.samples {
+			w.pushExtreme(agg, s)
+		}
+	}
+}
+
+// pushExtreme appends the sample, the latest one, to the extremes, dropping those it improves on.
+func (w *window) pushExtreme(agg string, s sample) {
+	if agg != models.AggMin && agg != models.AggMax {
+		return
+	}
+
+	for n := len(w.extremes); n > 0; n-- {
+		last := w.extremes[n-1].value
+		if agg == models.AggMin && last < s.value || agg == models.AggMax && last > s.value {
+			break
+		}
+		w.extremes = w.extremes[:n-1]
+	}
+	w.extremes = append(w.extremes, s)
+}
+
+// evict removes the samples at or before the cutoff timestamp and the earliest beyond limit samples.
+func (w *window) evict(cutoff int64, limit int) {
+	for len(w.samples) > 0 && (w.samples[0].ts <= cutoff || len(w.samples) > limit) {
+		s := w.samples[0]
+		w.samples = w.samples[1:]
+		w.sum -= s.value
+		w.evicted++
+		if len(w.extremes) > 0 && w.extremes[0].seq == s.seq {
+			w.extremes = w.extremes[1:]
+		}
+	}
+
+	// Subtracting accumulates rounding errors; recompute the sum once per window turnover
+	if w.evicted >= len(w.samples) {
+		w.sum = 0
+		for _, s := range w.samples {
+			w.sum += s.value
+		}
+		w.evicted = 0
+	}
+}
+
+// aggregate returns the aggregation of the samples, of a non-empty window.
+func (w *window) aggregate(agg string) float64 {
+	switch agg {
+	case models.AggMin, models.AggMax:
+		return w.extremes[0].value
+	case models.AggSum:
+		return w.sum
+	case models.AggCount:
+		return float64(len(w.samples))
+	}
+	return w.sum / float64(len(w.samples)) // avg
+}
@@ -0,0 +1,156 @@
+// Package anomaly scores Data records against the recent behavior of their series.
+package anomaly
+
+import (
+	"math"
+	"sync"
+	"time"
+	"xis-data-aggregator/internal/models"
+)
+
+const (
+	// minSamples is the number of records of a series scored 0 before the baseline is trusted.
+	minSamples = 10
+	// maxScore caps the score of a deviation from a constant series (zero standard deviation).
+	maxScore = 1000
+	// epsilon is the standard deviation below which a series is considered constant.
+	epsilon = 1e-12
+	// idleSeriesTTL is how long the baseline of a series without records is kept.
+	idleSeriesTTL = time.Hour
+)
+
+// series is the baseline of one series: the last window values and an exponentially weighted mean and variance.
+type series struct {
+	values []float64 // Ring buffer of the last window values
+	next   int       // Ring buffer position of the next value
+	count  int       // Records seen
+	mean   float64   // EWMA of the values
+	vari   float64   // EWMA of the squared deviations
+
+	lastSeen time.Time // Last record arrival
+}
+
+// Detector scores every record of a series by two online estimators and flags it if either deviates:
+//   - the z-score against the mean and standard deviation of the previous window records;
+//   - the z-score against the exponentially weighted moving mean and variance (EWMA, smoothing factor alpha),
+//     which adapts to level shifts faster and remembers longer than the window.
+//
+// The score is the larger absolute z-score; records scoring at least the threshold are anomalous.
+// Baselines are kept in memory per tenant series and start over after a restart or idleSeriesTTL without records.
+type Detector struct {
+	mu        sync.Mutex
+	window    int
+	alpha     float64
+	threshold float64
+	series    map[string]*series // By tenant and series key
+	lastSweep time.Time
+	now       func() time.Time // Clock, replaceable in tests
+}
+
+// New creates a detector with a rolling window of window records, an EWMA smoothing factor alpha in (0, 1]
+// and the score threshold of anomalous records.
+func New(window int, alpha, threshold float64) *Detector {
+	if alpha <= 0 || alpha > 1 {
+		alpha = 0.1
+	}
+	return &Detector{
+		window:    max(window, 2),
+		alpha:     alpha,
+		threshold: threshold,
+		series:    make(map[string]*series),
+		now:       time.Now,
+	}
+}
+
+// Score sets the AnomalyScore and Anomalous fields of the record and adds it to the baseline of its series.
+// Safe to call on a nil detector.
+func (d *Detector) Score(data *models.Data) {
+	if d == nil || data == nil {
+		return
+	}
+
+	key := data.Tenant + "\x00" + data.SeriesKey()
+	x := data.Max.Float64()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	d.sweep(now)
+
+	s := d.series[key]
+	if s == nil {
+		s = &series{values: make([]float64, 0, d.window)}
+		d.series[key] = s
+	}
+	s.lastSeen = now
+
+	data.AnomalyScore = 0
+	if s.count >= minSamples {
+		mean, stddev := meanStddev(s.values)
+		data.AnomalyScore = max(zScore(x, mean, stddev), zScore(x, s.mean, math.Sqrt(s.vari)))
+	}
+	data.Anomalous = data.AnomalyScore >= d.threshold && data.AnomalyScore > 0
+
+	s.add(x, d.window, d.alpha)
+}
+
+// sweep evicts the baselines idle for idleSeriesTTL, at most once per idleSeriesTTL. Must be called with mu held.
+func (d *Detector) sweep(now time.Time) {
+	if now.Sub(d.lastSweep) < idleSeriesTTL {
+		return
+	}
+	d.lastSweep = now
+
+	for key, s := range d.series {
+		if now.Sub(s.lastSeen) > idleSeriesTTL {
+			delete(d.series, key)
+		}
+	}
+}
+
+// add adds the value to the window and the EWMA.
+func (s *series) add(x float64, window int, alpha float64) {
+	if len(s.values) < window {
+		s.values = append(s.values, x)
+	} else {
+		s.values[s.next] = x
+	}
+	s.next = (s.next + 1) % window
+
+	if s.count == 0 {
+		s.mean = x
+	} else {
+		diff := x - s.mean
+		s.mean += alpha * diff
+		s.vari = (1 - alpha) * (s.vari + alpha*diff*diff)
+	}
+	s.count++
+}
+
+// meanStddev returns the mean and the sample standard deviation of the values.
+func meanStddev(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)-1))
+}
+
+// zScore returns the absolute deviation of x from the mean in standard deviations, capped at maxScore.
+func zScore(x, mean, stddev float64) float64 {
+	diff := math.Abs(x - mean)
+	if stddev < epsilon {
+		if diff < epsilon {
+			return 0
+		}
+		return maxScore
+	}
+	return min(diff/stddev, maxScore)
+}
@@ -0,0 +1,88 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func record(tenant, series string, v int64) *models.Data {
+	return &models.Data{Tenant: tenant, Series: series, Max: models.IntValue(v)}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    []int64
+		anomalous bool
+		score     float64 // Minimum score of the last value
+	}{
+		{"warm-up", []int64{10, 10, 10, 500}, false, 0},
+		{"within noise", []int64{10, 12, 9, 11, 10, 12, 9, 11, 10, 12, 11}, false, 0},
+		{"spike", []int64{10, 12, 9, 11, 10, 12, 9, 11, 10, 12, 60}, true, 3},
+		{"drop", []int64{10, 12, 9, 11, 10, 12, 9, 11, 10, 12, -40}, true, 3},
+		{"constant then jump", []int64{5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 6}, true, maxScore},
+		{"constant", []int64{5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5}, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(60, 0.1, 3)
+
+			var last *models.Data
+			for _, v := range tt.values {
+				last = record("", "cpu", v)
+				d.Score(last)
+			}
+
+			assert.Equal(t, tt.anomalous, last.Anomalous, "score %v", last.AnomalyScore)
+			assert.GreaterOrEqual(t, last.AnomalyScore, tt.score)
+		})
+	}
+}
+
+func TestSeriesIsolation(t *testing.T) {
+	d := New(60, 0.1, 3)
+	for i := 0; i < minSamples; i++ {
+		d.Score(record("", "cpu", 5))
+	}
+
+	// Other series and tenants have no baseline yet
+	for _, data := range []*models.Data{record("", "mem", 500), record("t1", "cpu", 500)} {
+		d.Score(data)
+		assert.False(t, data.Anomalous)
+		assert.Zero(t, data.AnomalyScore)
+	}
+
+	data := record("", "cpu", 500)
+	d.Score(data)
+	assert.True(t, data.Anomalous)
+}
+
+func TestSweep(t *testing.T) {
+	d := New(60, 0.1, 3)
+	now := time.Now()
+	d.now = func() time.Time { return now }
+
+	for range minSamples {
+		d.Score(record("", "cpu", 10))
+		d.Score(record("", "mem", 10))
+	}
+	now = now.Add(idleSeriesTTL + time.Second)
+
+	// The idle baseline is evicted and starts over
+	d.Score(record("", "cpu", 10))
+	assert.Len(t, d.series, 1)
+	last := record("", "mem", 500)
+	d.Score(last)
+	assert.False(t, last.Anomalous)
+}
+
+func TestNilDetector(t *testing.T) {
+	var d *Detector
+	data := record("", "cpu", 500)
+	d.Score(data)
+	assert.False(t, data.Anomalous)
+}
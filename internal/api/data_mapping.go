@@ -3,9 +3,12 @@ package api
 
 import (
 	"fmt"
+	"math"
 	"xis-data-aggregator/internal/models"
 	"xis-data-aggregator/pb"
 
+	"google.golang.org/protobuf/types/known/timestamppb"
+
 	"github.com/google/uuid"
 )
 
@@ -21,12 +24,39 @@ func DataToProto(data *models.Data) (*pb.Data, error) {
 	pbData := pb.Data{
 		Id:        data.ID.String(), // Convert UUID to string for protobuf
 		Timestamp: data.Timestamp,
-		Max:       int32(data.Max),
+		Max:       legacyMax(data.Max),
+		Tenant:    data.Tenant,
+		Series:    data.Series,
+		Labels:    data.Labels,
+		Time:      timestamppb.New(models.TimeOf(data.Timestamp)),
+
+		AnomalyScore: data.AnomalyScore,
+		Anomalous:    data.Anomalous,
+	}
+
+	if data.Max.IsFloat() {
+		pbData.MaxValue = &pb.Data_MaxFloat64{MaxFloat64: data.Max.Float}
+	} else {
+		pbData.MaxValue = &pb.Data_MaxInt64{MaxInt64: data.Max.Int}
 	}
 
 	return &pbData, nil
 }
 
+// legacyMax returns the value for the legacy int32 `max` field: saturated to the int32 range, floats truncated.
+func legacyMax(v models.Value) int32 {
+	f := v.Float64()
+	switch {
+	case f >= math.MaxInt32:
+		return math.MaxInt32
+	case f <= math.MinInt32:
+		return math.MinInt32
+	case v.IsFloat():
+		return int32(f)
+	}
+	return int32(v.Int)
+}
+
 // ProtoToData converts a protobuf pb.Data struct to the internal models.Data struct.
 // Returns an error if the input pb.Data is nil or if the ID cannot be parsed as a UUID.
 func ProtoToData(pbData *pb.Data) (*models.Data, error) {
@@ -37,7 +67,20 @@ func ProtoToData(pbData *pb.Data) (*models.Data, error) {
 
 	data := models.Data{
 		Timestamp: pbData.Timestamp,
-		Max:       int(pbData.Max),
+		Max:       models.IntValue(int64(pbData.Max)), // records written before max_value existed
+		Tenant:    pbData.Tenant,
+		Series:    pbData.Series,
+		Labels:    pbData.Labels,
+
+		AnomalyScore: pbData.AnomalyScore,
+		Anomalous:    pbData.Anomalous,
+	}
+
+	switch v := pbData.MaxValue.(type) {
+	case *pb.Data_MaxInt64:
+		data.Max = models.IntValue(v.MaxInt64)
+	case *pb.Data_MaxFloat64:
+		data.Max = models.FloatValue(v.MaxFloat64)
 	}
 
 	// Parse the string ID from protobuf into a UUID
@@ -45,3 +88,215 @@ func ProtoToData(pbData *pb.Data) (*models.Data, error) {
 
 	return &data, err
 }
+
+// windowKinds maps the window kinds to their protobuf enum values.
+var windowKinds = map[models.WindowKind]pb.WindowKind{
+	"":                   pb.WindowKind_WINDOW_KIND_UNSPECIFIED,
+	models.WindowSliding: pb.WindowKind_WINDOW_KIND_SLIDING,
+	models.WindowSession: pb.WindowKind_WINDOW_KIND_SESSION,
+}
+
+// ProtoToWindowKind converts a protobuf window kind; unspecified is returned as the empty kind (any).
+// Returns an error for unknown values.
+func ProtoToWindowKind(kind pb.WindowKind) (models.WindowKind, error) {
+	for k, v := range windowKinds {
+		if v == kind {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("invalid window kind %d", kind)
+}
+
+// WindowToProto converts a models.WindowResult to its protobuf representation with timestamps in stored units.
+// Returns an error if the input is nil.
+func WindowToProto(w *models.WindowResult) (*pb.WindowResult, error) {
+	if w == nil {
+		return nil, fmt.Errorf("window is nil")
+	}
+
+	pbWindow := pb.WindowResult{
+		Id:        w.ID.String(),
+		Kind:      windowKinds[w.Kind],
+		Start:     w.Start,
+		End:       w.End,
+		Tenant:    w.Tenant,
+		Series:    w.Series,
+		Labels:    w.Labels,
+		Count:     w.Count,
+		Sum:       w.Sum,
+		Avg:       w.Avg,
+		Revision:  uint32(w.Revision),
+		StartTime: timestamppb.New(models.TimeOf(w.Start)),
+		EndTime:   timestamppb.New(models.TimeOf(w.End)),
+	}
+
+	if w.Min.IsFloat() {
+		pbWindow.MinValue = &pb.WindowResult_MinFloat64{MinFloat64: w.Min.Float}
+	} else {
+		pbWindow.MinValue = &pb.WindowResult_MinInt64{MinInt64: w.Min.Int}
+	}
+	if w.Max.IsFloat() {
+		pbWindow.MaxValue = &pb.WindowResult_MaxFloat64{MaxFloat64: w.Max.Float}
+	} else {
+		pbWindow.MaxValue = &pb.WindowResult_MaxInt64{MaxInt64: w.Max.Int}
+	}
+
+	return &pbWindow, nil
+}
+
+// ProtoToWindow converts a protobuf pb.WindowResult to the internal models.WindowResult struct.
+// The window bounds are taken from `start_time` and `end_time` if set, otherwise from the integer bounds as they are.
+// Returns an error if the input is nil, the kind is unknown or the ID cannot be parsed as a UUID.
+func ProtoToWindow(pbWindow *pb.WindowResult) (*models.WindowResult, error) {
+	if pbWindow == nil {
+		return nil, fmt.Errorf("pb.WindowResult is nil")
+	}
+
+	kind, err := ProtoToWindowKind(pbWindow.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	w := models.WindowResult{
+		Kind:     kind,
+		Start:    pbWindow.Start,
+		End:      pbWindow.End,
+		Tenant:   pbWindow.Tenant,
+		Series:   pbWindow.Series,
+		Labels:   pbWindow.Labels,
+		Count:    pbWindow.Count,
+		Sum:      pbWindow.Sum,
+		Avg:      pbWindow.Avg,
+		Revision: int(pbWindow.Revision),
+	}
+	if pbWindow.StartTime != nil {
+		w.Start = models.Timestamp(pbWindow.StartTime.AsTime())
+	}
+	if pbWindow.EndTime != nil {
+		w.End = models.Timestamp(pbWindow.EndTime.AsTime())
+	}
+
+	switch v := pbWindow.MinValue.(type) {
+	case *pb.WindowResult_MinInt64:
+		w.Min = models.IntValue(v.MinInt64)
+	case *pb.WindowResult_MinFloat64:
+		w.Min = models.FloatValue(v.MinFloat64)
+	}
+	switch v := pbWindow.MaxValue.(type) {
+	case *pb.WindowResult_MaxInt64:
+		w.Max = models.IntValue(v.MaxInt64)
+	case *pb.WindowResult_MaxFloat64:
+		w.Max = models.FloatValue(v.MaxFloat64)
+	}
+
+	w.ID, err = uuid.Parse(pbWindow.Id)
+	return &w, err
+}
+
+// PackToProto converts a models.Pack to its protobuf representation. The stored timestamp is sent as `time`,
+// so that it does not depend on the server precision; float64 packs are sent as `float_data`.
+// Returns an error if the input pack is nil.
+func PackToProto(pack *models.Pack) (*pb.Pack, error) {
+	if pack == nil {
+		return nil, fmt.Errorf("pack is nil")
+	}
+
+	pbPack := pb.Pack{
+		Series: pack.Series,
+		Labels: pack.Labels,
+		Time:   timestamppb.New(models.TimeOf(pack.Timestamp)),
+	}
+	if pack.ID != uuid.Nil {
+		pbPack.Id = pack.ID.String()
+	}
+
+	float := pack.ValueType == models.ValueTypeFloat64
+	for _, v := range pack.Data {
+		float = float || v.IsFloat()
+	}
+
+	switch {
+	case float:
+		pbPack.ValueType = pb.ValueType_VALUE_TYPE_FLOAT64
+		pbPack.FloatData = make([]float64, len(pack.Data))
+		for i, v := range pack.Data {
+			pbPack.FloatData[i] = v.Float64()
+		}
+	default:
+		if pack.ValueType == models.ValueTypeInt64 {
+			pbPack.ValueType = pb.ValueType_VALUE_TYPE_INT64
+		}
+		pbPack.Data = make([]int64, len(pack.Data))
+		for i, v := range pack.Data {
+			pbPack.Data[i] = v.Int
+		}
+	}
+
+	return &pbPack, nil
+}
+
+// ProtoToPack converts a protobuf pb.Pack to the internal models.Pack struct.
+// The timestamp is normalized to stored units: `time` if set, otherwise the integer timestamp in the given precision.
+// An empty ID is left as uuid.Nil so that the service assigns a new one.
+// Returns an error if the input pb.Pack is nil or if the ID cannot be parsed as a UUID.
+func ProtoToPack(pbPack *pb.Pack, precision models.TimestampPrecision) (*models.Pack, error) {
+	if pbPack == nil {
+		return nil, fmt.Errorf("pb.Pack is nil")
+	}
+
+	pack := models.Pack{
+		Data:   models.IntValues(pbPack.Data),
+		Series: pbPack.Series,
+		Labels: pbPack.Labels,
+	}
+
+	if pbPack.Time != nil {
+		pack.Timestamp = models.Timestamp(pbPack.Time.AsTime())
+	} else {
+		ts, err := precision.ToStored(pbPack.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		pack.Timestamp = ts
+	}
+
+	switch {
+	case pbPack.ValueType == pb.ValueType_VALUE_TYPE_FLOAT64 || len(pbPack.FloatData) > 0:
+		if len(pbPack.Data) > 0 {
+			return nil, fmt.Errorf("pack has both data and float_data")
+		}
+		pack.ValueType = models.ValueTypeFloat64
+		pack.Data = models.FloatValues(pbPack.FloatData)
+	case pbPack.ValueType == pb.ValueType_VALUE_TYPE_INT64:
+		pack.ValueType = models.ValueTypeInt64
+	}
+
+	if pbPack.Id != "" {
+		id, err := uuid.Parse(pbPack.Id)
+		if err != nil {
+			return nil, err
+		}
+		pack.ID = id
+	}
+
+	return &pack, nil
+}
+
+// ProtoToFilter converts the series and label matchers of a protobuf request to a models.Filter.
+// Returns an error if a matcher is invalid.
+func ProtoToFilter(series string, pbMatchers []*pb.LabelMatcher) (*models.Filter, error) {
+	filter := models.Filter{Series: series}
+
+	for _, pbMatcher := range pbMatchers {
+		if pbMatcher == nil {
+			continue
+		}
+		m, err := models.NewLabelMatcher(pbMatcher.Name, models.MatchType(pbMatcher.Type), pbMatcher.Value)
+		if err != nil {
+			return nil, err
+		}
+		filter.Matchers = append(filter.Matchers, m)
+	}
+
+	return &filter, nil
+}
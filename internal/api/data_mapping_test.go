@@ -28,7 +28,7 @@ func TestDataToProto(t *testing.T) {
 			input: &models.Data{
 				ID:        id1,
 				Timestamp: 1678886400,
-				Max:       100,
+				Max:       models.IntValue(100),
 			},
 			want: &pb.Data{
 				Id:        id1.String(),
@@ -37,6 +37,36 @@ func TestDataToProto(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Int64 value above int32 range saturates legacy max",
+			input: &models.Data{
+				ID:        id1,
+				Timestamp: 1678886400,
+				Max:       models.IntValue(1 << 40),
+			},
+			want: &pb.Data{
+				Id:        id1.String(),
+				Timestamp: 1678886400,
+				Max:       2147483647,
+				MaxValue:  &pb.Data_MaxInt64{MaxInt64: 1 << 40},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Float value",
+			input: &models.Data{
+				ID:        id1,
+				Timestamp: 1678886400,
+				Max:       models.FloatValue(-12.75),
+			},
+			want: &pb.Data{
+				Id:        id1.String(),
+				Timestamp: 1678886400,
+				Max:       -12,
+				MaxValue:  &pb.Data_MaxFloat64{MaxFloat64: -12.75},
+			},
+			wantErr: false,
+		},
 		{
 			name:    "Nil input data",
 			input:   nil,
@@ -60,6 +90,9 @@ func TestDataToProto(t *testing.T) {
 				assert.Equal(t, tt.want.Id, got.Id, "ID mismatch")
 				assert.Equal(t, tt.want.Timestamp, got.Timestamp, "Timestamp mismatch")
 				assert.Equal(t, tt.want.Max, got.Max, "Max mismatch")
+				if tt.want.MaxValue != nil {
+					assert.Equal(t, tt.want.MaxValue, got.MaxValue, "MaxValue mismatch")
+				}
 			}
 		})
 	}
@@ -89,7 +122,7 @@ func TestProtoToData(t *testing.T) {
 			want: &models.Data{
 				ID:        validUUID1,
 				Timestamp: 1678886400,
-				Max:       100,
+				Max:       models.IntValue(100),
 			},
 			wantErr: false,
 		},
@@ -103,7 +136,55 @@ func TestProtoToData(t *testing.T) {
 			want: &models.Data{
 				ID:        validUUID2,
 				Timestamp: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC).UnixMilli(),
-				Max:       -5,
+				Max:       models.IntValue(-5),
+			},
+			wantErr: false,
+		},
+		{
+			name: "Float max value takes precedence over legacy max",
+			input: &pb.Data{
+				Id:        validUUID1.String(),
+				Timestamp: 1678886400,
+				Max:       3,
+				MaxValue:  &pb.Data_MaxFloat64{MaxFloat64: 3.5},
+			},
+			want: &models.Data{
+				ID:        validUUID1,
+				Timestamp: 1678886400,
+				Max:       models.FloatValue(3.5),
+			},
+			wantErr: false,
+		},
+		{
+			name: "Int64 max value above int32 range",
+			input: &pb.Data{
+				Id:        validUUID2.String(),
+				Timestamp: 1678886400,
+				Max:       2147483647,
+				MaxValue:  &pb.Data_MaxInt64{MaxInt64: 1 << 40},
+			},
+			want: &models.Data{
+				ID:        validUUID2,
+				Timestamp: 1678886400,
+				Max:       models.IntValue(1 << 40),
+			},
+			wantErr: false,
+		},
+		{
+			name: "Anomaly score and flag",
+			input: &pb.Data{
+				Id:           validUUID1.String(),
+				Timestamp:    1678886400,
+				MaxValue:     &pb.Data_MaxInt64{MaxInt64: 950},
+				AnomalyScore: 4.5,
+				Anomalous:    true,
+			},
+			want: &models.Data{
+				ID:           validUUID1,
+				Timestamp:    1678886400,
+				Max:          models.IntValue(950),
+				AnomalyScore: 4.5,
+				Anomalous:    true,
 			},
 			wantErr: false,
 		},
@@ -124,7 +205,7 @@ func TestProtoToData(t *testing.T) {
 			want: &models.Data{
 				ID:        validUUID1,
 				Timestamp: 1678886400,
-				Max:       -2147483648,
+				Max:       models.IntValue(-2147483648),
 			},
 			wantErr: false,
 		},
@@ -138,7 +219,7 @@ func TestProtoToData(t *testing.T) {
 			want: &models.Data{
 				ID:        validUUID2,
 				Timestamp: 1678886400,
-				Max:       2147483647,
+				Max:       models.IntValue(2147483647),
 			},
 			wantErr: false,
 		},
@@ -165,7 +246,58 @@ func TestProtoToData(t *testing.T) {
 				assert.Equal(t, tt.want.ID, got.ID, "ID mismatch for test case: %s", tt.name)
 				assert.Equal(t, tt.want.Timestamp, got.Timestamp, "Timestamp mismatch for test case: %s", tt.name)
 				assert.Equal(t, tt.want.Max, got.Max, "Max mismatch for test case: %s", tt.name)
+				assert.Equal(t, tt.want.AnomalyScore, got.AnomalyScore, "AnomalyScore mismatch for test case: %s", tt.name)
+				assert.Equal(t, tt.want.Anomalous, got.Anomalous, "Anomalous mismatch for test case: %s", tt.name)
 			}
 		})
 	}
 }
+
+// TestPackToProto tests that packs sent by clients are read back by ProtoToPack in any server precision.
+func TestPackToProto(t *testing.T) {
+	tests := []struct {
+		name string
+		pack *models.Pack
+	}{
+		{
+			name: "Int64 pack",
+			pack: &models.Pack{ID: uuid.New(), Timestamp: 1678886400123456, Data: models.IntValues([]int64{1, -2}), Series: "cpu", Labels: map[string]string{"host": "a"}},
+		},
+		{
+			name: "Declared int64 pack without ID",
+			pack: &models.Pack{Timestamp: 1, Data: models.IntValues([]int64{3}), ValueType: models.ValueTypeInt64},
+		},
+		{
+			name: "Float64 pack",
+			pack: &models.Pack{Timestamp: -5, Data: models.FloatValues([]float64{1.5, 2}), ValueType: models.ValueTypeFloat64},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pbPack, err := PackToProto(tt.pack)
+			assert.NoError(t, err)
+
+			got, err := ProtoToPack(pbPack, models.PrecisionSeconds)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.pack, got)
+		})
+	}
+}
+
+// TestProtoToWindow tests that ProtoToWindow reverses WindowToProto.
+func TestProtoToWindow(t *testing.T) {
+	w := &models.WindowResult{
+		ID: uuid.New(), Kind: models.WindowSession, Start: 1678886400000000, End: 1678886460000000,
+		Series: "temp", Labels: map[string]string{"dc": "b"}, Count: 3,
+		Min: models.FloatValue(-1.5), Max: models.FloatValue(4), Sum: 5, Avg: 5.0 / 3, Revision: 2,
+	}
+
+	pbWindow, err := WindowToProto(w)
+	assert.NoError(t, err)
+	pbWindow.Start, pbWindow.End = 1678886400, 1678886460 // Bounds in the server precision, seconds
+
+	got, err := ProtoToWindow(pbWindow)
+	assert.NoError(t, err)
+	assert.Equal(t, w, got)
+}
@@ -0,0 +1,126 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"xis-data-aggregator/internal/auth"
+	"xis-data-aggregator/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// methodScopes maps full gRPC method names to the scope they require.
+// Methods missing from the map are denied when authentication is enabled.
+var methodScopes = map[string]auth.Scope{
+	pb.DataService_GetDataById_FullMethodName:           auth.ScopeRead,
+	pb.DataService_ListDataByTimeRange_FullMethodName:   auth.ScopeRead,
+	pb.DataService_ListDataByTimeRangeV2_FullMethodName: auth.ScopeRead,
+	pb.DataService_IngestPacks_FullMethodName:           auth.ScopeIngest,
+	pb.DataService_GetData_FullMethodName:               auth.ScopeRead,
+	pb.DataService_BatchGetData_FullMethodName:          auth.ScopeRead,
+	pb.DataService_ListData_FullMethodName:              auth.ScopeRead,
+	pb.DataService_DeleteData_FullMethodName:            auth.ScopeDelete,
+	pb.DataService_DeleteDataByTimeRange_FullMethodName: auth.ScopeAdmin,
+	pb.DataService_SubscribeData_FullMethodName:         auth.ScopeRead,
+	pb.DataService_ListAnomalies_FullMethodName:         auth.ScopeRead,
+	pb.DataService_ListWindows_FullMethodName:           auth.ScopeRead,
+	pb.DataService_DownsampleData_FullMethodName:        auth.ScopeRead,
+}
+
+// publicMethods are served without credentials.
+var publicMethods = map[string]bool{
+	healthpb.Health_Check_FullMethodName: true,
+	healthpb.Health_Watch_FullMethodName: true,
+	healthpb.Health_List_FullMethodName:  true,
+}
+
+// principalCtxKey is the context key holding the authenticated *auth.Principal.
+type principalCtxKey struct{}
+
+// PrincipalFromContext returns the principal attached by the auth interceptors, if any.
+func PrincipalFromContext(ctx context.Context) (*auth.Principal, bool) {
+	p, ok := ctx.Value(principalCtxKey{}).(*auth.Principal)
+	return p, ok
+}
+
+// UnaryAuthInterceptor returns a unary server interceptor enforcing per-method scopes.
+// A nil authenticator disables the check; publicMethods are never checked.
+func UnaryAuthInterceptor(a *auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if a == nil || publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		principal, err := authorize(ctx, a, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(context.WithValue(ctx, principalCtxKey{}, principal), req)
+	}
+}
+
+// StreamAuthInterceptor returns a stream server interceptor enforcing per-method scopes.
+// A nil authenticator disables the check; publicMethods are never checked.
+func StreamAuthInterceptor(a *auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if a == nil || publicMethods[info.FullMethod] {
+			return handler(srv, ss)
+		}
+
+		principal, err := authorize(ss.Context(), a, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &principalStream{
+			ServerStream: ss,
+			ctx:          context.WithValue(ss.Context(), principalCtxKey{}, principal),
+		})
+	}
+}
+
+// authorize reads the credential from the "authorization" (Bearer) or "x-api-key" metadata
+// and checks it against the scope required by the method.
+// Returns Unauthenticated or PermissionDenied status errors.
+func authorize(ctx context.Context, a *auth.Authenticator, fullMethod string) (*auth.Principal, error) {
+	var credential string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("authorization"); len(v) > 0 {
+			credential = auth.BearerToken(v[0])
+		}
+		if v := md.Get("x-api-key"); credential == "" && len(v) > 0 {
+			credential = v[0]
+		}
+	}
+
+	scope, ok := methodScopes[fullMethod]
+	if !ok {
+		return nil, status.Errorf(codes.PermissionDenied, "method %s is not allowed", fullMethod)
+	}
+
+	principal, err := a.Authorize(credential, scope)
+	switch {
+	case errors.Is(err, auth.ErrPermissionDenied):
+		return nil, status.Errorf(codes.PermissionDenied, "scope %q required", scope)
+	case err != nil:
+		return nil, status.Error(codes.Unauthenticated, "invalid or missing credentials")
+	}
+
+	return principal, nil
+}
+
+// principalStream wraps a grpc.ServerStream to carry a context with the authenticated principal.
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the wrapped context.
+func (s *principalStream) Context() context.Context {
+	return s.ctx
+}
@@ -2,12 +2,18 @@
 package grpc
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io"
-	"strconv"
+	"math"
 	"xis-data-aggregator/internal/repository"
 
 	"xis-data-aggregator/internal/api"
+	"xis-data-aggregator/internal/auth"
+	"xis-data-aggregator/internal/downsample"
+	"xis-data-aggregator/internal/hub"
+	"xis-data-aggregator/internal/models"
 	"xis-data-aggregator/internal/service"
 	"xis-data-aggregator/pb"
 
@@ -21,27 +27,410 @@ import (
 
 // DataServiceServer implements the gRPC DataService interface.
 type DataServiceServer struct {
-	pb.UnimplementedDataServiceServer                      // Embeds unimplemented server for forward compatibility
-	service                           *service.DataService // Business logic service
+	pb.UnimplementedDataServiceServer                           // Embeds unimplemented server for forward compatibility
+	service                           *service.DataService      // Business logic service
+	precision                         models.TimestampPrecision // Unit of integer timestamps exchanged with clients
 }
 
 // NewDataServiceServer creates a new gRPC DataServiceServer instance.
-// Takes a pointer to the business logic service.
-func NewDataServiceServer(service *service.DataService) *DataServiceServer {
+// Takes a pointer to the business logic service and the declared precision of integer timestamps.
+func NewDataServiceServer(service *service.DataService, precision models.TimestampPrecision) *DataServiceServer {
 	return &DataServiceServer{
-		service: service,
+		service:   service,
+		precision: precision,
 	}
 }
 
 // RegisterDataServiceServer registers the DataServiceServer with the given gRPC server.
-func RegisterDataServiceServer(s *grpc.Server, service *service.DataService) {
-	server := NewDataServiceServer(service)
+func RegisterDataServiceServer(s *grpc.Server, service *service.DataService, precision models.TimestampPrecision) {
+	server := NewDataServiceServer(service, precision)
 	pb.RegisterDataServiceServer(s, server)
 }
 
+// tenantService returns the service scoped to the tenant of the caller authenticated by the interceptors.
+func (s *DataServiceServer) tenantService(ctx context.Context) *service.DataService {
+	principal, _ := PrincipalFromContext(ctx)
+	return s.service.ForTenant(auth.TenantOf(principal))
+}
+
+// storedRange converts the integer bounds of a time range in the declared precision to stored units.
+// Returns an InvalidArgument error if a bound overflows.
+func (s *DataServiceServer) storedRange(from, to int64) (int64, int64, error) {
+	storedFrom, err := s.precision.ToStored(from)
+	if err != nil {
+		return 0, 0, status.Errorf(codes.InvalidArgument, "invalid 'from': %v", err)
+	}
+	storedTo, err := s.precision.ToStored(to)
+	if err != nil {
+		return 0, 0, status.Errorf(codes.InvalidArgument, "invalid 'to': %v", err)
+	}
+	return storedFrom, storedTo, nil
+}
+
+// toProto converts stored data to a response message with the integer timestamp in the declared precision.
+func (s *DataServiceServer) toProto(data *models.Data) (*pb.Data, error) {
+	protoData, err := api.DataToProto(data)
+	if err != nil {
+		return nil, err
+	}
+	protoData.Timestamp = s.precision.FromStored(data.Timestamp)
+	return protoData, nil
+}
+
+// recvError passes through gRPC status errors (e.g. cancellation or rate limiting) and wraps any other
+// receive error as Internal.
+func recvError(err error, msg string) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Errorf(codes.Internal, "%s: %v", msg, err)
+}
+
+// itemStatus converts a gRPC status error of a single stream or batch item to an item status and message.
+func itemStatus(err error) (pb.ItemStatus, string) {
+	st, _ := status.FromError(err)
+	switch st.Code() {
+	case codes.OK:
+		return pb.ItemStatus_ITEM_STATUS_OK, ""
+	case codes.NotFound:
+		return pb.ItemStatus_ITEM_STATUS_NOT_FOUND, st.Message()
+	case codes.InvalidArgument:
+		return pb.ItemStatus_ITEM_STATUS_INVALID, st.Message()
+	default:
+		return pb.ItemStatus_ITEM_STATUS_ERROR, st.Message()
+	}
+}
+
+// getData fetches a single data item by its string ID.
+// Returns a gRPC status error if the ID is invalid or if data is not found.
+func (s *DataServiceServer) getData(ctx context.Context, idStr string) (*pb.Data, error) {
+	if idStr == "" {
+		glog.Errorln("Invalid request: ID is empty")
+		return nil, status.Errorf(codes.InvalidArgument, "ID cannot be empty")
+	}
+
+	// Parse UUID from request
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		glog.Errorf("Invalid UUID format: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "invalid UUID format: %v", err)
+	}
+
+	// Get data from service layer
+	data, err := s.tenantService(ctx).GetByID(id)
+
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		glog.Infof("Data not found for ID: %s", idStr)
+		return nil, status.Errorf(codes.NotFound, "data not found for ID: %s", idStr)
+	case errors.Is(err, service.ErrNotFound):
+		glog.Infof("Data not found for ID: %s", idStr)
+		return nil, status.Errorf(codes.NotFound, "data not found for ID: %s", idStr)
+	case err != nil:
+		glog.Errorf("Service error: %v", err)
+		return nil, status.Errorf(codes.Internal, "internal server error: %v", err)
+	}
+
+	// Convert to proto format for response
+	protoData, err := s.toProto(data)
+	if err != nil {
+		glog.Errorf("Error converting data to proto: %v", err)
+		return nil, status.Errorf(codes.Internal, "failed to convert data: %v", err)
+	}
+
+	return protoData, nil
+}
+
+// getDataItem fetches a single data item for a stream or batch, reporting failures as the item status.
+func (s *DataServiceServer) getDataItem(ctx context.Context, idStr string) *pb.Data {
+	protoData, err := s.getData(ctx, idStr)
+	if err != nil {
+		itemStatus, msg := itemStatus(err)
+		return &pb.Data{Id: idStr, Status: itemStatus, Error: msg}
+	}
+	return protoData
+}
+
+// GetData handles unary requests for getting data by ID.
+// Returns a gRPC error if the ID is invalid or if data is not found.
+func (s *DataServiceServer) GetData(ctx context.Context, req *pb.GetDataByIDRequest) (*pb.Data, error) {
+	return s.getData(ctx, req.GetId())
+}
+
+// BatchGetData handles unary requests for getting several data items by ID with a single lookup.
+// Responds with one item per requested ID and the list of IDs without a record; invalid IDs are
+// reported in the item status. Returns a gRPC error if there are too many IDs.
+func (s *DataServiceServer) BatchGetData(ctx context.Context, req *pb.BatchGetDataRequest) (*pb.BatchGetDataResponse, error) {
+	items := make([]*pb.Data, len(req.GetIds()))
+	ids := make([]uuid.UUID, 0, len(req.GetIds()))
+
+	for i, idStr := range req.GetIds() {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			items[i] = &pb.Data{Id: idStr, Status: pb.ItemStatus_ITEM_STATUS_INVALID, Error: fmt.Sprintf("invalid UUID format: %v", err)}
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	dataList, missing, err := s.tenantService(ctx).GetByIDs(ids)
+	switch {
+	case errors.Is(err, service.ErrBatchTooLarge):
+		glog.Infof("Batch too large: %d IDs", len(ids))
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	case err != nil:
+		glog.Errorf("Service error: %v", err)
+		return nil, status.Errorf(codes.Internal, "internal server error: %v", err)
+	}
+
+	found := make(map[string]*pb.Data, len(dataList))
+	for i := range dataList {
+		protoData, err := s.toProto(&dataList[i])
+		if err != nil {
+			glog.Errorf("Error converting data to proto: %v", err)
+			return nil, status.Errorf(codes.Internal, "failed to convert data: %v", err)
+		}
+		found[protoData.Id] = protoData
+	}
+
+	response := pb.BatchGetDataResponse{Items: items, MissingIds: make([]string, len(missing))}
+	for i, id := range missing {
+		response.MissingIds[i] = id.String()
+	}
+
+	// Fill the valid IDs in request order
+	for i, idStr := range req.GetIds() {
+		if items[i] != nil {
+			continue
+		}
+		id := uuid.MustParse(idStr)
+		if protoData, ok := found[id.String()]; ok {
+			items[i] = protoData
+		} else {
+			items[i] = &pb.Data{Id: idStr, Status: pb.ItemStatus_ITEM_STATUS_NOT_FOUND, Error: "data not found for ID: " + idStr}
+		}
+	}
+
+	glog.Infof("Successfully sent %d of %d data items", len(dataList), len(req.GetIds()))
+
+	return &response, nil
+}
+
+// ListData handles unary requests for listing data by a typed time range.
+// Returns a gRPC error if the request is invalid or if no data is found.
+func (s *DataServiceServer) ListData(ctx context.Context, req *pb.ListDataByTimeRangeRequestV2) (*pb.ListDataByTimeRangeResponse, error) {
+	query, err := api.ProtoToListQuery(req, s.precision)
+	if err != nil {
+		glog.Errorf("Invalid request: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	return s.listByTimeRange(ctx, query)
+}
+
+// ListAnomalies handles unary requests for the records flagged by the anomaly detector within a time range.
+// Responds with an empty list if there are none.
+func (s *DataServiceServer) ListAnomalies(ctx context.Context, req *pb.ListDataByTimeRangeRequestV2) (*pb.ListDataByTimeRangeResponse, error) {
+	query, err := api.ProtoToListQuery(req, s.precision)
+	if err != nil {
+		glog.Errorf("Invalid request: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	if query.Filter == nil {
+		query.Filter = &models.Filter{}
+	}
+	query.Filter.Anomalous = true
+
+	resp, err := s.listByTimeRange(ctx, query)
+	if status.Code(err) == codes.NotFound {
+		return &pb.ListDataByTimeRangeResponse{}, nil
+	}
+	return resp, err
+}
+
+// ListWindows handles unary requests for the window results starting within a time range.
+// Responds with an empty list if there are none and Unavailable if window aggregation is disabled.
+func (s *DataServiceServer) ListWindows(ctx context.Context, req *pb.ListWindowsRequest) (*pb.ListWindowsResponse, error) {
+	query, kind, err := api.ProtoToWindowQuery(req, s.precision)
+	if err != nil {
+		glog.Errorf("Invalid request: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	windows, err := s.tenantService(ctx).ListWindows(query.From, query.To, kind, query.Filter, query.Options)
+	switch {
+	case errors.Is(err, service.ErrRangeTooLarge), errors.Is(err, service.ErrInvalidQuery):
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	case errors.Is(err, service.ErrNoWindows):
+		return nil, status.Errorf(codes.Unavailable, "%v", err)
+	case err != nil:
+		glog.Errorf("Service error: %v", err)
+		return nil, status.Errorf(codes.Internal, "internal server error: %v", err)
+	}
+
+	response := pb.ListWindowsResponse{Windows: make([]*pb.WindowResult, len(windows))}
+	for i := range windows {
+		w, err := api.WindowToProto(&windows[i])
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to convert window: %v", err)
+		}
+		w.Start = s.precision.FromStored(windows[i].Start)
+		w.End = s.precision.FromStored(windows[i].End)
+		response.Windows[i] = w
+	}
+
+	return &response, nil
+}
+
+// DownsampleData handles unary requests for a downsampled series within a time range.
+// Responds with an empty list if there are no records.
+func (s *DataServiceServer) DownsampleData(ctx context.Context, req *pb.DownsampleDataRequest) (*pb.ListDataByTimeRangeResponse, error) {
+	from, to, err := s.storedRange(req.GetFrom(), req.GetTo())
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := api.ProtoToFilter(req.GetFilter().GetSeries(), req.GetFilter().GetMatchers())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid filter: %v", err)
+	}
+
+	method := downsample.MethodLTTB
+	switch req.GetMethod() {
+	case pb.DownsampleMethod_DOWNSAMPLE_METHOD_LTTB:
+	case pb.DownsampleMethod_DOWNSAMPLE_METHOD_MINMAX:
+		method = downsample.MethodMinMax
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid method %d", req.GetMethod())
+	}
+
+	points := downsample.DefaultPoints
+	if req.GetPoints() > 0 {
+		points = int(min(req.GetPoints(), math.MaxInt32))
+	}
+
+	dataList, err := s.tenantService(ctx).Downsample(from, to, filter, method, points)
+	switch {
+	case errors.Is(err, service.ErrRangeTooLarge), errors.Is(err, service.ErrInvalidQuery):
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	case err != nil:
+		glog.Errorf("Service error: %v", err)
+		return nil, status.Errorf(codes.Internal, "internal server error: %v", err)
+	}
+
+	response := pb.ListDataByTimeRangeResponse{DataItems: make([]*pb.Data, len(dataList))}
+	for i := range dataList {
+		protoData, err := s.toProto(&dataList[i])
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to convert data: %v", err)
+		}
+		response.DataItems[i] = protoData
+	}
+
+	return &response, nil
+}
+
+// DeleteData handles unary requests for deleting a record by ID.
+// Returns a gRPC error if the ID is invalid or if the record is not found.
+func (s *DataServiceServer) DeleteData(ctx context.Context, req *pb.DeleteDataRequest) (*pb.DeleteDataResponse, error) {
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		glog.Errorf("Invalid UUID format: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "invalid UUID format: %v", err)
+	}
+
+	err = s.tenantService(ctx).Delete(id)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		glog.Infof("Data not found for ID: %s", req.GetId())
+		return nil, status.Errorf(codes.NotFound, "data not found for ID: %s", req.GetId())
+	case err != nil:
+		glog.Errorf("Service error: %v", err)
+		return nil, status.Errorf(codes.Internal, "internal server error: %v", err)
+	}
+
+	glog.Infof("Deleted data for ID: %s", req.GetId())
+
+	return &pb.DeleteDataResponse{Deleted: 1}, nil
+}
+
+// DeleteDataByTimeRange handles unary admin requests for deleting all records within a time range.
+// Returns a gRPC error if the range is invalid.
+func (s *DataServiceServer) DeleteDataByTimeRange(ctx context.Context, req *pb.DeleteDataByTimeRangeRequest) (*pb.DeleteDataResponse, error) {
+	from, to, err := s.storedRange(req.GetFrom(), req.GetTo())
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := s.tenantService(ctx).DeleteByPeriod(from, to)
+	switch {
+	case errors.Is(err, service.ErrInvalidQuery):
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	case err != nil:
+		glog.Errorf("Service error after deleting %d records: %v", deleted, err)
+		return nil, status.Errorf(codes.Internal, "internal server error after deleting %d records: %v", deleted, err)
+	}
+
+	glog.Infof("Deleted %d data items for time range: %d to %d", deleted, from, to)
+
+	return &pb.DeleteDataResponse{Deleted: deleted}, nil
+}
+
+// SubscribeData handles server streaming of records stored after the subscription started.
+// Records are filtered by series, labels and minimum max value, and buffered per subscriber; depending on
+// the slow consumer policy, records that don't fit the buffer are dropped or end the stream with
+// ResourceExhausted. Returns Unavailable if live subscriptions are disabled.
+func (s *DataServiceServer) SubscribeData(req *pb.SubscribeDataRequest, stream pb.DataService_SubscribeDataServer) error {
+	filter, err := api.ProtoToFilter(req.GetFilter().GetSeries(), req.GetFilter().GetMatchers())
+	if err != nil {
+		glog.Errorf("Invalid filter: %v", err)
+		return status.Errorf(codes.InvalidArgument, "invalid filter: %v", err)
+	}
+
+	sub, err := s.tenantService(stream.Context()).Subscribe(
+		hub.Filter{Data: filter, MinMax: req.MinMax},
+		hub.Policy(req.GetSlowConsumerPolicy()),
+		int(req.GetBufferSize()),
+	)
+	if errors.Is(err, service.ErrNoHub) {
+		return status.Errorf(codes.Unavailable, "%v", err)
+	}
+	if err != nil {
+		glog.Errorf("Service error: %v", err)
+		return status.Errorf(codes.Internal, "internal server error: %v", err)
+	}
+	defer sub.Close()
+
+	glog.Infoln("SubscribeData stream started")
+	defer func() { glog.Infof("SubscribeData stream ended, %d records dropped", sub.Dropped()) }()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+
+		case <-sub.Done():
+			glog.Infof("Subscriber disconnected: %v", sub.Err())
+			return status.Errorf(codes.ResourceExhausted, "subscription ended: %v", sub.Err())
+
+		case data := <-sub.Events():
+			protoData, err := s.toProto(&data)
+			if err != nil {
+				glog.Errorf("Error converting data to proto: %v", err)
+				return status.Errorf(codes.Internal, "failed to convert data: %v", err)
+			}
+
+			if err := stream.Send(protoData); err != nil {
+				glog.Errorf("Error sending response: %v", err)
+				return status.Errorf(codes.Internal, "failed to send response: %v", err)
+			}
+		}
+	}
+}
+
 // GetDataById handles bidirectional streaming for getting data by ID.
 // Receives requests with IDs from the client, fetches data, and streams responses back.
-// Returns a gRPC error if the request is invalid or if data is not found.
+// Invalid or missing IDs are reported in the status of the response item; the stream continues.
 func (s *DataServiceServer) GetDataById(stream pb.DataService_GetDataByIdServer) error {
 	glog.Infoln("GetDataById stream started")
 	defer glog.Infoln("GetDataById stream ended")
@@ -55,59 +444,58 @@ func (s *DataServiceServer) GetDataById(stream pb.DataService_GetDataByIdServer)
 		}
 		if err != nil {
 			glog.Errorf("Error receiving request: %v", err)
-			return status.Errorf(codes.Internal, "failed to receive request: %v", err)
+			return recvError(err, "failed to receive request")
 		}
 
-		if req == nil || req.Id == "" {
-			glog.Errorln("Invalid request: ID is empty")
-			return status.Errorf(codes.InvalidArgument, "ID cannot be empty")
-		}
-
-		// Parse UUID from request
-		id, err := uuid.Parse(req.Id)
-		if err != nil {
-			glog.Errorf("Invalid UUID format: %v", err)
-			return status.Errorf(codes.InvalidArgument, "invalid UUID format: %v", err)
+		// Send response back to client
+		if err := stream.Send(s.getDataItem(stream.Context(), req.GetId())); err != nil {
+			glog.Errorf("Error sending response: %v", err)
+			return status.Errorf(codes.Internal, "failed to send response: %v", err)
 		}
+	}
+}
 
-		// Get data from service layer
-		data, err := s.service.GetByID(id)
+// ListDataByTimeRange handles bidirectional streaming for listing data by time range.
+// Receives v1 requests with string time range parameters, converts them with the compatibility shim
+// and serves them as ListDataByTimeRangeV2 does.
+// Invalid requests and empty ranges are reported in the response status; the stream continues.
+func (s *DataServiceServer) ListDataByTimeRange(stream pb.DataService_ListDataByTimeRangeServer) error {
+	glog.Infoln("ListDataByTimeRange stream started")
+	defer glog.Infoln("ListDataByTimeRange stream ended")
 
-		switch {
-		case errors.Is(err, repository.ErrNotFound):
-			glog.Infof("Data not found for ID: %s", req.Id)
-			return status.Errorf(codes.NotFound, "data not found for ID: %s", req.Id)
-		case errors.Is(err, service.ErrNotFound):
-			glog.Infof("Data not found for ID: %s", req.Id)
-			return status.Errorf(codes.NotFound, "data not found for ID: %s", req.Id)
-		case err != nil:
-			glog.Errorf("Service error: %v", err)
-			return status.Errorf(codes.Internal, "internal server error: %v", err)
+	for {
+		// Receive request from client
+		req, err := stream.Recv()
+		if err == io.EOF {
+			glog.Infoln("Client closed stream")
+			return nil
+		}
+		if err != nil {
+			glog.Errorf("Error receiving request: %v", err)
+			return recvError(err, "failed to receive request")
 		}
 
-		// Convert to proto format for response
-		protoData, err := api.DataToProto(data)
+		query, err := api.LegacyProtoToListQuery(req, s.precision)
 		if err != nil {
-			glog.Errorf("Error converting data to proto: %v", err)
-			return status.Errorf(codes.Internal, "failed to convert data: %v", err)
+			glog.Errorf("Invalid request: %v", err)
+			err = status.Errorf(codes.InvalidArgument, "%v", err)
 		}
 
 		// Send response back to client
-		if err := stream.Send(protoData); err != nil {
+		if err := stream.Send(s.listItem(stream.Context(), query, err)); err != nil {
 			glog.Errorf("Error sending response: %v", err)
 			return status.Errorf(codes.Internal, "failed to send response: %v", err)
 		}
-
-		glog.Infof("Successfully sent data for ID: %s", req.Id)
 	}
 }
 
-// ListDataByTimeRange handles bidirectional streaming for listing data by time range.
-// Receives requests with time range parameters, fetches matching data, and streams responses back.
-// Returns a gRPC error if the request is invalid or if no data is found.
-func (s *DataServiceServer) ListDataByTimeRange(stream pb.DataService_ListDataByTimeRangeServer) error {
-	glog.Infoln("ListDataByTimeRange stream started")
-	defer glog.Infoln("ListDataByTimeRange stream ended")
+// ListDataByTimeRangeV2 handles bidirectional streaming for listing data by a typed time range.
+// Receives requests with integer timestamps and optional limit, order and filter, fetches matching data,
+// and streams responses back.
+// Invalid requests and empty ranges are reported in the response status; the stream continues.
+func (s *DataServiceServer) ListDataByTimeRangeV2(stream pb.DataService_ListDataByTimeRangeV2Server) error {
+	glog.Infoln("ListDataByTimeRangeV2 stream started")
+	defer glog.Infoln("ListDataByTimeRangeV2 stream ended")
 
 	for {
 		// Receive request from client
@@ -118,70 +506,135 @@ func (s *DataServiceServer) ListDataByTimeRange(stream pb.DataService_ListDataBy
 		}
 		if err != nil {
 			glog.Errorf("Error receiving request: %v", err)
-			return status.Errorf(codes.Internal, "failed to receive request: %v", err)
+			return recvError(err, "failed to receive request")
 		}
 
-		if req == nil {
-			glog.Errorln("Request is nil")
-			return status.Errorf(codes.InvalidArgument, "request cannot be nil")
-		}
-
-		// Parse time range parameters from request
-		from, err := strconv.ParseInt(req.From, 10, 64)
+		query, err := api.ProtoToListQuery(req, s.precision)
 		if err != nil {
-			glog.Errorf("Invalid 'from' parameter: %v", err)
-			return status.Errorf(codes.InvalidArgument, "invalid 'from' parameter: %v", err)
+			glog.Errorf("Invalid request: %v", err)
+			err = status.Errorf(codes.InvalidArgument, "%v", err)
 		}
 
-		to, err := strconv.ParseInt(req.To, 10, 64)
-		if err != nil {
-			glog.Errorf("Invalid 'to' parameter: %v", err)
-			return status.Errorf(codes.InvalidArgument, "invalid 'to' parameter: %v", err)
+		// Send response back to client
+		if err := stream.Send(s.listItem(stream.Context(), query, err)); err != nil {
+			glog.Errorf("Error sending response: %v", err)
+			return status.Errorf(codes.Internal, "failed to send response: %v", err)
 		}
+	}
+}
 
-		// Validate time range
-		if from >= to {
-			glog.Errorln("Invalid time range: 'from' must be less than 'to'")
-			return status.Errorf(codes.InvalidArgument, "invalid time range: 'from' must be less than 'to'")
+// listItem runs a query of a list stream, reporting the query error (if the request could not be
+// converted) or the listing failure as the response status.
+func (s *DataServiceServer) listItem(ctx context.Context, query *api.ListQuery, queryErr error) *pb.ListDataByTimeRangeResponse {
+	err := queryErr
+	if err == nil {
+		var response *pb.ListDataByTimeRangeResponse
+		if response, err = s.listByTimeRange(ctx, query); err == nil {
+			return response
 		}
+	}
 
-		// Get data from service layer for the specified period
-		dataList, err := s.service.ListByPeriod(from, to)
+	itemStatus, msg := itemStatus(err)
+	return &pb.ListDataByTimeRangeResponse{Status: itemStatus, Error: msg}
+}
+
+// listByTimeRange fetches the data of a time range query and converts it to a response message.
+// Returns a gRPC status error if no data is found or the query is rejected by the service.
+func (s *DataServiceServer) listByTimeRange(ctx context.Context, query *api.ListQuery) (*pb.ListDataByTimeRangeResponse, error) {
+	from, to := query.From, query.To
+
+	// Get data from service layer for the specified period
+	dataList, err := s.tenantService(ctx).ListByPeriod(from, to, query.Filter, query.Options)
+
+	switch {
+	case errors.Is(err, service.ErrRangeTooLarge), errors.Is(err, service.ErrInvalidQuery):
+		glog.Infof("Time range too large: %d to %d", from, to)
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+
+	case errors.Is(err, repository.ErrNotFound):
+		glog.Infof("No data found for time range: %d to %d", from, to)
+		return nil, status.Errorf(codes.NotFound, "no data found for time range: %d to %d", from, to)
+	case errors.Is(err, service.ErrNotFound):
+		glog.Infof("No data found for time range: %d to %d", from, to)
+		return nil, status.Errorf(codes.NotFound, "no data found for time range: %d to %d", from, to)
+	case err != nil:
+		glog.Errorf("Service error: %v", err)
+		return nil, status.Errorf(codes.Internal, "internal server error: %v", err)
+	}
 
-		switch {
-		case errors.Is(err, repository.ErrNotFound):
-			glog.Infof("No data found for time range: %d to %d", from, to)
-			return status.Errorf(codes.NotFound, "no data found for time range: %d to %d", from, to)
-		case errors.Is(err, service.ErrNotFound):
-			glog.Infof("No data found for time range: %d to %d", from, to)
-			return status.Errorf(codes.NotFound, "no data found for time range: %d to %d", from, to)
-		case err != nil:
-			glog.Errorf("Service error: %v", err)
-			return status.Errorf(codes.Internal, "internal server error: %v", err)
+	// Convert data list to proto format for response
+	protoDataList := make([]*pb.Data, len(dataList))
+	for i, data := range dataList {
+		protoData, err := s.toProto(&data)
+		if err != nil {
+			glog.Errorf("Error converting data to proto: %v", err)
+			return nil, status.Errorf(codes.Internal, "failed to convert data: %v", err)
 		}
+		protoDataList[i] = protoData
+	}
 
-		// Convert data list to proto format for response
-		protoDataList := make([]*pb.Data, len(dataList))
-		for i, data := range dataList {
-			protoData, err := api.DataToProto(&data)
-			if err != nil {
-				glog.Errorf("Error converting data to proto: %v", err)
-				return status.Errorf(codes.Internal, "failed to convert data: %v", err)
-			}
-			protoDataList[i] = protoData
+	glog.Infof("Successfully listed %d data items for time range: %d to %d", len(dataList), from, to)
+
+	return &pb.ListDataByTimeRangeResponse{DataItems: protoDataList}, nil
+}
+
+// ingestPack aggregates and stores a single pack.
+// Returns a gRPC status error if the pack is invalid or cannot be stored.
+func (s *DataServiceServer) ingestPack(ctx context.Context, req *pb.Pack) (*pb.IngestPackResponse, error) {
+	if req == nil || (len(req.Data) == 0 && len(req.FloatData) == 0) {
+		glog.Errorln("Invalid pack: data is empty")
+		return nil, status.Errorf(codes.InvalidArgument, "pack data cannot be empty")
+	}
+
+	pack, err := api.ProtoToPack(req, s.precision)
+	if err != nil {
+		glog.Errorf("Invalid pack: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "invalid pack: %v", err)
+	}
+
+	// Aggregate and store the pack
+	data, err := s.tenantService(ctx).Ingest(pack)
+	if errors.Is(err, service.ErrInvalidPack) {
+		glog.Errorf("Invalid pack: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	if err != nil {
+		glog.Errorf("Service error: %v", err)
+		return nil, status.Errorf(codes.Internal, "internal server error: %v", err)
+	}
+
+	return &pb.IngestPackResponse{Id: data.ID.String()}, nil
+}
+
+// IngestPacks handles bidirectional streaming for submitting raw packs.
+// Receives packs from the client, aggregates and stores them, and streams back the stored IDs.
+// Invalid packs and storage failures are reported in the response status; the stream continues.
+func (s *DataServiceServer) IngestPacks(stream pb.DataService_IngestPacksServer) error {
+	glog.Infoln("IngestPacks stream started")
+	defer glog.Infoln("IngestPacks stream ended")
+
+	for {
+		// Receive pack from client
+		req, err := stream.Recv()
+		if err == io.EOF {
+			glog.Infoln("Client closed stream")
+			return nil
+		}
+		if err != nil {
+			glog.Errorf("Error receiving pack: %v", err)
+			return recvError(err, "failed to receive pack")
 		}
 
-		// Create response message
-		response := &pb.ListDataByTimeRangeResponse{
-			DataItems: protoDataList,
+		response, err := s.ingestPack(stream.Context(), req)
+		if err != nil {
+			itemStatus, msg := itemStatus(err)
+			response = &pb.IngestPackResponse{Status: itemStatus, Error: msg}
 		}
 
-		// Send response back to client
+		// Send acknowledgement back to client
 		if err := stream.Send(response); err != nil {
 			glog.Errorf("Error sending response: %v", err)
 			return status.Errorf(codes.Internal, "failed to send response: %v", err)
 		}
-
-		glog.Infof("Successfully sent %d data items for time range: %d to %d", len(dataList), from, to)
 	}
 }
@@ -0,0 +1,293 @@
+// Package grpc contains tests for the gRPC DataService handlers.
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+	"xis-data-aggregator/internal/anomaly"
+	"xis-data-aggregator/internal/hub"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/repository/repotest"
+	"xis-data-aggregator/internal/service"
+	"xis-data-aggregator/internal/windowing"
+	"xis-data-aggregator/pb"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient starts a DataService server over an in-memory connection and returns a client for it.
+func newTestClient(t *testing.T) (pb.DataServiceClient, *service.DataService) {
+	svc := service.NewDataService(repotest.New(t))
+
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	RegisterDataServiceServer(s, svc, models.PrecisionMicroseconds)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewDataServiceClient(conn), svc
+}
+
+// TestUnaryRPCs tests GetData, BatchGetData, ListData and the delete RPCs.
+func TestUnaryRPCs(t *testing.T) {
+	client, svc := newTestClient(t)
+	ctx := context.Background()
+
+	stored, err := svc.Ingest(&models.Pack{Timestamp: 100, Data: models.IntValues([]int64{1, 7, 3})})
+	require.NoError(t, err)
+
+	got, err := client.GetData(ctx, &pb.GetDataByIDRequest{Id: stored.ID.String()})
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), got.GetMaxInt64())
+
+	_, err = client.GetData(ctx, &pb.GetDataByIDRequest{Id: uuid.NewString()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	missing := uuid.NewString()
+	batch, err := client.BatchGetData(ctx, &pb.BatchGetDataRequest{Ids: []string{stored.ID.String(), "bad-id", missing}})
+	require.NoError(t, err)
+	require.Len(t, batch.Items, 3)
+	assert.Equal(t, pb.ItemStatus_ITEM_STATUS_OK, batch.Items[0].Status)
+	assert.Equal(t, int64(7), batch.Items[0].GetMaxInt64())
+	assert.Equal(t, pb.ItemStatus_ITEM_STATUS_INVALID, batch.Items[1].Status)
+	assert.Equal(t, "bad-id", batch.Items[1].Id)
+	assert.Equal(t, pb.ItemStatus_ITEM_STATUS_NOT_FOUND, batch.Items[2].Status)
+	assert.Equal(t, []string{missing}, batch.MissingIds)
+
+	list, err := client.ListData(ctx, &pb.ListDataByTimeRangeRequestV2{From: 0, To: 200})
+	require.NoError(t, err)
+	assert.Len(t, list.DataItems, 1)
+
+	_, err = client.ListData(ctx, &pb.ListDataByTimeRangeRequestV2{From: 200, To: 100})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Deletes
+	_, err = svc.Ingest(&models.Pack{Timestamp: 150, Data: models.IntValues([]int64{2})})
+	require.NoError(t, err)
+
+	deleted, err := client.DeleteData(ctx, &pb.DeleteDataRequest{Id: stored.ID.String()})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted.Deleted)
+
+	_, err = client.GetData(ctx, &pb.GetDataByIDRequest{Id: stored.ID.String()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.DeleteData(ctx, &pb.DeleteDataRequest{Id: stored.ID.String()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.DeleteDataByTimeRange(ctx, &pb.DeleteDataByTimeRangeRequest{From: 200, To: 0})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	deleted, err = client.DeleteDataByTimeRange(ctx, &pb.DeleteDataByTimeRangeRequest{From: 0, To: 200})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted.Deleted)
+
+	_, err = client.ListData(ctx, &pb.ListDataByTimeRangeRequestV2{From: 0, To: 200})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+// TestStreamItemStatus tests that stream items that fail are reported in their status without ending the stream.
+func TestStreamItemStatus(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	ingest, err := client.IngestPacks(ctx)
+	require.NoError(t, err)
+
+	// An invalid pack does not abort the stream
+	require.NoError(t, ingest.Send(&pb.Pack{Timestamp: 100}))
+	ack, err := ingest.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, pb.ItemStatus_ITEM_STATUS_INVALID, ack.Status)
+
+	require.NoError(t, ingest.Send(&pb.Pack{Timestamp: 100, FloatData: []float64{1.5}}))
+	ack, err = ingest.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, pb.ItemStatus_ITEM_STATUS_OK, ack.Status)
+	require.NoError(t, ingest.CloseSend())
+
+	get, err := client.GetDataById(ctx)
+	require.NoError(t, err)
+
+	// Define test cases for the GetDataById stream, sent in order on the same stream
+	tests := []struct {
+		name string        // Name of the test case
+		id   string        // Requested ID
+		want pb.ItemStatus // Expected item status
+	}{
+		{name: "Invalid ID", id: "bad-id", want: pb.ItemStatus_ITEM_STATUS_INVALID},
+		{name: "Missing ID", id: uuid.NewString(), want: pb.ItemStatus_ITEM_STATUS_NOT_FOUND},
+		{name: "Stored ID", id: ack.Id, want: pb.ItemStatus_ITEM_STATUS_OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, get.Send(&pb.GetDataByIDRequest{Id: tt.id}))
+			item, err := get.Recv()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, item.Status)
+			assert.Equal(t, tt.id, item.Id)
+		})
+	}
+
+	list, err := client.ListDataByTimeRange(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, list.Send(&pb.ListDataByTimeRangeRequest{From: "soon", To: "200"}))
+	resp, err := list.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, pb.ItemStatus_ITEM_STATUS_INVALID, resp.Status)
+
+	require.NoError(t, list.Send(&pb.ListDataByTimeRangeRequest{From: "0", To: "200"}))
+	resp, err = list.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, pb.ItemStatus_ITEM_STATUS_OK, resp.Status)
+	assert.Len(t, resp.DataItems, 1)
+}
+
+// TestSubscribeData tests that subscribers receive matching records stored after they subscribed.
+func TestSubscribeData(t *testing.T) {
+	client, svc := newTestClient(t)
+	h := hub.New(8)
+	svc.SetHub(h)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	minMax := 5.0
+	stream, err := client.SubscribeData(ctx, &pb.SubscribeDataRequest{Filter: &pb.Filter{Series: "cpu"}, MinMax: &minMax})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return h.Len() == 1 }, time.Second, time.Millisecond)
+
+	for _, pack := range []models.Pack{
+		{Series: "mem", Data: models.IntValues([]int64{9})}, // Other series
+		{Series: "cpu", Data: models.IntValues([]int64{1})}, // Below min_max
+		{Series: "cpu", Data: models.IntValues([]int64{7})},
+	} {
+		_, err := svc.Ingest(&pack)
+		require.NoError(t, err)
+	}
+
+	got, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "cpu", got.Series)
+	assert.Equal(t, int64(7), got.GetMaxInt64())
+
+	// The subscription is removed when the client goes away
+	cancel()
+	require.Eventually(t, func() bool { return h.Len() == 0 }, time.Second, time.Millisecond)
+}
+
+// TestListAnomalies tests that only the records flagged by the anomaly detector are listed.
+func TestListAnomalies(t *testing.T) {
+	client, svc := newTestClient(t)
+	svc.SetDetector(anomaly.New(60, 0.1, 3))
+	ctx := context.Background()
+
+	list, err := client.ListAnomalies(ctx, &pb.ListDataByTimeRangeRequestV2{From: 0, To: 1000})
+	require.NoError(t, err)
+	assert.Empty(t, list.DataItems)
+
+	for i, v := range []int64{10, 12, 9, 11, 10, 12, 9, 11, 10, 12, 80, 11} {
+		_, err := svc.Ingest(&models.Pack{Timestamp: int64(100 + i), Data: models.IntValues([]int64{v})})
+		require.NoError(t, err)
+	}
+
+	list, err = client.ListAnomalies(ctx, &pb.ListDataByTimeRangeRequestV2{From: 0, To: 1000})
+	require.NoError(t, err)
+	require.Len(t, list.DataItems, 1)
+	assert.Equal(t, int64(80), list.DataItems[0].GetMaxInt64())
+	assert.True(t, list.DataItems[0].Anomalous)
+	assert.Greater(t, list.DataItems[0].AnomalyScore, 3.0)
+
+	_, err = client.ListAnomalies(ctx, &pb.ListDataByTimeRangeRequestV2{From: 200, To: 100})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// TestListWindows tests that window results are listed by start, kind and series.
+func TestListWindows(t *testing.T) {
+	client, svc := newTestClient(t)
+	ctx := context.Background()
+
+	_, err := client.ListWindows(ctx, &pb.ListWindowsRequest{From: 0, To: 1000})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	windows, err := windowing.New(repotest.New(t), 10*time.Microsecond, 0, 0, 0)
+	require.NoError(t, err)
+	svc.SetWindows(windows)
+
+	for _, pack := range []models.Pack{
+		{Timestamp: 1, Series: "cpu", Data: models.IntValues([]int64{4})},
+		{Timestamp: 5, Series: "cpu", Data: models.IntValues([]int64{8})},
+		{Timestamp: 12, Series: "cpu", Data: models.IntValues([]int64{1})},
+		{Timestamp: 12, Series: "mem", Data: models.IntValues([]int64{1})},
+	} {
+		_, err := svc.Ingest(&pack)
+		require.NoError(t, err)
+	}
+
+	list, err := client.ListWindows(ctx, &pb.ListWindowsRequest{From: 0, To: 1000, Kind: pb.WindowKind_WINDOW_KIND_SLIDING})
+	require.NoError(t, err)
+	require.Len(t, list.Windows, 1)
+	w := list.Windows[0]
+	assert.Equal(t, "cpu", w.Series)
+	assert.Equal(t, int64(0), w.Start)
+	assert.Equal(t, int64(10), w.End)
+	assert.Equal(t, int64(2), w.Count)
+	assert.Equal(t, int64(4), w.GetMinInt64())
+	assert.Equal(t, int64(8), w.GetMaxInt64())
+	assert.Equal(t, 6.0, w.Avg)
+
+	list, err = client.ListWindows(ctx, &pb.ListWindowsRequest{From: 0, To: 1000, Kind: pb.WindowKind_WINDOW_KIND_SESSION})
+	require.NoError(t, err)
+	assert.Empty(t, list.Windows)
+
+	_, err = client.ListWindows(ctx, &pb.ListWindowsRequest{From: 0, To: 1000, Kind: pb.WindowKind(9)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// TestDownsampleData tests that long series are reduced to the requested points.
+func TestDownsampleData(t *testing.T) {
+	client, svc := newTestClient(t)
+	ctx := context.Background()
+
+	list, err := client.DownsampleData(ctx, &pb.DownsampleDataRequest{From: 0, To: 10_000, Points: 50})
+	require.NoError(t, err)
+	assert.Empty(t, list.DataItems)
+
+	for i := int64(0); i < 2500; i++ {
+		_, err := svc.Ingest(&models.Pack{Timestamp: i, Series: "cpu", Data: models.IntValues([]int64{i % 100})})
+		require.NoError(t, err)
+	}
+
+	for _, method := range []pb.DownsampleMethod{pb.DownsampleMethod_DOWNSAMPLE_METHOD_LTTB, pb.DownsampleMethod_DOWNSAMPLE_METHOD_MINMAX} {
+		list, err = client.DownsampleData(ctx, &pb.DownsampleDataRequest{From: 0, To: 10_000, Points: 50, Method: method,
+			Filter: &pb.Filter{Series: "cpu"}})
+		require.NoError(t, err)
+		assert.NotEmpty(t, list.DataItems, method)
+		assert.LessOrEqual(t, len(list.DataItems), 50, method)
+	}
+
+	_, err = client.DownsampleData(ctx, &pb.DownsampleDataRequest{From: 0, To: 10_000, Points: 2})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.DownsampleData(ctx, &pb.DownsampleDataRequest{From: 0, To: 10_000, Method: pb.DownsampleMethod(7)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// The range is capped by the maximum query span
+	svc.SetMaxQuerySpan(1000)
+	_, err = client.DownsampleData(ctx, &pb.DownsampleDataRequest{From: 0, To: 10_000})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
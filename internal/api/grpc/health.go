@@ -0,0 +1,40 @@
+package grpc
+
+import (
+	"context"
+	"xis-data-aggregator/internal/service"
+	"xis-data-aggregator/pb"
+
+	"github.com/golang/glog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthServer implements the standard gRPC health service. Check reports the server ("") and DataService as
+// serving while the repository responds; Watch and List report the static status of the embedded server.
+type healthServer struct {
+	*health.Server
+	service *service.DataService
+}
+
+// RegisterHealthServer registers the gRPC health service of the data service on the gRPC server.
+func RegisterHealthServer(s *grpc.Server, service *service.DataService) {
+	server := &healthServer{Server: health.NewServer(), service: service}
+	server.SetServingStatus(pb.DataService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, server)
+}
+
+// Check returns the status of the server or of DataService, NotFound for other services.
+func (s *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	resp, err := s.Server.Check(ctx, req)
+	if err != nil || resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return resp, err
+	}
+
+	if err := s.service.Health(); err != nil {
+		glog.Errorf("Health check error: %v", err)
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+	}
+	return resp, nil
+}
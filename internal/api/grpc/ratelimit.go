@@ -0,0 +1,98 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"xis-data-aggregator/internal/auth"
+	"xis-data-aggregator/internal/ratelimit"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// RateLimits holds the per-client limiters applied to methods requiring the given scope (see methodScopes).
+// A missing or nil limiter disables the check for that scope.
+type RateLimits map[auth.Scope]*ratelimit.Limiter
+
+// UnaryRateLimitInterceptor returns a unary server interceptor that applies the per-client limits.
+// It must be chained after the auth interceptor to key clients by principal.
+func UnaryRateLimitInterceptor(limits RateLimits) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := limits.allow(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamRateLimitInterceptor returns a stream server interceptor that applies the per-client limits
+// to every message received on the stream, so a single long-lived stream cannot bypass them.
+// It must be chained after the auth interceptor to key clients by principal.
+func StreamRateLimitInterceptor(limits RateLimits) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if limits[methodScopes[info.FullMethod]] == nil {
+			return handler(srv, ss)
+		}
+
+		return handler(srv, &rateLimitedStream{ServerStream: ss, limits: limits, method: info.FullMethod})
+	}
+}
+
+// allow takes a token for the caller from the limiter of the method scope.
+// Returns a ResourceExhausted status carrying RetryInfo when the client is over its limit.
+func (limits RateLimits) allow(ctx context.Context, fullMethod string) error {
+	ok, retryAfter := limits[methodScopes[fullMethod]].Allow(clientKey(ctx))
+	if ok {
+		return nil
+	}
+
+	// Retry hint for clients that don't read status details
+	_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after-ms", strconv.FormatInt(retryAfter.Milliseconds(), 10)))
+
+	st := status.New(codes.ResourceExhausted, "rate limit exceeded")
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}); err == nil {
+		st = detailed
+	}
+
+	return st.Err()
+}
+
+// clientKey identifies the caller by the principal authenticated from the request metadata or, if none, by peer IP.
+func clientKey(ctx context.Context) string {
+	if p, ok := PrincipalFromContext(ctx); ok {
+		return p.Subject
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return "ip:" + host
+	}
+
+	return "unknown"
+}
+
+// rateLimitedStream wraps a grpc.ServerStream to take a token for every received message.
+type rateLimitedStream struct {
+	grpc.ServerStream
+	limits RateLimits
+	method string
+}
+
+// RecvMsg receives the next message if the client is within its limit.
+func (s *rateLimitedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return s.limits.allow(s.Context(), s.method)
+}
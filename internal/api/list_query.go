@@ -0,0 +1,116 @@
+package api
+
+import (
+	"fmt"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/pb"
+)
+
+// ListQuery is a time range query in stored units, shared by the v1 and v2 protobuf requests.
+type ListQuery struct {
+	From    int64              // Inclusive start, stored units
+	To      int64              // Inclusive end, stored units
+	Filter  *models.Filter     // Series and label selector
+	Options models.ListOptions // Limit and order
+}
+
+// ProtoToListQuery converts a typed pb.ListDataByTimeRangeRequestV2 to a ListQuery.
+// from/to are integer timestamps in the given precision.
+// Returns an error if the request is nil, the order is unknown, a timestamp overflows, the range is empty,
+// or a matcher is invalid.
+func ProtoToListQuery(req *pb.ListDataByTimeRangeRequestV2, precision models.TimestampPrecision) (*ListQuery, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request is nil")
+	}
+
+	if _, ok := pb.Order_name[int32(req.Order)]; !ok {
+		return nil, fmt.Errorf("invalid order %d", req.Order)
+	}
+
+	query := ListQuery{
+		Options: models.ListOptions{
+			Limit: int(req.Limit),
+			Order: models.SortOrder(req.Order),
+		},
+	}
+
+	var err error
+	if query.From, err = precision.ToStored(req.From); err != nil {
+		return nil, fmt.Errorf("invalid 'from' parameter: %w", err)
+	}
+	if query.To, err = precision.ToStored(req.To); err != nil {
+		return nil, fmt.Errorf("invalid 'to' parameter: %w", err)
+	}
+
+	if query.From >= query.To {
+		return nil, fmt.Errorf("invalid time range: 'from' must be less than 'to'")
+	}
+
+	query.Filter, err = ProtoToFilter(req.GetFilter().GetSeries(), req.GetFilter().GetMatchers())
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	return &query, nil
+}
+
+// ProtoToWindowQuery converts a pb.ListWindowsRequest to a ListQuery over window starts and the requested kind.
+// Returns an error if the request is nil, the range is empty, a matcher or the kind is invalid.
+func ProtoToWindowQuery(req *pb.ListWindowsRequest, precision models.TimestampPrecision) (*ListQuery, models.WindowKind, error) {
+	if req == nil {
+		return nil, "", fmt.Errorf("request is nil")
+	}
+
+	query, err := ProtoToListQuery(&pb.ListDataByTimeRangeRequestV2{
+		From:   req.From,
+		To:     req.To,
+		Limit:  req.Limit,
+		Order:  req.Order,
+		Filter: req.Filter,
+	}, precision)
+	if err != nil {
+		return nil, "", err
+	}
+
+	kind, err := ProtoToWindowKind(req.Kind)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return query, kind, nil
+}
+
+// LegacyProtoToListQuery is the compatibility shim for the v1 pb.ListDataByTimeRangeRequest.
+// It uses `from_time`/`to_time` when set, otherwise parses the `from`/`to` strings with ParseTimestamp.
+// Returns an error if the request is nil, a timestamp is invalid, the range is empty, or a matcher is invalid.
+func LegacyProtoToListQuery(req *pb.ListDataByTimeRangeRequest, precision models.TimestampPrecision) (*ListQuery, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request is nil")
+	}
+
+	var query ListQuery
+	var err error
+
+	if req.FromTime != nil {
+		query.From = models.Timestamp(req.FromTime.AsTime())
+	} else if query.From, err = ParseTimestamp(req.From, precision); err != nil {
+		return nil, fmt.Errorf("invalid 'from' parameter: %w", err)
+	}
+
+	if req.ToTime != nil {
+		query.To = models.Timestamp(req.ToTime.AsTime())
+	} else if query.To, err = ParseTimestamp(req.To, precision); err != nil {
+		return nil, fmt.Errorf("invalid 'to' parameter: %w", err)
+	}
+
+	if query.From >= query.To {
+		return nil, fmt.Errorf("invalid time range: 'from' must be less than 'to'")
+	}
+
+	query.Filter, err = ProtoToFilter(req.Series, req.Matchers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	return &query, nil
+}
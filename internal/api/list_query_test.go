@@ -0,0 +1,72 @@
+// Package api contains tests for mapping v1 and v2 range requests to a ListQuery.
+package api
+
+import (
+	"math"
+	"testing"
+	"time"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/pb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// TestListQueryCompatibility tests that v1 and v2 requests for the same range map to the same ListQuery.
+func TestListQueryCompatibility(t *testing.T) {
+	const from, to = int64(1640995200), int64(1641081600) // 2022-01-01 to 2022-01-02 UTC in seconds
+	want := &ListQuery{From: from * 1_000_000, To: to * 1_000_000, Filter: &models.Filter{Series: "cpu"}}
+
+	// Define test cases for LegacyProtoToListQuery
+	tests := []struct {
+		name string                         // Name of the test case
+		req  *pb.ListDataByTimeRangeRequest // v1 request
+	}{
+		{name: "Plain integers", req: &pb.ListDataByTimeRangeRequest{From: "1640995200", To: "1641081600", Series: "cpu"}},
+		{name: "Unit suffixes", req: &pb.ListDataByTimeRangeRequest{From: "1640995200000ms", To: "1641081600s", Series: "cpu"}},
+		{name: "RFC3339", req: &pb.ListDataByTimeRangeRequest{From: "2022-01-01T00:00:00Z", To: "2022-01-02T00:00:00Z", Series: "cpu"}},
+		{name: "Timestamps", req: &pb.ListDataByTimeRangeRequest{
+			FromTime: timestamppb.New(time.Unix(from, 0)),
+			ToTime:   timestamppb.New(time.Unix(to, 0)),
+			Series:   "cpu",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LegacyProtoToListQuery(tt.req, models.PrecisionSeconds)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+
+	got, err := ProtoToListQuery(&pb.ListDataByTimeRangeRequestV2{From: from, To: to, Filter: &pb.Filter{Series: "cpu"}}, models.PrecisionSeconds)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+// TestProtoToListQuery tests v2 request validation and the mapping of limit and order.
+func TestProtoToListQuery(t *testing.T) {
+	got, err := ProtoToListQuery(&pb.ListDataByTimeRangeRequestV2{From: 1, To: 2, Limit: 10, Order: pb.Order_ORDER_DESC}, models.PrecisionMicroseconds)
+	require.NoError(t, err)
+	assert.Equal(t, models.ListOptions{Limit: 10, Order: models.OrderDesc}, got.Options)
+	assert.Equal(t, &models.Filter{}, got.Filter)
+
+	_, err = ProtoToListQuery(&pb.ListDataByTimeRangeRequestV2{From: 2, To: 2}, models.PrecisionMicroseconds)
+	assert.Error(t, err, "empty range")
+
+	_, err = ProtoToListQuery(&pb.ListDataByTimeRangeRequestV2{From: 1, To: 2, Filter: &pb.Filter{
+		Matchers: []*pb.LabelMatcher{{Name: "host", Type: pb.LabelMatcher_REGEX, Value: "("}},
+	}}, models.PrecisionMicroseconds)
+	assert.Error(t, err, "invalid matcher")
+
+	_, err = ProtoToListQuery(&pb.ListDataByTimeRangeRequestV2{From: 1, To: 2, Order: pb.Order(2)}, models.PrecisionMicroseconds)
+	assert.Error(t, err, "unknown order")
+
+	_, err = ProtoToListQuery(&pb.ListDataByTimeRangeRequestV2{From: 1, To: math.MaxInt64}, models.PrecisionSeconds)
+	assert.ErrorIs(t, err, models.ErrTimestampRange)
+
+	_, err = ProtoToListQuery(nil, models.PrecisionMicroseconds)
+	assert.Error(t, err, "nil request")
+}
@@ -0,0 +1,209 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"xis-data-aggregator/internal/alerting"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ruleError writes the response for an error of a rule operation.
+func ruleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, alerting.ErrInvalidRule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, alerting.ErrRuleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, alerting.ErrRuleExists), errors.Is(err, alerting.ErrRuleReadOnly):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNoAlerting):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+// ListRules godoc
+// @Summary      List alert rules
+// @Description  get the alert rules of the caller's tenant
+// @Tags         alerts
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Success      200  {array}   models.Rule
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Router       /rules [get]
+// ListRules handles GET requests to fetch the alert rules sorted by ID.
+func (h *DataServiceServer) ListRules(c *gin.Context) {
+	rules, err := h.tenantService(c).Rules()
+	if err != nil {
+		ruleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// GetRule godoc
+// @Summary      Get alert rule
+// @Description  get an alert rule by ID
+// @Tags         alerts
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Rule ID"
+// @Success      200  {object}  models.Rule
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Router       /rules/{id} [get]
+// GetRule handles GET requests to fetch an alert rule. Responds with 404 if there is no such rule.
+func (h *DataServiceServer) GetRule(c *gin.Context) {
+	rule, err := h.tenantService(c).Rule(c.Param("id"))
+	if err != nil {
+		ruleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// CreateRule godoc
+// @Summary      Create alert rule
+// @Description  create an alert rule evaluated on every stored record of the caller's tenant (admin)
+// @Tags         alerts
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        rule  body      models.Rule  true  "Rule"
+// @Success      201   {object}  models.Rule
+// @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
+// @Failure      429   {object}  map[string]string
+// @Failure      503   {object}  map[string]string
+// @Router       /rules [post]
+// CreateRule handles POST requests to create an alert rule.
+// Responds with 400 if the rule is invalid or 409 if a rule with the ID exists.
+func (h *DataServiceServer) CreateRule(c *gin.Context) {
+	var rule models.Rule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body: " + err.Error()})
+		return
+	}
+
+	rule, err := h.tenantService(c).CreateRule(rule)
+	if err != nil {
+		ruleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateRule godoc
+// @Summary      Replace alert rule
+// @Description  replace an alert rule, its windows and counters restart (admin)
+// @Tags         alerts
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string       true  "Rule ID"
+// @Param        rule  body      models.Rule  true  "Rule, the ID is taken from the path"
+// @Success      200   {object}  models.Rule
+// @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
+// @Failure      429   {object}  map[string]string
+// @Failure      503   {object}  map[string]string
+// @Router       /rules/{id} [put]
+// UpdateRule handles PUT requests to replace an alert rule.
+// Responds with 400 if the rule is invalid, 404 if there is no such rule or 409 if it is a config rule.
+func (h *DataServiceServer) UpdateRule(c *gin.Context) {
+	var rule models.Rule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body: " + err.Error()})
+		return
+	}
+	rule.ID = c.Param("id")
+
+	rule, err := h.tenantService(c).UpdateRule(rule)
+	if err != nil {
+		ruleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule godoc
+// @Summary      Delete alert rule
+// @Description  delete an alert rule and resolve its firing alerts (admin)
+// @Tags         alerts
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Rule ID"
+// @Success      204
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Router       /rules/{id} [delete]
+// DeleteRule handles DELETE requests to remove an alert rule.
+// Responds with 404 if there is no such rule or 409 if it is a config rule.
+func (h *DataServiceServer) DeleteRule(c *gin.Context) {
+	if err := h.tenantService(c).DeleteRule(c.Param("id")); err != nil {
+		ruleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListAlerts godoc
+// @Summary      List alerts
+// @Description  get the alert states of the caller's tenant, one per rule and series
+// @Tags         alerts
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        state  query     string  false  "Only alerts in this state: firing or resolved"
+// @Param        rule   query     string  false  "Only alerts of this rule ID"
+// @Success      200  {array}   models.Alert
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Router       /alerts [get]
+// ListAlerts handles GET requests to fetch alerts sorted by key. Responds with 400 if the state is invalid.
+func (h *DataServiceServer) ListAlerts(c *gin.Context) {
+	state := models.AlertState(c.Query("state"))
+	switch state {
+	case "", models.AlertFiring, models.AlertResolved:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid state, expected firing or resolved"})
+		return
+	}
+
+	alerts, err := h.tenantService(c).Alerts(state, c.Query("rule"))
+	if err != nil {
+		ruleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
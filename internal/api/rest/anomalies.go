@@ -0,0 +1,56 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/repository"
+	"xis-data-aggregator/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListAnomalies godoc
+// @Summary      List anomalies by time range
+// @Description  get the records flagged by the anomaly detector within a time range, with their anomaly_score
+// @Tags         data
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        from  query     string  true  "From timestamp: RFC3339, unit-suffixed (e.g. 1704067200s, 1704067200000ms) or integer in the declared precision"
+// @Param        to    query     string  true  "To timestamp: RFC3339, unit-suffixed (e.g. 1704153600s, 1704153600000ms) or integer in the declared precision"
+// @Param        ts_format  query  string  false  "Set to rfc3339 to render ts as an RFC3339 string"
+// @Param        series  query   string  false  "Series name"
+// @Param        label   query   []string  false  "Label matchers (name=value, name!=value, name=~regexp, name!~regexp)"  collectionFormat(multi)
+// @Success      200  {array}   models.Data
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /anomalies [get]
+// ListAnomalies handles GET requests to fetch the anomalous data items within a specified time range.
+// Responds with an empty array if there are none, 400 if parameters are invalid, or 500 for internal errors.
+func (h *DataServiceServer) ListAnomalies(c *gin.Context) {
+	from, to, filter, ok := h.parseRangeQuery(c)
+	if !ok {
+		return
+	}
+
+	data, err := h.tenantService(c).ListAnomalies(from, to, filter, models.ListOptions{})
+	switch {
+	case errors.Is(err, service.ErrRangeTooLarge), errors.Is(err, service.ErrInvalidQuery):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, service.ErrNotFound):
+		data = nil
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	views := make([]*models.Data, len(data))
+	for i := range data {
+		views[i] = &data[i]
+	}
+	c.JSON(http.StatusOK, h.renderData(c, views))
+}
@@ -0,0 +1,57 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"xis-data-aggregator/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// principalKey is the gin context key holding the authenticated *auth.Principal.
+const principalKey = "principal"
+
+// AuthMiddleware returns a Gin middleware that requires a credential carrying the given scope.
+// The credential is read from the "Authorization: Bearer <token>" header or the "X-API-Key" header.
+// Responds with 401 if the credential is missing or invalid and 403 if the scope is not granted.
+// A nil authenticator disables the check.
+func AuthMiddleware(a *auth.Authenticator, scope auth.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a == nil {
+			c.Next()
+			return
+		}
+
+		credential := auth.BearerToken(c.GetHeader("Authorization"))
+		if credential == "" {
+			credential = c.GetHeader("X-API-Key")
+		}
+
+		principal, err := a.Authorize(credential, scope)
+		switch {
+		case errors.Is(err, auth.ErrPermissionDenied):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "permission denied"})
+			return
+		case err != nil:
+			c.Header("WWW-Authenticate", `Bearer realm="xis-data-aggregator"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthenticated"})
+			return
+		}
+
+		c.Set(principalKey, principal)
+		c.Next()
+	}
+}
+
+// QueryTokenMiddleware returns a Gin middleware that accepts the credential from the "access_token" query
+// parameter (RFC 6750) when no credential header is set. Browsers can't set headers on EventSource and
+// WebSocket requests, so it is meant for the live feed routes only and must precede AuthMiddleware.
+func QueryTokenMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Query("access_token")
+		if token != "" && c.GetHeader("Authorization") == "" && c.GetHeader("X-API-Key") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,107 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+	"xis-data-aggregator/internal/backup"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+)
+
+// Backup godoc
+// @Summary      Back up data
+// @Description  download a versioned, checksummed archive of all data records of the caller's tenant (admin)
+// @Tags         admin
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Produce      application/octet-stream
+// @Success      200  {file}    file
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /admin/backup [get]
+// Backup handles GET requests for an archive of the tenant's records, streamed while the records are read.
+// Responds with 500 if the repository fails before the archive starts; a later failure aborts the connection.
+func (h *DataServiceServer) Backup(c *gin.Context) {
+	svc := h.tenantService(c)
+
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="backup-%s.xisbak"`, time.Now().UTC().Format("20060102T150405Z")))
+
+	m, err := svc.Backup(c.Writer)
+	switch {
+	case err == nil:
+		glog.Infof("Backup of tenant %q: %d records", svc.Tenant(), m.Records)
+	case c.Writer.Written():
+		glog.Errorf("Backup of tenant %q aborted: %v", svc.Tenant(), err)
+		abortStream(c)
+	default:
+		glog.Errorf("Backup of tenant %q error: %v", svc.Tenant(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+// Restore godoc
+// @Summary      Restore data
+// @Description  restore the records of a backup archive into the caller's tenant (admin); records with the IDs of existing ones overwrite them, others are kept. The archive is verified before anything is stored.
+// @Tags         admin
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Accept       application/octet-stream
+// @Param        archive  body      string  true  "Backup archive"
+// @Success      200      {object}  backup.Result
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      413      {object}  map[string]string
+// @Failure      429      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/restore [post]
+// Restore handles POST requests with a backup archive, spooled to a temporary file to verify it before
+// restoring. Responds with the manifest and the records restored, or with 400 if the archive is corrupted or of an
+// unsupported version, 413 if it exceeds the upload limit, or 500 for internal errors.
+func (h *DataServiceServer) Restore(c *gin.Context) {
+	f, err := os.CreateTemp("", "restore-*.xisbak")
+	if err != nil {
+		glog.Errorf("Restore spool file error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if _, err := io.Copy(f, h.uploadBody(c)); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("archive larger than %d bytes", tooLarge.Limit)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	svc := h.tenantService(c)
+	res, err := svc.Restore(f)
+	switch {
+	case errors.Is(err, backup.ErrCorrupt), errors.Is(err, backup.ErrUnsupported):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		glog.Errorf("Restore of tenant %q error: %v", svc.Tenant(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	glog.Infof("Restore of tenant %q: %d records restored, %d expired", svc.Tenant(), res.Restored, res.Expired)
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,32 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CustomMethodParam is the route parameter holding a custom method path segment, e.g. "data:batchGet".
+// Register the dispatcher as `POST /api/v1/:method`.
+const CustomMethodParam = "method"
+
+// CustomMethods returns a handler serving `resource:verb` custom methods, which gin can't route as
+// static paths because ':' starts a parameter. Each method has its own handler chain (middlewares first).
+// Responds with 404 for unknown methods.
+func CustomMethods(methods map[string]gin.HandlersChain) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chain, ok := methods[c.Param(CustomMethodParam)]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+
+		// Middlewares of the chain call c.Next() as their last step, which is a no-op at the end of the route chain
+		for _, handler := range chain {
+			handler(c)
+			if c.IsAborted() {
+				return
+			}
+		}
+	}
+}
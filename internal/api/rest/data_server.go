@@ -3,8 +3,13 @@ package rest
 
 import (
 	"errors"
+	"io"
 	"net/http"
-	"strconv"
+	"xis-data-aggregator/internal/api"
+	"xis-data-aggregator/internal/auth"
+	"xis-data-aggregator/internal/export"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/ratelimit"
 	"xis-data-aggregator/internal/repository"
 
 	"xis-data-aggregator/internal/service"
@@ -15,21 +20,94 @@ import (
 
 // DataServiceServer handles HTTP requests for data operations.
 type DataServiceServer struct {
-	service *service.DataService // Business logic service
+	service       *service.DataService      // Business logic service
+	precision     models.TimestampPrecision // Unit of integer timestamps exchanged with clients
+	ingestLimiter *ratelimit.Limiter        // Charged per imported batch, nil - no limit
+	maxUpload     int64                     // Maximum body size of file uploads in bytes, 0 - no limit
 }
 
-// NewDataServiceServer creates a new DataServiceServer with the provided service.
-func NewDataServiceServer(service *service.DataService) *DataServiceServer {
-	return &DataServiceServer{service: service}
+// NewDataServiceServer creates a new DataServiceServer with the provided service and the declared
+// precision of integer timestamps.
+func NewDataServiceServer(service *service.DataService, precision models.TimestampPrecision) *DataServiceServer {
+	return &DataServiceServer{service: service, precision: precision}
+}
+
+// SetIngestLimiter sets the limiter charged for every batch of an import, besides the request itself.
+func (h *DataServiceServer) SetIngestLimiter(l *ratelimit.Limiter) {
+	h.ingestLimiter = l
+}
+
+// SetMaxUploadSize sets the maximum body size of file uploads (imports and restores) in bytes.
+func (h *DataServiceServer) SetMaxUploadSize(n int64) {
+	h.maxUpload = n
+}
+
+// uploadBody returns the request body, limited to the maximum upload size.
+func (h *DataServiceServer) uploadBody(c *gin.Context) io.Reader {
+	if h.maxUpload <= 0 {
+		return c.Request.Body
+	}
+	return http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUpload)
+}
+
+// tenantService returns the service scoped to the tenant of the authenticated caller.
+func (h *DataServiceServer) tenantService(c *gin.Context) *service.DataService {
+	value, _ := c.Get(principalKey)
+	principal, _ := value.(*auth.Principal)
+
+	return h.service.ForTenant(auth.TenantOf(principal))
+}
+
+// parseFilter builds a filter from the `series` and repeated `label` query parameters.
+func parseFilter(c *gin.Context) (*models.Filter, error) {
+	filter := models.Filter{Series: c.Query("series")}
+	for _, s := range c.QueryArray("label") {
+		m, err := models.ParseLabelMatcher(s)
+		if err != nil {
+			return nil, err
+		}
+		filter.Matchers = append(filter.Matchers, m)
+	}
+	return &filter, nil
+}
+
+// parseRangeQuery parses the `from` and `to` timestamps and the series and label filter of a range query.
+// Writes the 400 response and returns false if a parameter is invalid.
+func (h *DataServiceServer) parseRangeQuery(c *gin.Context) (int64, int64, *models.Filter, bool) {
+	from, err := api.ParseTimestamp(c.Query("from"), h.precision)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from: " + err.Error()})
+		return 0, 0, nil, false
+	}
+
+	to, err := api.ParseTimestamp(c.Query("to"), h.precision)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to: " + err.Error()})
+		return 0, 0, nil, false
+	}
+
+	filter, err := parseFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, 0, nil, false
+	}
+
+	return from, to, filter, true
 }
 
 // GetByID godoc
 // @Summary      Get data by ID
 // @Description  get data by UUID
 // @Tags         data
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
 // @Param        id   path      string  true  "Data ID"
+// @Param        ts_format  query  string  false  "Set to rfc3339 to render ts as an RFC3339 string"
 // @Success      200  {object}  models.Data
 // @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
 // @Failure      404  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /data/{id} [get]
@@ -43,7 +121,7 @@ func (h *DataServiceServer) GetByID(c *gin.Context) {
 		return
 	}
 
-	data, err := h.service.GetByID(id)
+	data, err := h.tenantService(c).GetByID(id)
 	switch {
 	case errors.Is(err, repository.ErrNotFound):
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
@@ -56,35 +134,128 @@ func (h *DataServiceServer) GetByID(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, data)
+	c.JSON(http.StatusOK, h.renderData(c, []*models.Data{data})[0])
+}
+
+// BatchGetRequest is the body of a batch lookup by IDs.
+type BatchGetRequest struct {
+	IDs []string `json:"ids" binding:"required"` // Record UUIDs, at most 1000
+}
+
+// BatchGetResponse holds the records found by a batch lookup and the IDs without a record.
+type BatchGetResponse struct {
+	Data    []dataView `json:"data"`    // Records found, in request order
+	Missing []string   `json:"missing"` // IDs without a record, in request order
+}
+
+// BatchGet godoc
+// @Summary      Get data by IDs
+// @Description  get several data records by UUID in one call
+// @Tags         data
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Accept       json
+// @Param        request    body   BatchGetRequest  true   "IDs"
+// @Param        ts_format  query  string  false  "Set to rfc3339 to render ts as an RFC3339 string"
+// @Success      200  {object}  BatchGetResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /data:batchGet [post]
+// BatchGet handles POST requests to fetch several data items by their UUIDs.
+// Responds with 400 if the body or any UUID is invalid or there are too many IDs, or 500 for internal errors.
+func (h *DataServiceServer) BatchGet(c *gin.Context) {
+	var req BatchGetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+
+	ids := make([]uuid.UUID, len(req.IDs))
+	for i, idStr := range req.IDs {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid UUID " + idStr})
+			return
+		}
+		ids[i] = id
+	}
+
+	data, missing, err := h.tenantService(c).GetByIDs(ids)
+	switch {
+	case errors.Is(err, service.ErrBatchTooLarge):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	found := make([]*models.Data, len(data))
+	for i := range data {
+		found[i] = &data[i]
+	}
+
+	resp := BatchGetResponse{Data: h.renderData(c, found), Missing: make([]string, len(missing))}
+	for i, id := range missing {
+		resp.Missing[i] = id.String()
+	}
+
+	c.JSON(http.StatusOK, resp)
 }
 
 // ListByTimeRange godoc
 // @Summary      List data by time range
 // @Description  get data by time range
 // @Tags         data
-// @Param        from  query     int64  true  "From timestamp"
-// @Param        to    query     int64  true  "To timestamp"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        from  query     string  true  "From timestamp: RFC3339, unit-suffixed (e.g. 1704067200s, 1704067200000ms) or integer in the declared precision"
+// @Param        to    query     string  true  "To timestamp: RFC3339, unit-suffixed (e.g. 1704153600s, 1704153600000ms) or integer in the declared precision"
+// @Param        ts_format  query  string  false  "Set to rfc3339 to render ts as an RFC3339 string"
+// @Param        series  query   string  false  "Series name"
+// @Param        label   query   []string  false  "Label matchers (name=value, name!=value, name=~regexp, name!~regexp)"  collectionFormat(multi)
+// @Param        format  query   string  false  "json (default), csv, ndjson or parquet; overrides the Accept header"
+// @Produce      json
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Produce      application/vnd.apache.parquet
 // @Success      200  {array}   models.Data
 // @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
 // @Failure      404  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /data [get]
 // ListByTimeRange handles GET requests to fetch data items within a specified time range.
-// Responds with 400 if parameters are invalid, 404 if no data found, or 500 for internal errors.
+// The response format is negotiated by the `format` parameter or the Accept header; CSV, NDJSON and Parquet
+// exports are streamed, see export.
+// Responds with 400 if parameters are invalid or the range exceeds the maximum query span, 404 if no data found,
+// or 500 for internal errors.
 func (h *DataServiceServer) ListByTimeRange(c *gin.Context) {
-	fromStr := c.Query("from")
-	toStr := c.Query("to")
-	from, err1 := strconv.ParseInt(fromStr, 10, 64)
-	to, err2 := strconv.ParseInt(toStr, 10, 64)
+	from, to, filter, ok := h.parseRangeQuery(c)
+	if !ok {
+		return
+	}
 
-	if err1 != nil || err2 != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from/to"})
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+	if format != export.FormatJSON {
+		h.export(c, format, from, to, filter)
 		return
 	}
 
-	data, err := h.service.ListByPeriod(from, to)
+	data, err := h.tenantService(c).ListByPeriod(from, to, filter, models.ListOptions{})
 	switch {
+	case errors.Is(err, service.ErrRangeTooLarge), errors.Is(err, service.ErrInvalidQuery):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+
 	case errors.Is(err, repository.ErrNotFound):
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
@@ -96,5 +267,161 @@ func (h *DataServiceServer) ListByTimeRange(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, data)
+	views := make([]*models.Data, len(data))
+	for i := range data {
+		views[i] = &data[i]
+	}
+	c.JSON(http.StatusOK, h.renderData(c, views))
+}
+
+// IngestPacks godoc
+// @Summary      Ingest packs
+// @Description  submit raw packs for aggregation and storage
+// @Tags         data
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Accept       json
+// @Param        packs  body      []models.Pack  true  "Packs, ts in the declared precision"
+// @Param        ts_format  query  string  false  "Set to rfc3339 to render ts as an RFC3339 string"
+// @Success      201    {array}   models.Data
+// @Failure      400    {object}  map[string]string
+// @Failure      401    {object}  map[string]string
+// @Failure      403    {object}  map[string]string
+// @Failure      429    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /packs [post]
+// IngestPacks handles POST requests with a JSON array of packs.
+// Responds with 400 if the body or any pack is invalid, or 500 for internal errors.
+func (h *DataServiceServer) IngestPacks(c *gin.Context) {
+	var packs []models.Pack
+	if err := c.ShouldBindJSON(&packs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+
+	svc := h.tenantService(c)
+	stored := make([]*models.Data, 0, len(packs))
+	for i := range packs {
+		if len(packs[i].Data) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "pack data is empty", "stored": h.renderData(c, stored)})
+			return
+		}
+
+		ts, err := h.precision.ToStored(packs[i].Timestamp)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "stored": h.renderData(c, stored)})
+			return
+		}
+		packs[i].Timestamp = ts
+
+		data, err := svc.Ingest(&packs[i])
+		if errors.Is(err, service.ErrInvalidPack) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "stored": h.renderData(c, stored)})
+			return
+		}
+		if err != nil {
+
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error", "stored": h.renderData(c, stored)})
+			return
+		}
+		stored = append(stored, data)
+	}
+
+	c.JSON(http.StatusCreated, h.renderData(c, stored))
+}
+
+// Delete godoc
+// @Summary      Delete data by ID
+// @Description  delete a data record by UUID
+// @Tags         data
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Data ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /data/{id} [delete]
+// Delete handles DELETE requests to remove a data item by its UUID.
+// Responds with 400 if the UUID is invalid, 404 if not found, or 500 for internal errors.
+func (h *DataServiceServer) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid UUID"})
+		return
+	}
+
+	err = h.tenantService(c).Delete(id)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteByTimeRange godoc
+// @Summary      Delete data by time range
+// @Description  delete all data records of the caller's tenant within a time range (admin)
+// @Tags         admin
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        from  query     string  true  "From timestamp (inclusive): RFC3339, unit-suffixed or integer in the declared precision"
+// @Param        to    query     string  true  "To timestamp (inclusive): RFC3339, unit-suffixed or integer in the declared precision"
+// @Success      200  {object}  map[string]int64
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/data [delete]
+// DeleteByTimeRange handles DELETE requests to remove all data items within a time range.
+// Responds with 400 if parameters are invalid, or 500 with the number of removed items for internal errors.
+func (h *DataServiceServer) DeleteByTimeRange(c *gin.Context) {
+	from, err := api.ParseTimestamp(c.Query("from"), h.precision)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from: " + err.Error()})
+		return
+	}
+
+	to, err := api.ParseTimestamp(c.Query("to"), h.precision)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to: " + err.Error()})
+		return
+	}
+
+	deleted, err := h.tenantService(c).DeleteByPeriod(from, to)
+	switch {
+	case errors.Is(err, service.ErrInvalidQuery):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error", "deleted": deleted})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deleted": deleted})
+}
+
+// Stats godoc
+// @Summary      Get tenant stats
+// @Description  get ingestion and query counters of the caller's tenant
+// @Tags         data
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Success      200  {object}  metrics.TenantCounters
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Router       /stats [get]
+// Stats handles GET requests to fetch the counters of the caller's tenant.
+func (h *DataServiceServer) Stats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.tenantService(c).Stats())
 }
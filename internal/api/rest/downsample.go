@@ -0,0 +1,73 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"xis-data-aggregator/internal/downsample"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Downsample godoc
+// @Summary      Downsample data by time range
+// @Description  get at most `points` records per series within a time range, selected by Largest-Triangle-Three-Buckets (lttb) or the minimum and maximum per time bucket (minmax)
+// @Tags         data
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        from  query     string  true  "From timestamp: RFC3339, unit-suffixed (e.g. 1704067200s, 1704067200000ms) or integer in the declared precision"
+// @Param        to    query     string  true  "To timestamp: RFC3339, unit-suffixed (e.g. 1704153600s, 1704153600000ms) or integer in the declared precision"
+// @Param        points  query   int     false  "Maximum records per series, 3 to 10000 (default 500)"
+// @Param        method  query   string  false  "lttb (default) or minmax"
+// @Param        ts_format  query  string  false  "Set to rfc3339 to render ts as an RFC3339 string"
+// @Param        series  query   string  false  "Series name"
+// @Param        label   query   []string  false  "Label matchers (name=value, name!=value, name=~regexp, name!~regexp)"  collectionFormat(multi)
+// @Success      200  {array}   models.Data
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /data/downsample [get]
+// Downsample handles GET requests to fetch a downsampled series within a specified time range.
+// Responds with an empty array if there are no records, 400 if parameters are invalid or the range exceeds the
+// maximum query span, or 500 for internal errors.
+func (h *DataServiceServer) Downsample(c *gin.Context) {
+	from, to, filter, ok := h.parseRangeQuery(c)
+	if !ok {
+		return
+	}
+
+	points := downsample.DefaultPoints
+	if s := c.Query("points"); s != "" {
+		var err error
+		if points, err = strconv.Atoi(s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid points"})
+			return
+		}
+	}
+
+	method, err := downsample.ParseMethod(c.Query("method"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	data, err := h.tenantService(c).Downsample(from, to, filter, method, points)
+	switch {
+	case errors.Is(err, service.ErrRangeTooLarge), errors.Is(err, service.ErrInvalidQuery):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	views := make([]*models.Data, len(data))
+	for i := range data {
+		views[i] = &data[i]
+	}
+	c.JSON(http.StatusOK, h.renderData(c, views))
+}
@@ -0,0 +1,86 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"xis-data-aggregator/internal/export"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+)
+
+// exportFormat returns the response format of a range query: the `format` query parameter if set,
+// otherwise the Accept header. Writes the 400 response and returns false if the parameter is invalid.
+func exportFormat(c *gin.Context) (export.Format, bool) {
+	if s := c.Query("format"); s != "" {
+		f, err := export.ParseFormat(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return "", false
+		}
+		return f, true
+	}
+	return export.FromAccept(c.GetHeader("Accept")), true
+}
+
+// export streams the records within [from, to] matching the filter in a streamed format. Records are encoded
+// and flushed batch by batch with chunked transfer encoding, so the range is never held in memory. An empty
+// range is a valid empty export. An error before the first batch is a JSON error response; a later one aborts
+// the connection so the client sees a truncated transfer rather than a complete file.
+func (h *DataServiceServer) export(c *gin.Context, format export.Format, from, to int64, filter *models.Filter) {
+	enc, err := export.NewEncoder(format, c.Writer, h.timestampRenderer(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	started := false
+	start := func() {
+		if started {
+			return
+		}
+		started = true
+		c.Header("Content-Type", format.ContentType())
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="data-%d-%d.%s"`, from, to, format))
+		c.Status(http.StatusOK)
+	}
+
+	err = h.tenantService(c).Scan(from, to, filter, func(batch []models.Data) error {
+		start()
+		if err := enc.Encode(batch); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err == nil {
+		start()
+		err = enc.Close()
+	}
+
+	switch {
+	case err == nil:
+		c.Writer.Flush()
+	case started:
+		glog.Errorf("Export of [%d, %d] aborted: %v", from, to, err)
+		abortStream(c)
+	case errors.Is(err, service.ErrRangeTooLarge), errors.Is(err, service.ErrInvalidQuery):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		glog.Errorf("Export of [%d, %d] error: %v", from, to, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+// abortStream closes the connection of a streamed response without completing the transfer.
+func abortStream(c *gin.Context) {
+	conn, _, err := c.Writer.Hijack()
+	if err != nil {
+		glog.Errorf("Export connection abort error: %v", err)
+		return
+	}
+	conn.Close()
+}
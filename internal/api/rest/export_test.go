@@ -0,0 +1,110 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/repository/repotest"
+	"xis-data-aggregator/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestExportServer starts an HTTP server with the range query route over records of series cpu and mem.
+func newTestExportServer(t *testing.T) string {
+	svc := service.NewDataService(repotest.New(t))
+	svc.SetMaxQuerySpan(time.Second.Microseconds())
+	for i := int64(1); i <= 3; i++ {
+		_, err := svc.Ingest(&models.Pack{Timestamp: i * 1000, Series: "cpu", Labels: map[string]string{"host": "a"}, Data: models.IntValues([]int64{i})})
+		require.NoError(t, err)
+	}
+	_, err := svc.Ingest(&models.Pack{Timestamp: 2500, Series: "mem", Data: models.IntValues([]int64{7})})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/data", NewDataServiceServer(svc, models.PrecisionMilliseconds).ListByTimeRange)
+
+	ts := httptest.NewServer(r)
+	t.Cleanup(ts.Close)
+	return ts.URL
+}
+
+func get(t *testing.T, url, accept string) (*http.Response, []byte) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, body
+}
+
+func TestExport(t *testing.T) {
+	base := newTestExportServer(t) + "/data?series=cpu"
+	url := base + "&from=0&to=10"
+
+	// CSV by format parameter over the Accept header, streamed without a content length
+	resp, body := get(t, url+"&format=csv", "application/json")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, []string{"chunked"}, resp.TransferEncoding)
+	rows, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	assert.Equal(t, []string{"id", "ts", "max", "tenant", "series", "labels", "anomaly_score", "anomalous"}, rows[0])
+	assert.Equal(t, []string{"1", "1", "", "cpu", `{"host":"a"}`, "0", "false"}, rows[1][1:])
+
+	// NDJSON by Accept header, timestamps in the requested format
+	resp, body = get(t, url+"&ts_format=rfc3339", "application/x-ndjson")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[2], `"ts":"1970-01-01T00:00:00.003Z"`)
+
+	// Parquet with typed timestamps
+	resp, body = get(t, url, "application/vnd.apache.parquet")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	records, err := parquet.Read[struct {
+		Timestamp int64             `parquet:"ts"`
+		Max       float64           `parquet:"max"`
+		Labels    map[string]string `parquet:"labels"`
+	}](bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, int64(3000), records[2].Timestamp)
+	assert.Equal(t, 3.0, records[2].Max)
+	assert.Equal(t, map[string]string{"host": "a"}, records[2].Labels)
+
+	// An empty range is an empty export
+	resp, body = get(t, base+"&format=csv&from=100&to=200", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "id,ts,max,tenant,series,labels,anomaly_score,anomalous\n", string(body))
+
+	resp, _ = get(t, url+"&format=xml", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, _ = get(t, base+"&from=200&to=100", "text/csv")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, body = get(t, base+"&format=ndjson&from=0&to=2000", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "wider than the maximum query span")
+	assert.Contains(t, string(body), "exceeds")
+
+	// JSON stays the default
+	resp, _ = get(t, url, "*/*")
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+}
@@ -0,0 +1,27 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+)
+
+// Health godoc
+// @Summary      Health check
+// @Description  check that the service and its repository respond; no credentials required
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Router       /health [get]
+// Health handles GET requests for the service health.
+// Responds with {"status": "ok"}, or 503 with {"status": "unavailable"} if the repository does not respond.
+func (h *DataServiceServer) Health(c *gin.Context) {
+	if err := h.service.Health(); err != nil {
+		glog.Errorf("Health check error: %v", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -0,0 +1,141 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"xis-data-aggregator/internal/importer"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+)
+
+// errImportRateLimited stops an import whose client is over the ingest rate limit.
+var errImportRateLimited = errors.New("rate limit exceeded")
+
+// importStatus is a line of the import response: the progress after a stored batch, the final progress
+// (Done) or the error that stopped the import.
+type importStatus struct {
+	importer.Progress
+	DryRun bool   `json:"dry_run,omitempty"`
+	Done   bool   `json:"done,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// importFormat returns the format of an import body: the `format` query parameter if set,
+// otherwise the Content-Type (text/csv or application/x-ndjson).
+func importFormat(c *gin.Context) (importer.Format, error) {
+	if s := c.Query("format"); s != "" {
+		return importer.ParseFormat(s)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		return importer.FormatCSV, nil
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return importer.FormatNDJSON, nil
+	}
+	return importer.ParseFormat(mediaType)
+}
+
+// Import godoc
+// @Summary      Import packs from a file
+// @Description  bulk import of historical packs from a CSV file (header row with ts, data as ';'-separated samples and optionally id, type, series, labels as a JSON object) or NDJSON (one pack per line). Invalid records are reported and skipped. The response streams one progress line per stored batch; `records` of the last one is the checkpoint to resume from.
+// @Tags         data
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Accept       text/csv
+// @Accept       application/x-ndjson
+// @Produce      application/x-ndjson
+// @Param        file        body   string  true   "Pack file, ts in the declared precision"
+// @Param        format      query  string  false  "csv or ndjson, overrides the Content-Type"
+// @Param        dry_run     query  bool    false  "Validate the packs without storing them"
+// @Param        checkpoint  query  int     false  "Records of the file already imported, skipped"
+// @Param        batch_size  query  int     false  "Packs stored per batch, 1 to 5000 (default 500)"
+// @Success      200  {array}   importStatus
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Router       /import [post]
+// Import handles POST requests with a Pack file. Responds with 400 if parameters are invalid; later errors,
+// e.g. a malformed CSV header, a failed batch write, a body above the upload limit or a client over the ingest
+// rate limit, end the progress stream with an error line.
+func (h *DataServiceServer) Import(c *gin.Context) {
+	format, err := importFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+		return
+	}
+
+	var checkpoint int64
+	if s := c.Query("checkpoint"); s != "" {
+		if checkpoint, err = strconv.ParseInt(s, 10, 64); err != nil || checkpoint < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid checkpoint"})
+			return
+		}
+	}
+
+	batchSize := importer.DefaultBatchSize
+	if s := c.Query("batch_size"); s != "" {
+		if batchSize, err = strconv.Atoi(s); err != nil || batchSize < 1 || batchSize > importer.MaxBatchSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid batch_size"})
+			return
+		}
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+	enc := json.NewEncoder(c.Writer)
+	write := func(status importStatus) {
+		status.DryRun = dryRun
+		if err := enc.Encode(status); err != nil {
+			glog.Errorf("Import progress write error: %v", err)
+		}
+		c.Writer.Flush()
+	}
+
+	// Every stored batch takes an ingest token like a request; over the limit the import stops at the
+	// checkpoint of the batch, to be resumed later
+	key := clientKey(c)
+	svc := h.tenantService(c)
+	progress, err := importer.Import(h.uploadBody(c), svc.NewImport(dryRun), importer.Options{
+		Format:     format,
+		Precision:  h.precision,
+		BatchSize:  batchSize,
+		Checkpoint: checkpoint,
+		Progress: func(p importer.Progress) error {
+			write(importStatus{Progress: p})
+			if ok, retryAfter := h.ingestLimiter.Allow(key); !ok {
+				return fmt.Errorf("%w, retry after %d ms", errImportRateLimited, retryAfter.Milliseconds())
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		glog.Errorf("Import of tenant %q stopped at record %d: %v", svc.Tenant(), progress.Records, err)
+		msg := err.Error()
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.Is(err, importer.ErrStore):
+			msg = "internal server error"
+		case errors.As(err, &tooLarge):
+			msg = fmt.Sprintf("file larger than %d bytes, import the rest from the checkpoint", tooLarge.Limit)
+		}
+		write(importStatus{Progress: progress, Error: msg})
+		return
+	}
+
+	glog.Infof("Import of tenant %q done: %d imported, %d failed, dry run %v", svc.Tenant(), progress.Imported, progress.Failed, dryRun)
+	write(importStatus{Progress: progress, Done: true})
+}
@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"xis-data-aggregator/internal/importer"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/ratelimit"
+	"xis-data-aggregator/internal/repository/repotest"
+	"xis-data-aggregator/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImport(t *testing.T) {
+	svc := service.NewDataService(repotest.New(t))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	server := NewDataServiceServer(svc, models.PrecisionMilliseconds)
+	r.POST("/import", server.Import)
+	ts := httptest.NewServer(r)
+	t.Cleanup(ts.Close)
+
+	post := func(query, contentType, body string) (*http.Response, []importStatus) {
+		resp, err := http.Post(ts.URL+"/import"+query, contentType, strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var lines []importStatus
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			var status importStatus
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &status))
+			lines = append(lines, status)
+		}
+		return resp, lines
+	}
+
+	file := "{\"ts\":1,\"data\":[1]}\n{\"ts\":2,\"data\":[]}\n{\"ts\":3,\"data\":[3]}\n"
+
+	// A dry run reports the invalid record and stores nothing
+	resp, lines := post("?dry_run=true&batch_size=1", "application/x-ndjson", file)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, lines, 4, "a line per batch and the final one")
+	last := lines[3]
+	assert.True(t, last.Done && last.DryRun)
+	assert.Equal(t, int64(2), last.Imported)
+	assert.Equal(t, []importer.RecordError{{Record: 2, Error: "invalid pack: pack data is empty"}}, last.Errors)
+	_, err := svc.ListByPeriod(0, 10_000, nil, models.ListOptions{})
+	assert.Error(t, err, "nothing stored")
+
+	// Resume after the first record
+	_, lines = post("?format=ndjson&checkpoint=1", "", file)
+	last = lines[len(lines)-1]
+	assert.Equal(t, int64(1), last.Skipped)
+	assert.Equal(t, int64(1), last.Imported)
+	data, err := svc.ListByPeriod(0, 10_000, nil, models.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, data, 1)
+	assert.Equal(t, int64(3000), data[0].Timestamp)
+
+	// A malformed file ends the stream with an error
+	_, lines = post("", "text/csv", "series\ncpu\n")
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0].Error, "invalid CSV header")
+
+	// Batches are charged to the ingest limiter, over the limit the import stops at the checkpoint
+	server.SetIngestLimiter(ratelimit.NewLimiter(0.001, 1))
+	_, lines = post("?batch_size=1", "application/x-ndjson", file)
+	require.Len(t, lines, 3)
+	assert.Equal(t, int64(2), lines[1].Records)
+	assert.Contains(t, lines[2].Error, "rate limit exceeded")
+	assert.Equal(t, int64(2), lines[2].Records)
+	server.SetIngestLimiter(nil)
+
+	// Bodies above the upload limit end the stream with an error
+	server.SetMaxUploadSize(int64(len(file) - 1))
+	_, lines = post("?batch_size=1", "application/x-ndjson", file)
+	assert.Contains(t, lines[len(lines)-1].Error, "file larger than")
+	server.SetMaxUploadSize(0)
+
+	resp, _ = post("", "application/xml", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, _ = post("?batch_size=0", "text/csv", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
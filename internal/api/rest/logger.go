@@ -0,0 +1,46 @@
+package rest
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// accessTokenParam matches the value of the "access_token" query parameter accepted by QueryTokenMiddleware.
+var accessTokenParam = regexp.MustCompile(`([?&]access_token=)[^&]*`)
+
+// LoggerMiddleware returns the Gin access logger with the format of gin.Logger, except that the value of the
+// "access_token" query parameter is redacted, so that live feed credentials don't end up in the access logs.
+func LoggerMiddleware() gin.HandlerFunc {
+	return gin.LoggerWithConfig(gin.LoggerConfig{Formatter: logFormatter})
+}
+
+// logFormatter formats an access log line like the default Gin formatter, with the access token redacted.
+func logFormatter(param gin.LogFormatterParams) string {
+	var statusColor, methodColor, resetColor string
+	if param.IsOutputColor() {
+		statusColor = param.StatusCodeColor()
+		methodColor = param.MethodColor()
+		resetColor = param.ResetColor()
+	}
+
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency.Truncate(time.Second)
+	}
+	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		statusColor, param.StatusCode, resetColor,
+		param.Latency,
+		param.ClientIP,
+		methodColor, param.Method, resetColor,
+		redactAccessToken(param.Path),
+		param.ErrorMessage,
+	)
+}
+
+// redactAccessToken replaces the value of the "access_token" query parameter of a request path.
+func redactAccessToken(path string) string {
+	return accessTokenParam.ReplaceAllString(path, "${1}REDACTED")
+}
@@ -0,0 +1,24 @@
+package rest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRedactAccessToken tests that only the value of the access_token query parameter is redacted.
+func TestRedactAccessToken(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/v1/data/stream", "/api/v1/data/stream"},
+		{"/api/v1/data/stream?access_token=secret", "/api/v1/data/stream?access_token=REDACTED"},
+		{"/api/v1/data/ws?series=cpu&access_token=secret&buffer=10", "/api/v1/data/ws?series=cpu&access_token=REDACTED&buffer=10"},
+		{"/api/v1/data/ws?my_access_token=x", "/api/v1/data/ws?my_access_token=x"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, redactAccessToken(tt.path), tt.path)
+	}
+}
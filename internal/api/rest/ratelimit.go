@@ -0,0 +1,43 @@
+package rest
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"xis-data-aggregator/internal/auth"
+	"xis-data-aggregator/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitMiddleware returns a Gin middleware that applies the limiter per client.
+// Clients are keyed by the authenticated principal (see AuthMiddleware) or, if none, by client IP.
+// Responds with 429 and a Retry-After header when the client bucket is empty.
+// A nil limiter disables the check.
+func RateLimitMiddleware(l *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l == nil {
+			c.Next()
+			return
+		}
+
+		if ok, retryAfter := l.Allow(clientKey(c)); !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":          "rate limit exceeded",
+				"retry_after_ms": retryAfter.Milliseconds(),
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// clientKey identifies the caller by the authenticated principal or, if none, by client IP.
+func clientKey(c *gin.Context) string {
+	if p, ok := c.Get(principalKey); ok {
+		return p.(*auth.Principal).Subject
+	}
+	return "ip:" + c.ClientIP()
+}
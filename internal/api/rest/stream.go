@@ -0,0 +1,328 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"xis-data-aggregator/internal/hub"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/service"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// replayLimit is the maximum number of missed records replayed on resume.
+	replayLimit = 10000
+	// heartbeatInterval is the interval of keep-alive messages on idle live feeds.
+	heartbeatInterval = 15 * time.Second
+	// wsWriteTimeout bounds a single WebSocket write so that a stuck client can't hold the feed.
+	wsWriteTimeout = 10 * time.Second
+)
+
+// upgrader upgrades live feed requests to WebSocket connections. Cross-origin requests are rejected.
+var upgrader = websocket.Upgrader{}
+
+// wsMessage is a record sent over the WebSocket feed.
+type wsMessage struct {
+	ID   string   `json:"id"`   // Event ID, pass as `last_event_id` to resume
+	Data dataView `json:"data"` // Record
+}
+
+// eventID returns the resumable event ID of a record: "<stored timestamp>:<record ID>".
+func eventID(data *models.Data) string {
+	return strconv.FormatInt(data.Timestamp, 10) + ":" + data.ID.String()
+}
+
+// parseEventID parses an event ID created by eventID.
+func parseEventID(s string) (int64, uuid.UUID, error) {
+	tsStr, idStr, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, uuid.Nil, fmt.Errorf("invalid event ID %q", s)
+	}
+
+	ts, err := strconv.ParseInt(tsStr, 10, 64)
+	if err != nil {
+		return 0, uuid.Nil, fmt.Errorf("invalid event ID %q", s)
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return 0, uuid.Nil, fmt.Errorf("invalid event ID %q", s)
+	}
+
+	return ts, id, nil
+}
+
+// liveFeed is a live subscription preceded by the stored records missed since the last event.
+type liveFeed struct {
+	sub      *hub.Subscription
+	replay   []models.Data      // Missed records, oldest first
+	replayed map[uuid.UUID]bool // Replayed record IDs, skipped if they are also delivered live
+}
+
+// openFeed subscribes to the records of the caller's tenant matching the `series`, `label` and `min_max`
+// query parameters, with the `policy` (drop or disconnect) and `buffer` parameters. If lastEventID is set,
+// the records stored since that event are loaded from the time range index for replay; if more than
+// replayLimit were missed, responds with 409 so that the client re-syncs with a range query.
+// Writes the error response and returns nil if the request is invalid or subscriptions are disabled.
+func (h *DataServiceServer) openFeed(c *gin.Context, lastEventID string) *liveFeed {
+	filter, err := parseFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil
+	}
+
+	hubFilter := hub.Filter{Data: filter}
+	if s := c.Query("min_max"); s != "" {
+		minMax, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid min_max"})
+			return nil
+		}
+		hubFilter.MinMax = &minMax
+	}
+
+	var policy hub.Policy
+	switch c.DefaultQuery("policy", "drop") {
+	case "drop":
+		policy = hub.PolicyDrop
+	case "disconnect":
+		policy = hub.PolicyDisconnect
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid policy, expected drop or disconnect"})
+		return nil
+	}
+
+	buffer, err := strconv.Atoi(c.DefaultQuery("buffer", "0"))
+	if err != nil || buffer < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid buffer"})
+		return nil
+	}
+
+	var lastTs int64
+	var lastID uuid.UUID
+	if lastEventID != "" {
+		if lastTs, lastID, err = parseEventID(lastEventID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil
+		}
+	}
+
+	svc := h.tenantService(c)
+
+	// Subscribe before loading the replay so that no record falls in between
+	sub, err := svc.Subscribe(hubFilter, policy, buffer)
+	switch {
+	case errors.Is(err, service.ErrNoHub):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return nil
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return nil
+	}
+
+	feed := liveFeed{sub: sub, replayed: make(map[uuid.UUID]bool)}
+	if lastEventID == "" {
+		return &feed
+	}
+
+	missed, err := svc.ListSince(lastTs, filter, replayLimit)
+	switch {
+	case errors.Is(err, service.ErrReplayTooLong):
+		sub.Close()
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error() + ", re-sync with a range query and reconnect without the event ID"})
+		return nil
+	case err != nil:
+		sub.Close()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return nil
+	}
+
+	for _, data := range missed {
+		if data.ID == lastID || (hubFilter.MinMax != nil && data.Max.Float64() < *hubFilter.MinMax) {
+			continue
+		}
+		feed.replay = append(feed.replay, data)
+		feed.replayed[data.ID] = true
+	}
+
+	return &feed
+}
+
+// run sends the replayed records and then the live records until the context is done, the subscription
+// ends, or sending fails. ping is called on idle feeds; ended is called if the hub ended the subscription.
+func (f *liveFeed) run(ctx context.Context, send func(*models.Data) error, ping func() error, ended func(error)) {
+	for i := range f.replay {
+		if err := send(&f.replay[i]); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-f.sub.Done():
+			ended(f.sub.Err())
+			return
+
+		case data := <-f.sub.Events():
+			if f.replayed[data.ID] {
+				continue
+			}
+			if err := send(&data); err != nil {
+				return
+			}
+
+		case <-heartbeat.C:
+			if err := ping(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Stream godoc
+// @Summary      Live feed (Server-Sent Events)
+// @Description  stream records as they are stored, as `data` events with a resumable ID
+// @Tags         data
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Produce      text/event-stream
+// @Param        Last-Event-ID  header  string    false  "Resume after this event ID"
+// @Param        series         query   string    false  "Series name"
+// @Param        label          query   []string  false  "Label matchers (name=value, name!=value, name=~regexp, name!~regexp)"  collectionFormat(multi)
+// @Param        min_max        query   number    false  "Only records with max >= min_max"
+// @Param        policy         query   string    false  "Slow consumer policy: drop (default) or disconnect"
+// @Param        buffer         query   int       false  "Records buffered for the client, 0 - server maximum"
+// @Param        ts_format      query   string    false  "Set to rfc3339 to render ts as an RFC3339 string"
+// @Success      200  {object}  models.Data
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Router       /data/stream [get]
+// Stream handles GET requests for a Server-Sent Events feed of newly stored records.
+// Responds with 400 if parameters are invalid, 409 if too many records were missed since the last event,
+// or 503 if live subscriptions are disabled.
+func (h *DataServiceServer) Stream(c *gin.Context) {
+	feed := h.openFeed(c, c.GetHeader("Last-Event-ID"))
+	if feed == nil {
+		return
+	}
+	defer feed.sub.Close()
+
+	c.Header("Content-Type", sse.ContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // disable proxy buffering
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	write := func(event sse.Event) error {
+		if err := sse.Encode(c.Writer, event); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+
+	feed.run(c.Request.Context(),
+		func(data *models.Data) error {
+			return write(sse.Event{Id: eventID(data), Event: "data", Data: h.renderData(c, []*models.Data{data})[0]})
+		},
+		func() error {
+			if _, err := c.Writer.WriteString(": ping\n\n"); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+			return nil
+		},
+		func(err error) {
+			_ = write(sse.Event{Event: "error", Data: gin.H{"error": err.Error()}})
+		},
+	)
+
+	glog.Infof("SSE feed ended, %d records dropped", feed.sub.Dropped())
+}
+
+// WebSocket godoc
+// @Summary      Live feed (WebSocket)
+// @Description  stream records as they are stored, as JSON messages {"id": event ID, "data": record}
+// @Tags         data
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        last_event_id  query   string    false  "Resume after this event ID"
+// @Param        series         query   string    false  "Series name"
+// @Param        label          query   []string  false  "Label matchers (name=value, name!=value, name=~regexp, name!~regexp)"  collectionFormat(multi)
+// @Param        min_max        query   number    false  "Only records with max >= min_max"
+// @Param        policy         query   string    false  "Slow consumer policy: drop (default) or disconnect"
+// @Param        buffer         query   int       false  "Records buffered for the client, 0 - server maximum"
+// @Param        ts_format      query   string    false  "Set to rfc3339 to render ts as an RFC3339 string"
+// @Success      101
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Router       /data/ws [get]
+// WebSocket handles GET requests for a WebSocket feed of newly stored records.
+// Responds with 400 if parameters are invalid, 409 if too many records were missed since the last event,
+// or 503 if live subscriptions are disabled.
+func (h *DataServiceServer) WebSocket(c *gin.Context) {
+	feed := h.openFeed(c, c.Query("last_event_id"))
+	if feed == nil {
+		return
+	}
+	defer feed.sub.Close()
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		glog.Errorf("WebSocket upgrade error: %v", err) // the upgrader has responded
+		return
+	}
+	defer conn.Close()
+
+	// Read (and discard) client messages to process control frames and notice when the client goes away
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	feed.run(ctx,
+		func(data *models.Data) error {
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			return conn.WriteJSON(wsMessage{ID: eventID(data), Data: h.renderData(c, []*models.Data{data})[0]})
+		},
+		func() error {
+			return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout))
+		},
+		func(err error) {
+			msg := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, err.Error())
+			_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsWriteTimeout))
+		},
+	)
+
+	glog.Infof("WebSocket feed ended, %d records dropped", feed.sub.Dropped())
+}
@@ -0,0 +1,152 @@
+package rest
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"xis-data-aggregator/internal/hub"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/repository/repotest"
+	"xis-data-aggregator/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestFeedServer starts an HTTP server with the live feed routes and returns its URL.
+func newTestFeedServer(t *testing.T) (string, *service.DataService, *hub.Hub) {
+	svc := service.NewDataService(repotest.New(t))
+	h := hub.New(8)
+	svc.SetHub(h)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	server := NewDataServiceServer(svc, models.PrecisionMicroseconds)
+	r.GET("/data/stream", server.Stream)
+	r.GET("/data/ws", server.WebSocket)
+
+	ts := httptest.NewServer(r)
+	t.Cleanup(ts.Close)
+
+	return ts.URL, svc, h
+}
+
+func TestParseEventID(t *testing.T) {
+	data := models.Data{ID: [16]byte{1}, Timestamp: 1640995200000000}
+	ts, id, err := parseEventID(eventID(&data))
+	require.NoError(t, err)
+	assert.Equal(t, data.Timestamp, ts)
+	assert.Equal(t, data.ID, id)
+
+	for _, s := range []string{"", "123", "x:00000000-0000-0000-0000-000000000000", "123:not-a-uuid"} {
+		_, _, err := parseEventID(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestStreamResume(t *testing.T) {
+	url, svc, h := newTestFeedServer(t)
+
+	first, err := svc.Ingest(&models.Pack{Timestamp: 1000, Series: "cpu", Data: models.IntValues([]int64{1})})
+	require.NoError(t, err)
+	missed, err := svc.Ingest(&models.Pack{Timestamp: 2000, Series: "cpu", Data: models.IntValues([]int64{2})})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, url+"/data/stream?series=cpu", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", eventID(first))
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/event-stream")
+
+	require.Eventually(t, func() bool { return h.Len() == 1 }, time.Second, time.Millisecond)
+	live, err := svc.Ingest(&models.Pack{Timestamp: 3000, Series: "cpu", Data: models.IntValues([]int64{3})})
+	require.NoError(t, err)
+
+	// The missed record is replayed before the live one, the last seen record is not
+	var ids []string
+	scanner := bufio.NewScanner(resp.Body)
+	for len(ids) < 2 && scanner.Scan() {
+		if id, ok := strings.CutPrefix(scanner.Text(), "id:"); ok {
+			ids = append(ids, id)
+		}
+	}
+	assert.Equal(t, []string{eventID(missed), eventID(live)}, ids)
+}
+
+func TestStreamResumeTooFar(t *testing.T) {
+	url, svc, _ := newTestFeedServer(t)
+
+	first, err := svc.Ingest(&models.Pack{Timestamp: 1000, Series: "cpu", Data: models.IntValues([]int64{1})})
+	require.NoError(t, err)
+	for i := int64(0); i < replayLimit; i++ {
+		_, err = svc.Ingest(&models.Pack{Timestamp: 2000 + i, Series: "cpu", Data: models.IntValues([]int64{i})})
+		require.NoError(t, err)
+	}
+
+	// The last seen record and the missed ones exceed the replay limit
+	req, err := http.NewRequest(http.MethodGet, url+"/data/stream?series=cpu", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", eventID(first))
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
+
+func TestStreamBadRequest(t *testing.T) {
+	url, _, _ := newTestFeedServer(t)
+
+	for _, query := range []string{"policy=block", "buffer=-1", "min_max=x", "label=bad"} {
+		resp, err := http.Get(url + "/data/stream?" + query)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url+"/data/stream", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "bad")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestWebSocket(t *testing.T) {
+	url, svc, h := newTestFeedServer(t)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(url, "http")+"/data/ws?series=cpu", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Eventually(t, func() bool { return h.Len() == 1 }, time.Second, time.Millisecond)
+
+	_, err = svc.Ingest(&models.Pack{Series: "mem", Data: models.IntValues([]int64{1})}) // Other series
+	require.NoError(t, err)
+	data, err := svc.Ingest(&models.Pack{Series: "cpu", Data: models.IntValues([]int64{2})})
+	require.NoError(t, err)
+
+	var msg struct {
+		ID   string `json:"id"`
+		Data struct {
+			ID     string `json:"id"`
+			Series string `json:"series"`
+		} `json:"data"`
+	}
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	require.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, eventID(data), msg.ID)
+	assert.Equal(t, data.ID.String(), msg.Data.ID)
+	assert.Equal(t, "cpu", msg.Data.Series)
+
+	// The subscription is removed when the client goes away
+	conn.Close()
+	require.Eventually(t, func() bool { return h.Len() == 0 }, time.Second, time.Millisecond)
+}
@@ -0,0 +1,61 @@
+package rest
+
+import (
+	"xis-data-aggregator/internal/api"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tsFormatRFC3339 is the `ts_format` query value that renders response timestamps as RFC3339 strings.
+const tsFormatRFC3339 = "rfc3339"
+
+// dataView is the JSON rendering of models.Data with the timestamp in the requested format.
+type dataView struct {
+	models.Data
+	Timestamp interface{} `json:"ts"` // Integer in the declared precision or RFC3339 string, shadows Data.Timestamp
+}
+
+// renderData converts stored data for a response: `ts` is an integer in the declared precision,
+// or an RFC3339 string when the request has `ts_format=rfc3339`.
+func (h *DataServiceServer) renderData(c *gin.Context, data []*models.Data) []dataView {
+	ts := h.timestampRenderer(c)
+
+	views := make([]dataView, len(data))
+	for i, d := range data {
+		views[i].Data = *d
+		views[i].Timestamp = ts(d.Timestamp)
+	}
+	return views
+}
+
+// timestampRenderer returns the conversion of stored timestamps to the format requested by `ts_format`.
+func (h *DataServiceServer) timestampRenderer(c *gin.Context) func(int64) interface{} {
+	if c.Query("ts_format") == tsFormatRFC3339 {
+		return func(ts int64) interface{} { return api.FormatTimestamp(ts) }
+	}
+	return func(ts int64) interface{} { return h.precision.FromStored(ts) }
+}
+
+// windowView is the JSON rendering of models.WindowResult with the window bounds in the requested format.
+type windowView struct {
+	models.WindowResult
+	Start interface{} `json:"ts"`  // Integer in the declared precision or RFC3339 string, shadows WindowResult.Start
+	End   interface{} `json:"end"` // Same format as ts, shadows WindowResult.End
+}
+
+// renderWindows converts stored window results for a response like renderData converts records.
+func (h *DataServiceServer) renderWindows(c *gin.Context, windows []models.WindowResult) []windowView {
+	rfc3339 := c.Query("ts_format") == tsFormatRFC3339
+
+	views := make([]windowView, len(windows))
+	for i, w := range windows {
+		views[i].WindowResult = w
+		if rfc3339 {
+			views[i].Start, views[i].End = api.FormatTimestamp(w.Start), api.FormatTimestamp(w.End)
+		} else {
+			views[i].Start, views[i].End = h.precision.FromStored(w.Start), h.precision.FromStored(w.End)
+		}
+	}
+	return views
+}
@@ -0,0 +1,111 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"xis-data-aggregator/internal/service"
+	"xis-data-aggregator/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultDeliveriesLimit is the number of delivery attempts returned when no limit is given.
+const defaultDeliveriesLimit = 100
+
+// webhookError writes the response for an error of a webhook operation.
+func webhookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, webhook.ErrEndpointNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNoWebhooks):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+// ListWebhooks godoc
+// @Summary      List webhook endpoints
+// @Description  get the webhook endpoints of the caller's tenant, without secrets (admin)
+// @Tags         admin
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Success      200  {array}   models.WebhookEndpoint
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Router       /admin/webhooks [get]
+// ListWebhooks handles GET requests to fetch the webhook endpoints sorted by ID.
+func (h *DataServiceServer) ListWebhooks(c *gin.Context) {
+	endpoints, err := h.tenantService(c).Webhooks()
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoints)
+}
+
+// ListWebhookDeliveries godoc
+// @Summary      List webhook deliveries
+// @Description  get the most recent webhook delivery attempts of the caller's tenant, newest first (admin)
+// @Tags         admin
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        endpoint  query     string  false  "Only attempts to this endpoint ID"
+// @Param        limit     query     int     false  "Maximum number of attempts (default 100, at most 1000)"
+// @Success      200  {array}   models.WebhookDelivery
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Router       /admin/webhooks/deliveries [get]
+// ListWebhookDeliveries handles GET requests to fetch the delivery log. Responds with 400 if the limit is invalid.
+func (h *DataServiceServer) ListWebhookDeliveries(c *gin.Context) {
+	limit := defaultDeliveriesLimit
+	if s := c.Query("limit"); s != "" {
+		var err error
+		if limit, err = strconv.Atoi(s); err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+
+	deliveries, err := h.tenantService(c).WebhookDeliveries(c.Query("endpoint"), limit)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// TestWebhook godoc
+// @Summary      Test webhook endpoint
+// @Description  send a sample `test` event to the endpoint once, without retries, and return the attempt (admin)
+// @Tags         admin
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Endpoint ID"
+// @Success      200  {object}  models.WebhookDelivery
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Router       /admin/webhooks/{id}/test [post]
+// TestWebhook handles POST requests to send a test event. A failed delivery is reported in the attempt with 200.
+// Responds with 404 if there is no such endpoint.
+func (h *DataServiceServer) TestWebhook(c *gin.Context) {
+	delivery, err := h.tenantService(c).TestWebhook(c.Param("id"))
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
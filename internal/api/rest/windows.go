@@ -0,0 +1,61 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListWindows godoc
+// @Summary      List window results by time range
+// @Description  get the sliding and session window aggregates of the series starting within a time range, ordered by start
+// @Tags         data
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Param        from  query     string  true  "From window start: RFC3339, unit-suffixed (e.g. 1704067200s, 1704067200000ms) or integer in the declared precision"
+// @Param        to    query     string  true  "To window start: RFC3339, unit-suffixed (e.g. 1704153600s, 1704153600000ms) or integer in the declared precision"
+// @Param        kind  query     string  false  "Window kind: sliding or session"
+// @Param        ts_format  query  string  false  "Set to rfc3339 to render ts and end as RFC3339 strings"
+// @Param        series  query   string  false  "Series name"
+// @Param        label   query   []string  false  "Label matchers (name=value, name!=value, name=~regexp, name!~regexp)"  collectionFormat(multi)
+// @Success      200  {array}   models.WindowResult
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Router       /windows [get]
+// ListWindows handles GET requests to fetch the window results starting within a specified time range.
+// Responds with an empty array if there are none, 400 if parameters are invalid, 503 if window aggregation
+// is disabled, or 500 for internal errors.
+func (h *DataServiceServer) ListWindows(c *gin.Context) {
+	from, to, filter, ok := h.parseRangeQuery(c)
+	if !ok {
+		return
+	}
+
+	kind, err := models.ParseWindowKind(c.Query("kind"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	windows, err := h.tenantService(c).ListWindows(from, to, kind, filter, models.ListOptions{})
+	switch {
+	case errors.Is(err, service.ErrRangeTooLarge), errors.Is(err, service.ErrInvalidQuery):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrNoWindows):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.renderWindows(c, windows))
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"xis-data-aggregator/internal/models"
+)
+
+// ParseTimestamp parses a timestamp query parameter into stored units (see models.TimestampUnit).
+// Accepted forms are RFC3339 ("2024-01-02T15:04:05Z"), an integer with a unit suffix
+// ("1704207845s", "1704207845000ms", "...us", "...ns") or a plain integer in the declared precision.
+func ParseTimestamp(s string, precision models.TimestampPrecision) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty timestamp")
+	}
+
+	if strings.ContainsAny(s, "T:") {
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid RFC3339 timestamp %q", s)
+		}
+		return models.Timestamp(t), nil
+	}
+
+	// Split the unit suffix, if any
+	digits := strings.TrimRightFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if suffix := s[len(digits):]; suffix != "" {
+		p, err := models.ParseTimestampPrecision(suffix)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp unit in %q", s)
+		}
+		precision = p
+	}
+
+	ts, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+
+	return precision.ToStored(ts)
+}
+
+// FormatTimestamp formats a stored timestamp as RFC3339 in UTC.
+func FormatTimestamp(ts int64) string {
+	return models.TimeOf(ts).UTC().Format(time.RFC3339Nano)
+}
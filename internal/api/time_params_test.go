@@ -0,0 +1,50 @@
+// Package api contains tests for timestamp query parameter parsing.
+package api
+
+import (
+	"testing"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseTimestamp tests parsing RFC3339, unit-suffixed and plain integer timestamps into stored units.
+func TestParseTimestamp(t *testing.T) {
+	const stored = int64(1704067200_000_000) // 2024-01-01T00:00:00Z in Unix microseconds
+
+	// Define test cases for ParseTimestamp
+	tests := []struct {
+		name      string                    // Name of the test case
+		input     string                    // Query parameter value
+		precision models.TimestampPrecision // Declared precision
+		want      int64                     // Expected stored timestamp
+		wantErr   bool                      // Whether an error is expected
+	}{
+		{name: "RFC3339", input: "2024-01-01T00:00:00Z", precision: models.PrecisionSeconds, want: stored},
+		{name: "RFC3339 with offset", input: "2024-01-01T03:00:00+03:00", precision: models.PrecisionSeconds, want: stored},
+		{name: "RFC3339 fraction", input: "2024-01-01T00:00:00.5Z", precision: models.PrecisionSeconds, want: stored + 500_000},
+		{name: "Seconds suffix", input: "1704067200s", precision: models.PrecisionMicroseconds, want: stored},
+		{name: "Milliseconds suffix", input: "1704067200000ms", precision: models.PrecisionSeconds, want: stored},
+		{name: "Nanoseconds suffix", input: "1704067200000000000ns", precision: models.PrecisionSeconds, want: stored},
+		{name: "Plain seconds", input: "1704067200", precision: models.PrecisionSeconds, want: stored},
+		{name: "Plain microseconds", input: "1704067200000000", precision: models.PrecisionMicroseconds, want: stored},
+		{name: "Unknown suffix", input: "1704067200h", precision: models.PrecisionSeconds, wantErr: true},
+		{name: "Invalid RFC3339", input: "2024-01-01T25:00:00Z", precision: models.PrecisionSeconds, wantErr: true},
+		{name: "Empty", input: "", precision: models.PrecisionSeconds, wantErr: true},
+		{name: "Overflowing seconds", input: "9223372036854775", precision: models.PrecisionSeconds, wantErr: true},
+		{name: "Overflowing milliseconds suffix", input: "9223372036854776ms", precision: models.PrecisionSeconds, wantErr: true},
+		{name: "Largest milliseconds", input: "9223372036854775ms", precision: models.PrecisionSeconds, want: 9223372036854775000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTimestamp(tt.input, tt.precision)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,225 @@
+// Package auth provides API key and JWT authentication shared by the REST and gRPC APIs.
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"xis-data-aggregator/config"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Scope is a permission granted to an authenticated caller.
+type Scope string
+
+const (
+	// ScopeRead allows querying stored data.
+	ScopeRead Scope = "read"
+	// ScopeIngest allows submitting packs for processing.
+	ScopeIngest Scope = "ingest"
+	// ScopeDelete allows removing single records by ID.
+	ScopeDelete Scope = "delete"
+	// ScopeAdmin allows administrative operations such as range deletes.
+	ScopeAdmin Scope = "admin"
+)
+
+var (
+	// ErrUnauthenticated is returned when credentials are missing or invalid.
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrPermissionDenied is returned when valid credentials lack the required scope.
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// Principal describes an authenticated caller.
+type Principal struct {
+	Subject string  // API key name or JWT subject
+	Scopes  []Scope // Granted scopes
+	Tenant  string  // Tenant the caller's data belongs to, models.DefaultTenant if not set
+}
+
+// TenantOf returns the tenant of the principal, or models.DefaultTenant for a nil (anonymous) principal.
+func TenantOf(p *Principal) string {
+	if p == nil {
+		return models.DefaultTenant
+	}
+	return p.Tenant
+}
+
+// HasScope reports whether the principal was granted the given scope.
+func (p *Principal) HasScope(scope Scope) bool {
+	if p == nil {
+		return false
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator verifies static API keys and HS256/RS256 signed JWTs against locally configured keys.
+type Authenticator struct {
+	apiKeys   map[[sha256.Size]byte]*Principal // Static API keys by SHA-256 of the key
+	hmacKey   []byte                           // HS256 shared secret
+	rsaPubKey *rsa.PublicKey                   // RS256 public key
+}
+
+// NewAuthenticator creates an Authenticator from the auth section of the config.
+// Returns nil if authentication is not configured.
+func NewAuthenticator(cfg *config.XisDataAggregatorConfig) (*Authenticator, error) {
+	if !cfg.AuthEnabled() {
+		return nil, nil
+	}
+
+	apiKeys, err := ParseAPIKeys(cfg.APIKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	a := Authenticator{apiKeys: apiKeys}
+
+	if cfg.JWTHS256Secret != "" {
+		a.hmacKey = []byte(cfg.JWTHS256Secret)
+	}
+
+	if cfg.JWTRS256PublicKeyFile != "" {
+		pem, err := os.ReadFile(cfg.JWTRS256PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read JWT public key: %w", err)
+		}
+		a.rsaPubKey, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("parse JWT public key: %w", err)
+		}
+	}
+
+	return &a, nil
+}
+
+// ParseAPIKeys parses an API key list in the form "key=scope1,scope2;key2@tenant=scope1" into the principals
+// by SHA-256 of the key, so that keys are neither kept nor compared in the clear.
+// A key without a "@tenant" suffix belongs to the default tenant.
+func ParseAPIKeys(spec string) (map[[sha256.Size]byte]*Principal, error) {
+	keys := make(map[[sha256.Size]byte]*Principal)
+
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, scopes, ok := strings.Cut(entry, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid API key entry %q", entry)
+		}
+
+		key, tenant, _ := strings.Cut(key, "@")
+		if err := models.ValidateTenant(tenant); err != nil {
+			return nil, err
+		}
+
+		// The subject identifies the key (e.g. for rate limiting) without exposing it in logs
+		sum := sha256.Sum256([]byte(key))
+		principal := Principal{Subject: "apikey:" + hex.EncodeToString(sum[:6]), Tenant: tenant}
+
+		for _, s := range strings.Split(scopes, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				principal.Scopes = append(principal.Scopes, Scope(s))
+			}
+		}
+		keys[sum] = &principal
+	}
+
+	return keys, nil
+}
+
+// Authenticate verifies the credential (an API key or a JWT) and returns the caller principal.
+// Returns ErrUnauthenticated if the credential is empty, unknown, or fails verification.
+func (a *Authenticator) Authenticate(credential string) (*Principal, error) {
+	if credential == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	// Looked up by hash, so that the lookup time does not depend on how much of a key the credential matches
+	if p, ok := a.apiKeys[sha256.Sum256([]byte(credential))]; ok {
+		return p, nil
+	}
+
+	if strings.Count(credential, ".") != 2 {
+		return nil, ErrUnauthenticated
+	}
+
+	return a.verifyJWT(credential)
+}
+
+// Authorize authenticates the credential and checks that it carries the required scope.
+func (a *Authenticator) Authorize(credential string, scope Scope) (*Principal, error) {
+	p, err := a.Authenticate(credential)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.HasScope(scope) {
+		return p, ErrPermissionDenied
+	}
+
+	return p, nil
+}
+
+// claims are the JWT claims understood by the service. Scopes follow the OAuth2 space-separated "scope" claim.
+type claims struct {
+	Scope  string `json:"scope"`
+	Tenant string `json:"tenant,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// verifyJWT checks the token signature and standard time claims and converts it to a Principal.
+func (a *Authenticator) verifyJWT(token string) (*Principal, error) {
+	var c claims
+
+	_, err := jwt.ParseWithClaims(token, &c, func(t *jwt.Token) (interface{}, error) {
+		switch t.Method.Alg() {
+		case jwt.SigningMethodHS256.Alg():
+			if a.hmacKey != nil {
+				return a.hmacKey, nil
+			}
+		case jwt.SigningMethodRS256.Alg():
+			if a.rsaPubKey != nil {
+				return a.rsaPubKey, nil
+			}
+		}
+		return nil, fmt.Errorf("unsupported signing method %q", t.Method.Alg())
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+
+	if err := models.ValidateTenant(c.Tenant); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+
+	principal := Principal{Subject: c.Subject, Tenant: c.Tenant}
+
+	for _, s := range strings.Fields(c.Scope) {
+		principal.Scopes = append(principal.Scopes, Scope(s))
+	}
+
+	return &principal, nil
+}
+
+// BearerToken extracts the credential from an "Authorization: Bearer <token>" header value.
+// Returns an empty string if the value is not a bearer credential.
+func BearerToken(header string) string {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,72 @@
+// Package auth contains tests for API key and JWT authentication.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAuthorize tests credential verification and scope enforcement for API keys and JWTs.
+func TestAuthorize(t *testing.T) {
+	secret := []byte("test-secret")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	apiKeys, err := ParseAPIKeys("reader=read; producer=read,ingest")
+	require.NoError(t, err)
+
+	a := &Authenticator{apiKeys: apiKeys, hmacKey: secret, rsaPubKey: &rsaKey.PublicKey}
+
+	// sign creates a token with the given method, key, scope and expiration offset.
+	sign := func(method jwt.SigningMethod, key interface{}, scope string, exp time.Duration) string {
+		token := jwt.NewWithClaims(method, claims{
+			Scope: scope,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "client-1",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(exp)),
+			},
+		})
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	// Define test cases for Authorize
+	tests := []struct {
+		name       string // Name of the test case
+		credential string // API key or JWT
+		scope      Scope  // Required scope
+		wantErr    error  // Expected error, nil on success
+	}{
+		{name: "API key with scope", credential: "producer", scope: ScopeIngest},
+		{name: "API key without scope", credential: "reader", scope: ScopeIngest, wantErr: ErrPermissionDenied},
+		{name: "Unknown API key", credential: "nobody", scope: ScopeRead, wantErr: ErrUnauthenticated},
+		{name: "Empty credential", credential: "", scope: ScopeRead, wantErr: ErrUnauthenticated},
+		{name: "HS256 JWT", credential: sign(jwt.SigningMethodHS256, secret, "read ingest", time.Hour), scope: ScopeIngest},
+		{name: "HS256 JWT without scope", credential: sign(jwt.SigningMethodHS256, secret, "read", time.Hour), scope: ScopeIngest, wantErr: ErrPermissionDenied},
+		{name: "HS256 JWT wrong secret", credential: sign(jwt.SigningMethodHS256, []byte("other"), "read", time.Hour), scope: ScopeRead, wantErr: ErrUnauthenticated},
+		{name: "Expired JWT", credential: sign(jwt.SigningMethodHS256, secret, "read", -time.Hour), scope: ScopeRead, wantErr: ErrUnauthenticated},
+		{name: "RS256 JWT", credential: sign(jwt.SigningMethodRS256, rsaKey, "read", time.Hour), scope: ScopeRead},
+		{name: "RS256 JWT wrong key", credential: sign(jwt.SigningMethodRS256, otherKey, "read", time.Hour), scope: ScopeRead, wantErr: ErrUnauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := a.Authorize(tt.credential, tt.scope)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+// Package backup writes the Data records of a repository to a portable, versioned and checksummed archive and
+// restores archives into any models.Repository.
+//
+// An archive is a gzip stream of a JSON header line, the records as length-prefixed (uvarint) protobuf
+// messages in the storage encoding, grouped by tenant, an empty record marking the end and a JSON trailer line
+// with the record counts and the SHA-256 of everything before the trailer.
+package backup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+	"time"
+	"xis-data-aggregator/internal/api"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/pb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	Format  = "xis-data-aggregator-backup" // Header format name
+	Version = 1                            // Archive version written, and the latest one read
+
+	batchSize     = 1000    // Records read or restored per repository call
+	maxRecordSize = 1 << 20 // Cap of an archived record, larger length prefixes are corruption
+)
+
+var (
+	ErrCorrupt     = errors.New("corrupted backup")
+	ErrUnsupported = errors.New("unsupported backup")
+)
+
+// Manifest describes an archive.
+type Manifest struct {
+	Format  string           `json:"format"`
+	Version int              `json:"version"`
+	Created time.Time        `json:"created"`
+	Records int64            `json:"records"`
+	Tenants map[string]int64 `json:"tenants"` // Records per tenant, "" - the default tenant
+	SHA256  string           `json:"sha256"`
+}
+
+// header is the first line of an archive.
+type header struct {
+	Format  string    `json:"format"`
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+}
+
+// trailer is the last line of an archive.
+type trailer struct {
+	Records int64            `json:"records"`
+	Tenants map[string]int64 `json:"tenants"`
+	SHA256  string           `json:"sha256"`
+}
+
+// Write writes an archive of all records of the tenants in src to w. Records written to src meanwhile may be
+// missed or archived twice; restoring a duplicate overwrites the same record.
+func Write(w io.Writer, src models.Repository, tenants []string) (Manifest, error) {
+	zw := gzip.NewWriter(w)
+	bw := bufio.NewWriter(zw)
+	sum := sha256.New()
+	out := io.MultiWriter(bw, sum)
+
+	m := Manifest{Format: Format, Version: Version, Created: time.Now().UTC(), Tenants: make(map[string]int64)}
+	if err := writeLine(out, header{Format: m.Format, Version: m.Version, Created: m.Created}); err != nil {
+		return m, err
+	}
+
+	var frame []byte
+	for _, tenant := range tenants {
+		err := src.ForTenant(tenant).ScanByPeriod(math.MinInt64, math.MaxInt64, batchSize, func(batch []models.Data) error {
+			for i := range batch {
+				batch[i].Tenant = tenant
+				pbData, err := api.DataToProto(&batch[i])
+				if err != nil {
+					return err
+				}
+
+				bytes, err := proto.Marshal(pbData)
+				if err != nil {
+					return err
+				}
+				frame = append(binary.AppendUvarint(frame[:0], uint64(len(bytes))), bytes...)
+				if _, err := out.Write(frame); err != nil {
+					return err
+				}
+				m.Tenants[tenant]++
+				m.Records++
+			}
+			return nil
+		})
+		if err != nil {
+			return m, fmt.Errorf("tenant %q: %w", tenant, err)
+		}
+	}
+
+	// The end marker, then the trailer covered by the checksum
+	if _, err := out.Write(binary.AppendUvarint(nil, 0)); err != nil {
+		return m, err
+	}
+	m.SHA256 = hex.EncodeToString(sum.Sum(nil))
+	if err := writeLine(bw, trailer{Records: m.Records, Tenants: m.Tenants, SHA256: m.SHA256}); err != nil {
+		return m, err
+	}
+
+	if err := bw.Flush(); err != nil {
+		return m, err
+	}
+	return m, zw.Close()
+}
+
+// Verify reads a whole archive and checks its version, records, counts and checksum.
+// Returns the manifest, or an error wrapping ErrUnsupported or ErrCorrupt.
+func Verify(r io.Reader) (Manifest, error) {
+	return read(r, func(*models.Data) error { return nil })
+}
+
+// Result is the outcome of a restore: the manifest of the archive and the number of its records stored.
+type Result struct {
+	Manifest
+	Restored int64 `json:"restored"` // Records stored
+	Expired  int64 `json:"expired"`  // Records older than the retention of their tenant, not stored
+}
+
+// Restore verifies the archive and then stores its records into dst in batches, each record into the tenant
+// returned by into for its archived tenant (nil - the archived tenant). Existing records with the same IDs are
+// overwritten, others are kept. Records older than the retention of their tenant are counted as expired instead,
+// as the repository would drop them. Nothing is stored if the archive does not verify.
+func Restore(r io.ReadSeeker, dst models.Repository, into func(tenant string) string) (Result, error) {
+	if _, err := Verify(r); err != nil {
+		return Result{}, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return Result{}, err
+	}
+
+	var res Result
+	var tenant string
+	var repo models.Repository // View of tenant
+	var cutoff int64           // Oldest timestamp kept in tenant
+	batch := make([]*models.Data, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := repo.PutBatch(batch); err != nil {
+			return fmt.Errorf("tenant %q: %w", tenant, err)
+		}
+		res.Restored += int64(len(batch))
+		batch = batch[:0]
+		return nil
+	}
+
+	m, err := read(r, func(data *models.Data) error {
+		t := data.Tenant
+		if into != nil {
+			t = into(t)
+		}
+		if repo == nil || t != tenant || len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+			if repo == nil || t != tenant {
+				tenant, repo, cutoff = t, dst.ForTenant(t), math.MinInt64
+				if retention := repo.Retention(); retention > 0 {
+					cutoff = models.Timestamp(time.Now().Add(-retention))
+				}
+			}
+		}
+		if data.Timestamp < cutoff {
+			res.Expired++
+			return nil
+		}
+		batch = append(batch, data)
+		return nil
+	})
+	res.Manifest = m
+	if err != nil {
+		return res, err
+	}
+	return res, flush()
+}
+
+// read reads an archive, passing every record to fn.
+func read(r io.Reader, fn func(*models.Data) error) (Manifest, error) {
+	var m Manifest
+
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return m, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	br := bufio.NewReader(zr)
+	sum := sha256.New()
+
+	var h header
+	if err := readLine(br, sum, &h); err != nil {
+		return m, err
+	}
+	if h.Format != Format {
+		return m, fmt.Errorf("%w: format %q", ErrUnsupported, h.Format)
+	}
+	if h.Version < 1 || h.Version > Version {
+		return m, fmt.Errorf("%w: version %d, latest supported %d", ErrUnsupported, h.Version, Version)
+	}
+	m = Manifest{Format: h.Format, Version: h.Version, Created: h.Created, Tenants: make(map[string]int64)}
+
+	in := &hashingReader{r: br, sum: sum}
+	var buf []byte
+	for {
+		size, err := binary.ReadUvarint(in)
+		if err != nil {
+			return m, fmt.Errorf("%w: %v", ErrCorrupt, unexpected(err))
+		}
+		if size == 0 {
+			break
+		}
+		if size > maxRecordSize {
+			return m, fmt.Errorf("%w: record of %d bytes", ErrCorrupt, size)
+		}
+
+		if uint64(cap(buf)) < size {
+			buf = make([]byte, size)
+		}
+		buf = buf[:size]
+		if _, err := io.ReadFull(in, buf); err != nil {
+			return m, fmt.Errorf("%w: %v", ErrCorrupt, unexpected(err))
+		}
+
+		var pbData pb.Data
+		if err := proto.Unmarshal(buf, &pbData); err != nil {
+			return m, fmt.Errorf("%w: record %d: %v", ErrCorrupt, m.Records+1, err)
+		}
+		data, err := api.ProtoToData(&pbData)
+		if err != nil {
+			return m, fmt.Errorf("%w: record %d: %v", ErrCorrupt, m.Records+1, err)
+		}
+
+		m.Records++
+		m.Tenants[data.Tenant]++
+		if err := fn(data); err != nil {
+			return m, err
+		}
+	}
+	m.SHA256 = hex.EncodeToString(sum.Sum(nil))
+
+	var t trailer
+	if err := readLine(br, nil, &t); err != nil {
+		return m, err
+	}
+	if t.SHA256 != m.SHA256 {
+		return m, fmt.Errorf("%w: checksum mismatch", ErrCorrupt)
+	}
+	if t.Records != m.Records {
+		return m, fmt.Errorf("%w: %d records, trailer counts %d", ErrCorrupt, m.Records, t.Records)
+	}
+	return m, nil
+}
+
+// hashingReader adds the bytes read to a checksum.
+type hashingReader struct {
+	r   *bufio.Reader
+	sum hash.Hash
+}
+
+func (o *hashingReader) Read(p []byte) (int, error) {
+	n, err := o.r.Read(p)
+	o.sum.Write(p[:n])
+	return n, err
+}
+
+func (o *hashingReader) ReadByte() (byte, error) {
+	b, err := o.r.ReadByte()
+	if err == nil {
+		o.sum.Write([]byte{b})
+	}
+	return b, err
+}
+
+// writeLine writes v as a JSON line.
+func writeLine(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// readLine reads a JSON line into v, adding it to the checksum if sum is not nil.
+func readLine(r *bufio.Reader, sum hash.Hash, v interface{}) error {
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupt, unexpected(err))
+	}
+	if sum != nil {
+		sum.Write(line)
+	}
+	if err := json.Unmarshal(line, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	return nil
+}
+
+// unexpected converts io.EOF, a truncated archive, to io.ErrUnexpectedEOF.
+func unexpected(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
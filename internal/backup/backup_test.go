@@ -0,0 +1,137 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/repository/repotest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// all returns the records of the tenant in the repository.
+func all(t *testing.T, repo models.Repository, tenant string) []models.Data {
+	data, err := repo.ForTenant(tenant).ListByPeriod(math.MinInt64, math.MaxInt64)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func TestRoundTrip(t *testing.T) {
+	src := repotest.New(t)
+	for i := int64(0); i < 2500; i++ {
+		require.NoError(t, src.Put(&models.Data{ID: uuid.New(), Timestamp: i, Max: models.IntValue(i), Series: "cpu"}))
+	}
+	float := models.Data{ID: uuid.New(), Timestamp: -5, Max: models.FloatValue(42), Series: "temp", Labels: map[string]string{"host": "a"}}
+	require.NoError(t, src.ForTenant("acme").Put(&float))
+
+	tenants, err := src.Tenants()
+	require.NoError(t, err)
+	sort.Strings(tenants)
+	require.Equal(t, []string{"", "acme"}, tenants)
+
+	var archive bytes.Buffer
+	m, err := Write(&archive, src, tenants)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2501), m.Records)
+	assert.Equal(t, map[string]int64{"": 2500, "acme": 1}, m.Tenants)
+
+	verified, err := Verify(bytes.NewReader(archive.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, m.SHA256, verified.SHA256)
+	assert.Equal(t, m.Tenants, verified.Tenants)
+
+	// Into the archived tenants of another repository, with both indexes
+	dst := repotest.New(t)
+	_, err = Restore(bytes.NewReader(archive.Bytes()), dst, nil)
+	require.NoError(t, err)
+	assert.Equal(t, all(t, src, ""), all(t, dst, ""))
+	restored := all(t, dst, "acme")
+	require.Len(t, restored, 1)
+	assert.Equal(t, models.FloatValue(42), restored[0].Max, "the value type is kept")
+	byID, err := dst.ForTenant("acme").GetByID(float.ID)
+	require.NoError(t, err)
+	assert.Equal(t, float.Labels, byID.Labels)
+
+	// Into one tenant
+	dst = repotest.New(t)
+	res, err := Restore(bytes.NewReader(archive.Bytes()), dst, func(string) string { return "other" })
+	require.NoError(t, err)
+	assert.Equal(t, int64(2501), res.Restored)
+	assert.Zero(t, res.Expired)
+	assert.Len(t, all(t, dst, "other"), 2501)
+	assert.Empty(t, all(t, dst, ""))
+}
+
+func TestRestoreRetention(t *testing.T) {
+	src := repotest.New(t)
+	recent := models.Data{ID: uuid.New(), Timestamp: models.Timestamp(time.Now()), Max: models.IntValue(1)}
+	require.NoError(t, src.Put(&recent))
+	require.NoError(t, src.Put(&models.Data{ID: uuid.New(), Timestamp: 1, Max: models.IntValue(2)}))
+	var archive bytes.Buffer
+	_, err := Write(&archive, src, []string{""})
+	require.NoError(t, err)
+
+	// Records older than the retention are reported instead of counted as restored
+	dst := repotest.New(t)
+	dst.SetRetention(map[string]time.Duration{"*": time.Hour})
+	res, err := Restore(bytes.NewReader(archive.Bytes()), dst, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.Records)
+	assert.Equal(t, int64(1), res.Restored)
+	assert.Equal(t, int64(1), res.Expired)
+	restored := all(t, dst, "")
+	require.Len(t, restored, 1)
+	assert.Equal(t, recent.ID, restored[0].ID)
+}
+
+func TestCorrupt(t *testing.T) {
+	src := repotest.New(t)
+	require.NoError(t, src.Put(&models.Data{ID: uuid.New(), Timestamp: 1, Max: models.IntValue(7)}))
+	var archive bytes.Buffer
+	_, err := Write(&archive, src, []string{""})
+	require.NoError(t, err)
+
+	zr, err := gzip.NewReader(&archive)
+	require.NoError(t, err)
+	plain, err := io.ReadAll(zr)
+	require.NoError(t, err)
+
+	rewrite := func(f func(string) string) *bytes.Reader {
+		var b bytes.Buffer
+		zw := gzip.NewWriter(&b)
+		_, err := zw.Write([]byte(f(string(plain))))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+		return bytes.NewReader(b.Bytes())
+	}
+
+	tests := []struct {
+		name string
+		edit func(string) string
+		err  error
+	}{
+		{"tampered record", func(s string) string { return strings.Replace(s, "\x10\x01", "\x10\x02", 1) }, ErrCorrupt},
+		{"truncated", func(s string) string { return s[:len(s)-20] }, ErrCorrupt},
+		{"newer version", func(s string) string { return strings.Replace(s, `"version":1`, `"version":2`, 1) }, ErrUnsupported},
+		{"other format", func(s string) string { return strings.Replace(s, Format, "tar", 1) }, ErrUnsupported},
+	}
+	for _, tt := range tests {
+		dst := repotest.New(t)
+		_, err := Restore(rewrite(tt.edit), dst, nil)
+		assert.ErrorIs(t, err, tt.err, tt.name)
+		assert.Empty(t, all(t, dst, ""), tt.name)
+	}
+
+	_, err = Verify(strings.NewReader("not gzip"))
+	assert.ErrorIs(t, err, ErrCorrupt)
+}
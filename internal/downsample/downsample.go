@@ -0,0 +1,255 @@
+// Package downsample reduces the records of a time range to a bounded number of visually representative
+// records per series, consuming them in timestamp order without keeping the whole range in memory.
+package downsample
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"xis-data-aggregator/internal/models"
+)
+
+// Method is a downsampling algorithm.
+type Method string
+
+const (
+	MethodLTTB   Method = "lttb"   // Largest-Triangle-Three-Buckets
+	MethodMinMax Method = "minmax" // Minimum and maximum record per bucket
+)
+
+const (
+	DefaultPoints = 500    // Points per series when none are requested
+	MaxPoints     = 10_000 // Cap of the points requested per series
+)
+
+// ParseMethod parses a method name; the empty string selects MethodLTTB.
+func ParseMethod(s string) (Method, error) {
+	switch m := Method(s); m {
+	case "":
+		return MethodLTTB, nil
+	case MethodLTTB, MethodMinMax:
+		return m, nil
+	}
+	return "", fmt.Errorf("invalid downsampling method %q", s)
+}
+
+// sampler downsamples the records of one series.
+type sampler interface {
+	add(data *models.Data)
+	finish()
+}
+
+// Downsampler downsamples records added in ascending timestamp order to at most the requested points per
+// series (name and labels). The range [from, to] is divided into equal time buckets, so memory is bounded
+// by the records of two buckets per series rather than by the range.
+type Downsampler struct {
+	method   Method
+	from, to int64
+	points   int
+	series   map[string]sampler // By series key
+	out      []models.Data      // Selected records
+}
+
+// New creates a downsampler of the records within [from, to] to at most points records per series.
+// Returns an error if the method is unknown, the range is empty or points is not within [3, MaxPoints].
+func New(method Method, from, to int64, points int) (*Downsampler, error) {
+	if method != MethodLTTB && method != MethodMinMax {
+		return nil, fmt.Errorf("invalid downsampling method %q", method)
+	}
+	if from >= to {
+		return nil, fmt.Errorf("invalid time range: 'from' must be less than 'to'")
+	}
+	if points < 3 || points > MaxPoints {
+		return nil, fmt.Errorf("points must be between 3 and %d", MaxPoints)
+	}
+
+	return &Downsampler{method: method, from: from, to: to, points: points, series: make(map[string]sampler)}, nil
+}
+
+// Add adds the next record. Records outside the range are ignored.
+func (d *Downsampler) Add(data *models.Data) {
+	if data.Timestamp < d.from || data.Timestamp > d.to {
+		return
+	}
+
+	key := data.SeriesKey()
+	s := d.series[key]
+	if s == nil {
+		if d.method == MethodLTTB {
+			s = d.newLTTB()
+		} else {
+			s = d.newMinMax()
+		}
+		d.series[key] = s
+	}
+	s.add(data)
+}
+
+// Result completes the downsampling and returns the selected records sorted by timestamp.
+func (d *Downsampler) Result() []models.Data {
+	for _, s := range d.series {
+		s.finish()
+	}
+	d.series = make(map[string]sampler)
+
+	sort.SliceStable(d.out, func(i, j int) bool { return d.out[i].Timestamp < d.out[j].Timestamp })
+	return d.out
+}
+
+// bucket returns the index of the timestamp among n equal buckets of the range.
+func (d *Downsampler) bucket(ts int64, n int) int {
+	i := int(float64(ts-d.from) / float64(d.to-d.from+1) * float64(n))
+	return min(max(i, 0), n-1)
+}
+
+// emit adds a selected record to the result.
+func (d *Downsampler) emit(data *models.Data) {
+	d.out = append(d.out, *data)
+}
+
+// lttb selects the first and the last record of the series and, from every bucket, the record forming the
+// largest triangle with the record selected from the previous bucket and the average of the next bucket.
+// The last record is held back as the end point; the selection from a bucket is made once the next bucket
+// is complete.
+type lttb struct {
+	d         *Downsampler
+	buckets   int
+	selected  *models.Data   // Record selected last, the first triangle vertex
+	last      *models.Data   // Latest record, not yet bucketed
+	pending   []*models.Data // Complete bucket awaiting selection
+	current   []*models.Data // Bucket being filled
+	currentIx int
+}
+
+func (d *Downsampler) newLTTB() *lttb {
+	return &lttb{d: d, buckets: d.points - 2}
+}
+
+func (s *lttb) add(data *models.Data) {
+	data = copyOf(data)
+	if s.selected == nil {
+		s.selected = data
+		s.d.emit(data)
+		return
+	}
+
+	if s.last != nil {
+		s.bucketed(s.last)
+	}
+	s.last = data
+}
+
+// bucketed adds a record between the first and the last one to its bucket.
+func (s *lttb) bucketed(data *models.Data) {
+	ix := s.d.bucket(data.Timestamp, s.buckets)
+	if len(s.current) > 0 && ix != s.currentIx {
+		if len(s.pending) > 0 {
+			s.selectFrom(s.pending, average(s.current))
+		}
+		s.pending, s.current = s.current, nil
+	}
+	s.currentIx = ix
+	s.current = append(s.current, data)
+}
+
+func (s *lttb) finish() {
+	if s.last == nil {
+		return
+	}
+
+	end := point{float64(s.last.Timestamp), s.last.Max.Float64()}
+	if len(s.pending) > 0 {
+		next := end
+		if len(s.current) > 0 {
+			next = average(s.current)
+		}
+		s.selectFrom(s.pending, next)
+	}
+	if len(s.current) > 0 {
+		s.selectFrom(s.current, end)
+	}
+	s.d.emit(s.last)
+}
+
+// selectFrom emits the record of the bucket forming the largest triangle with the selected record and next.
+func (s *lttb) selectFrom(bucket []*models.Data, next point) {
+	a := point{float64(s.selected.Timestamp), s.selected.Max.Float64()}
+
+	best, bestArea := bucket[0], -1.0
+	for _, data := range bucket {
+		area := math.Abs((a.x-next.x)*(data.Max.Float64()-a.y) - (a.x-float64(data.Timestamp))*(next.y-a.y))
+		if area > bestArea {
+			best, bestArea = data, area
+		}
+	}
+
+	s.selected = best
+	s.d.emit(best)
+}
+
+// minMax selects the records with the minimum and the maximum Max of every bucket, in timestamp order.
+type minMax struct {
+	d         *Downsampler
+	buckets   int
+	low, high *models.Data // Of the current bucket
+	currentIx int
+}
+
+func (d *Downsampler) newMinMax() *minMax {
+	return &minMax{d: d, buckets: d.points / 2}
+}
+
+func (s *minMax) add(data *models.Data) {
+	ix := s.d.bucket(data.Timestamp, s.buckets)
+	if s.low != nil && ix != s.currentIx {
+		s.finish()
+	}
+	s.currentIx = ix
+
+	switch {
+	case s.low == nil:
+		s.low, s.high = copyOf(data), copyOf(data)
+	case data.Max.Float64() < s.low.Max.Float64():
+		s.low = copyOf(data)
+	case data.Max.Float64() > s.high.Max.Float64():
+		s.high = copyOf(data)
+	}
+}
+
+func (s *minMax) finish() {
+	if s.low == nil {
+		return
+	}
+
+	first, second := s.low, s.high
+	if second.Timestamp < first.Timestamp {
+		first, second = second, first
+	}
+	s.d.emit(first)
+	if second.ID != first.ID || second.Timestamp != first.Timestamp {
+		s.d.emit(second)
+	}
+	s.low, s.high = nil, nil
+}
+
+// point is a record in the chart plane.
+type point struct {
+	x, y float64
+}
+
+// average returns the centroid of the records.
+func average(bucket []*models.Data) point {
+	var p point
+	for _, data := range bucket {
+		p.x += float64(data.Timestamp)
+		p.y += data.Max.Float64()
+	}
+	n := float64(len(bucket))
+	return point{p.x / n, p.y / n}
+}
+
+// copyOf returns a copy of the record, which the caller may reuse.
+func copyOf(data *models.Data) *models.Data {
+	c := *data
+	return &c
+}
@@ -0,0 +1,101 @@
+package downsample
+
+import (
+	"math"
+	"testing"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// run downsamples a sine wave of n records of series "a" with a spike at the given index.
+func run(t *testing.T, method Method, n, points, spike int) []models.Data {
+	d, err := New(method, 0, int64(n-1), points)
+	require.NoError(t, err)
+
+	for i := 0; i < n; i++ {
+		v := 100 * math.Sin(float64(i)/50)
+		if i == spike {
+			v = 1000
+		}
+		d.Add(&models.Data{ID: uuid.New(), Timestamp: int64(i), Max: models.FloatValue(v), Series: "a"})
+	}
+	return d.Result()
+}
+
+func TestDownsample(t *testing.T) {
+	tests := []struct {
+		method Method
+		n      int
+		points int
+	}{
+		{MethodLTTB, 10_000, 100},
+		{MethodLTTB, 50, 100},
+		{MethodLTTB, 10_000, 3},
+		{MethodMinMax, 10_000, 100},
+		{MethodMinMax, 50, 100},
+	}
+
+	for _, tt := range tests {
+		spike := tt.n / 3
+		got := run(t, tt.method, tt.n, tt.points, spike)
+
+		assert.LessOrEqual(t, len(got), min(tt.points, tt.n), "%+v", tt)
+		assert.IsIncreasing(t, timestamps(got), "%+v", tt)
+
+		// The spike is visually significant and must survive
+		assert.Contains(t, timestamps(got), int64(spike), "%+v", tt)
+
+		if tt.method == MethodLTTB {
+			assert.Equal(t, int64(0), got[0].Timestamp)
+			assert.Equal(t, int64(tt.n-1), got[len(got)-1].Timestamp)
+		}
+		if tt.n <= tt.points/2 {
+			assert.Len(t, got, tt.n, "%+v: short series are returned as is", tt)
+		}
+	}
+}
+
+func TestSeries(t *testing.T) {
+	d, err := New(MethodLTTB, 0, 999, 10)
+	require.NoError(t, err)
+
+	for i := 0; i < 1000; i++ {
+		d.Add(&models.Data{Timestamp: int64(i), Max: models.IntValue(int64(i % 7)), Series: "a"})
+		d.Add(&models.Data{Timestamp: int64(i), Max: models.IntValue(int64(i % 5)), Series: "b"})
+	}
+	d.Add(&models.Data{Timestamp: 5000, Series: "a"}) // out of range
+
+	counts := make(map[string]int)
+	for _, data := range d.Result() {
+		counts[data.Series]++
+	}
+	assert.Equal(t, map[string]int{"a": 10, "b": 10}, counts)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(MethodLTTB, 0, 10, 2)
+	assert.Error(t, err)
+	_, err = New(MethodLTTB, 0, 10, MaxPoints+1)
+	assert.Error(t, err)
+	_, err = New(MethodMinMax, 10, 10, 100)
+	assert.Error(t, err)
+	_, err = New("avg", 0, 10, 100)
+	assert.Error(t, err)
+
+	_, err = ParseMethod("bogus")
+	assert.Error(t, err)
+	m, err := ParseMethod("")
+	require.NoError(t, err)
+	assert.Equal(t, MethodLTTB, m)
+}
+
+func timestamps(data []models.Data) []int64 {
+	ts := make([]int64, len(data))
+	for i := range data {
+		ts[i] = data[i].Timestamp
+	}
+	return ts
+}
@@ -0,0 +1,230 @@
+// Package export encodes Data records as CSV, newline-delimited JSON or Apache Parquet, one batch at a time,
+// so large time ranges can be streamed without being held in memory.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Format is an export file format.
+type Format string
+
+const (
+	FormatJSON    Format = "json"    // JSON array, the regular API response
+	FormatCSV     Format = "csv"     // Comma-separated values with a header row
+	FormatNDJSON  Format = "ndjson"  // One JSON record per line
+	FormatParquet Format = "parquet" // Apache Parquet
+)
+
+// parquetRowGroupSize is the number of records per Parquet row group, the unit buffered before writing.
+const parquetRowGroupSize = 10_000
+
+// contentTypes are the Accept media types of the formats.
+var contentTypes = map[string]Format{
+	"application/json":               FormatJSON,
+	"text/csv":                       FormatCSV,
+	"application/x-ndjson":           FormatNDJSON,
+	"application/ndjson":             FormatNDJSON,
+	"application/vnd.apache.parquet": FormatParquet,
+	"application/x-parquet":          FormatParquet,
+}
+
+// ParseFormat parses a format name.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatJSON, FormatCSV, FormatNDJSON, FormatParquet:
+		return f, nil
+	}
+	return "", fmt.Errorf("invalid format %q", s)
+}
+
+// FromAccept returns the format of the first media type of an Accept header with a known format,
+// or FormatJSON if there is none.
+func FromAccept(accept string) Format {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if f, ok := contentTypes[mediaType]; ok {
+			return f
+		}
+	}
+	return FormatJSON
+}
+
+// ContentType returns the media type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	}
+	return "application/json; charset=utf-8"
+}
+
+// Encoder writes batches of records in a format.
+type Encoder interface {
+	// Encode writes a batch of records.
+	Encode(data []models.Data) error
+	// Close completes the output; it does not close the underlying writer.
+	Close() error
+}
+
+// NewEncoder creates an encoder of the streamed formats writing to w. CSV and NDJSON timestamps are rendered by
+// ts; Parquet timestamps are typed Unix microseconds. Returns an error for FormatJSON or an unknown format.
+func NewEncoder(f Format, w io.Writer, ts func(int64) interface{}) (Encoder, error) {
+	switch f {
+	case FormatCSV:
+		return newCSVEncoder(w, ts), nil
+	case FormatNDJSON:
+		return &ndjsonEncoder{enc: json.NewEncoder(w), ts: ts}, nil
+	case FormatParquet:
+		return &parquetEncoder{w: parquet.NewGenericWriter[parquetRow](w, parquet.MaxRowsPerRowGroup(parquetRowGroupSize))}, nil
+	}
+	return nil, fmt.Errorf("format %q is not streamed", f)
+}
+
+// csvHeader are the CSV columns; labels are a JSON object.
+var csvHeader = []string{"id", "ts", "max", "tenant", "series", "labels", "anomaly_score", "anomalous"}
+
+type csvEncoder struct {
+	w      *csv.Writer
+	ts     func(int64) interface{}
+	header bool // The header row is written
+}
+
+func newCSVEncoder(w io.Writer, ts func(int64) interface{}) *csvEncoder {
+	return &csvEncoder{w: csv.NewWriter(w), ts: ts}
+}
+
+func (e *csvEncoder) Encode(data []models.Data) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	for i := range data {
+		d := &data[i]
+		labels := ""
+		if len(d.Labels) > 0 {
+			b, err := json.Marshal(d.Labels)
+			if err != nil {
+				return err
+			}
+			labels = string(b)
+		}
+
+		err := e.w.Write([]string{
+			d.ID.String(),
+			fmt.Sprint(e.ts(d.Timestamp)),
+			d.Max.String(),
+			d.Tenant,
+			d.Series,
+			labels,
+			strconv.FormatFloat(d.AnomalyScore, 'g', -1, 64),
+			strconv.FormatBool(d.Anomalous),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// Close writes the header row if no batch was encoded.
+func (e *csvEncoder) Close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) writeHeader() error {
+	if e.header {
+		return nil
+	}
+	e.header = true
+	return e.w.Write(csvHeader)
+}
+
+// ndjsonRecord is the JSON rendering of models.Data with the timestamp rendered by the encoder.
+type ndjsonRecord struct {
+	models.Data
+	Timestamp interface{} `json:"ts"` // Shadows Data.Timestamp
+}
+
+type ndjsonEncoder struct {
+	enc *json.Encoder
+	ts  func(int64) interface{}
+}
+
+func (e *ndjsonEncoder) Encode(data []models.Data) error {
+	for i := range data {
+		if err := e.enc.Encode(ndjsonRecord{Data: data[i], Timestamp: e.ts(data[i].Timestamp)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *ndjsonEncoder) Close() error {
+	return nil
+}
+
+// parquetRow is the Parquet schema of a record. Max is a double column for every series, so int64 values
+// beyond 2^53 lose precision.
+type parquetRow struct {
+	ID           string            `parquet:"id"`
+	Timestamp    int64             `parquet:"ts,timestamp(microsecond)"`
+	Max          float64           `parquet:"max"`
+	Tenant       string            `parquet:"tenant"`
+	Series       string            `parquet:"series"`
+	Labels       map[string]string `parquet:"labels"`
+	AnomalyScore float64           `parquet:"anomaly_score"`
+	Anomalous    bool              `parquet:"anomalous"`
+}
+
+type parquetEncoder struct {
+	w    *parquet.GenericWriter[parquetRow]
+	rows []parquetRow // Reused between batches
+}
+
+func (e *parquetEncoder) Encode(data []models.Data) error {
+	e.rows = e.rows[:0]
+	for i := range data {
+		d := &data[i]
+		e.rows = append(e.rows, parquetRow{
+			ID:           d.ID.String(),
+			Timestamp:    d.Timestamp,
+			Max:          d.Max.Float64(),
+			Tenant:       d.Tenant,
+			Series:       d.Series,
+			Labels:       d.Labels,
+			AnomalyScore: d.AnomalyScore,
+			Anomalous:    d.Anomalous,
+		})
+	}
+
+	_, err := e.w.Write(e.rows)
+	return err
+}
+
+// Close writes the buffered row group and the file footer.
+func (e *parquetEncoder) Close() error {
+	return e.w.Close()
+}
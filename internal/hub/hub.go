@@ -0,0 +1,172 @@
+// Package hub fans newly stored Data records out to live subscribers.
+package hub
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"xis-data-aggregator/internal/models"
+)
+
+// Policy selects what happens when a record doesn't fit a subscriber's buffer.
+type Policy int
+
+// Slow consumer policies, numbered as the protobuf SlowConsumerPolicy enum.
+const (
+	// PolicyDrop drops the record for that subscriber and counts it.
+	PolicyDrop Policy = iota
+	// PolicyDisconnect ends the subscription with ErrSlowConsumer.
+	PolicyDisconnect
+)
+
+var (
+	// ErrSlowConsumer ends a PolicyDisconnect subscription whose buffer is full.
+	ErrSlowConsumer = errors.New("slow consumer")
+)
+
+// Filter selects the records delivered to a subscription.
+type Filter struct {
+	Tenant string         // Only records of this tenant
+	Data   *models.Filter // Series and label selector, nil - all
+	MinMax *float64       // Only records with Max >= MinMax, nil - all
+}
+
+// Matches reports whether the record passes the filter.
+func (f *Filter) Matches(data *models.Data) bool {
+	if data.Tenant != f.Tenant {
+		return false
+	}
+	if f.Data != nil && !f.Data.Matches(data) {
+		return false
+	}
+	return f.MinMax == nil || data.Max.Float64() >= *f.MinMax
+}
+
+// Subscription receives the records published after it was created.
+type Subscription struct {
+	hub     *Hub
+	filter  Filter
+	policy  Policy
+	events  chan models.Data // Bounded buffer, never closed
+	done    chan struct{}    // Closed when the subscription ends
+	once    sync.Once
+	err     error
+	dropped atomic.Uint64
+}
+
+// Events returns the channel of delivered records.
+func (s *Subscription) Events() <-chan models.Data {
+	return s.events
+}
+
+// Done returns a channel that is closed when the subscription ends.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the reason the hub ended the subscription (ErrSlowConsumer), nil otherwise.
+// Valid after Done is closed.
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// Dropped returns the number of records dropped because the buffer was full (PolicyDrop).
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close ends the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.remove(s, nil)
+}
+
+// end closes the subscription with the given reason. Must be called by the hub, which owns the membership.
+func (s *Subscription) end(err error) {
+	s.once.Do(func() {
+		s.err = err
+		close(s.done)
+	})
+}
+
+// Hub delivers published records to matching subscriptions without ever blocking the publisher.
+// It is safe for concurrent use. A nil Hub ignores published records.
+type Hub struct {
+	mu        sync.RWMutex
+	subs      map[*Subscription]struct{}
+	maxBuffer int // Maximum subscription buffer
+}
+
+// New creates a Hub without subscriptions whose subscription buffers hold at most maxBuffer records (at least 1).
+func New(maxBuffer int) *Hub {
+	return &Hub{subs: make(map[*Subscription]struct{}), maxBuffer: max(maxBuffer, 1)}
+}
+
+// Subscribe registers a subscription with a buffer of the given size.
+// A buffer of 0 or above the hub maximum uses the maximum.
+func (h *Hub) Subscribe(filter Filter, policy Policy, buffer int) *Subscription {
+	if buffer <= 0 || buffer > h.maxBuffer {
+		buffer = h.maxBuffer
+	}
+
+	s := &Subscription{
+		hub:    h,
+		filter: filter,
+		policy: policy,
+		events: make(chan models.Data, buffer),
+		done:   make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	h.subs[s] = struct{}{}
+	h.mu.Unlock()
+
+	return s
+}
+
+// Len returns the number of active subscriptions.
+func (h *Hub) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs)
+}
+
+// Publish delivers the record to every matching subscription. Full buffers are handled by the
+// subscription policy, so a slow subscriber never stalls the caller.
+func (h *Hub) Publish(data *models.Data) {
+	if h == nil || data == nil {
+		return
+	}
+
+	var slow []*Subscription
+
+	h.mu.RLock()
+	for s := range h.subs {
+		if !s.filter.Matches(data) {
+			continue
+		}
+
+		select {
+		case s.events <- *data:
+		default:
+			if s.policy == PolicyDisconnect {
+				slow = append(slow, s)
+			} else {
+				s.dropped.Add(1)
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, s := range slow {
+		h.remove(s, ErrSlowConsumer)
+	}
+}
+
+// remove unregisters the subscription and ends it with the given reason.
+func (h *Hub) remove(s *Subscription, err error) {
+	h.mu.Lock()
+	delete(h.subs, s)
+	h.mu.Unlock()
+
+	s.end(err)
+}
@@ -0,0 +1,72 @@
+// Package hub contains tests for record fan-out to subscribers.
+package hub
+
+import (
+	"testing"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFilter tests the tenant, series and minimum max selection of records.
+func TestFilter(t *testing.T) {
+	minMax := 10.0
+
+	// Define test cases for Filter.Matches
+	tests := []struct {
+		name   string      // Name of the test case
+		filter Filter      // Subscription filter
+		data   models.Data // Published record
+		want   bool        // Expected result
+	}{
+		{name: "Same tenant", filter: Filter{Tenant: "acme"}, data: models.Data{Tenant: "acme"}, want: true},
+		{name: "Other tenant", filter: Filter{Tenant: "acme"}, data: models.Data{}, want: false},
+		{name: "Series match", filter: Filter{Data: &models.Filter{Series: "cpu"}}, data: models.Data{Series: "cpu"}, want: true},
+		{name: "Series mismatch", filter: Filter{Data: &models.Filter{Series: "cpu"}}, data: models.Data{Series: "mem"}, want: false},
+		{name: "Max above minimum", filter: Filter{MinMax: &minMax}, data: models.Data{Max: models.IntValue(10)}, want: true},
+		{name: "Max below minimum", filter: Filter{MinMax: &minMax}, data: models.Data{Max: models.FloatValue(9.5)}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(&tt.data))
+		})
+	}
+}
+
+// TestSlowConsumer tests that full buffers drop records or disconnect the subscriber without blocking Publish.
+func TestSlowConsumer(t *testing.T) {
+	h := New(2)
+
+	dropping := h.Subscribe(Filter{}, PolicyDrop, 0)
+	disconnecting := h.Subscribe(Filter{}, PolicyDisconnect, 100)
+	other := h.Subscribe(Filter{Tenant: "acme"}, PolicyDisconnect, 1)
+	assert.Equal(t, 3, h.Len())
+
+	for i := 0; i < 5; i++ {
+		h.Publish(&models.Data{Max: models.IntValue(int64(i))})
+	}
+
+	// The dropping subscriber keeps the first records and stays subscribed
+	assert.Equal(t, uint64(3), dropping.Dropped())
+	assert.Equal(t, models.IntValue(0), (<-dropping.Events()).Max)
+	assert.Equal(t, models.IntValue(1), (<-dropping.Events()).Max)
+
+	// The disconnecting subscriber is ended
+	<-disconnecting.Done()
+	assert.ErrorIs(t, disconnecting.Err(), ErrSlowConsumer)
+
+	// Subscribers of other tenants are not affected
+	assert.Empty(t, other.Events())
+	assert.Equal(t, 2, h.Len())
+
+	dropping.Close()
+	dropping.Close()
+	<-dropping.Done()
+	assert.NoError(t, dropping.Err())
+	assert.Equal(t, 1, h.Len())
+
+	// A nil hub ignores records
+	var nilHub *Hub
+	nilHub.Publish(&models.Data{})
+}
@@ -0,0 +1,194 @@
+// Package importer reads Pack files in CSV or newline-delimited JSON and imports them in batches, reporting
+// progress and the checkpoint an interrupted import resumes from.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"xis-data-aggregator/internal/models"
+)
+
+// ErrStore is returned when the sink fails to store a batch.
+var ErrStore = errors.New("batch not stored")
+
+// Format is a Pack file format.
+type Format string
+
+const (
+	FormatCSV    Format = "csv"    // Header row naming the columns ts, data and optionally id, type, series, labels
+	FormatNDJSON Format = "ndjson" // One Pack JSON object per line, as accepted by POST /api/v1/packs
+)
+
+const (
+	DefaultBatchSize = 500  // Packs written per batch when none is requested
+	MaxBatchSize     = 5000 // Cap of the packs written per batch
+	maxErrors        = 100  // Record errors kept in the progress, later ones are only counted
+)
+
+// ParseFormat parses a format name.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatCSV, FormatNDJSON:
+		return f, nil
+	}
+	return "", fmt.Errorf("invalid import format %q", s)
+}
+
+// FormatOf returns the format of a file by its extension: .csv, or .ndjson, .jsonl and .json.
+func FormatOf(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".ndjson", ".jsonl", ".json":
+		return FormatNDJSON, nil
+	}
+	return "", fmt.Errorf("unknown import format of %q, expected a .csv or .ndjson file", path)
+}
+
+// Sink validates batches of packs, maps them to Data and stores the valid ones.
+type Sink interface {
+	// ImportPacks returns the error of every invalid pack by index, nil for valid ones, or an error if the
+	// batch could not be stored; then none of its packs are stored.
+	ImportPacks(packs []*models.Pack) ([]error, error)
+}
+
+// Options are the parameters of an import.
+type Options struct {
+	Format     Format
+	Precision  models.TimestampPrecision // Unit of integer timestamps in the file
+	BatchSize  int                       // Packs per Sink call, 0 - DefaultBatchSize
+	Checkpoint int64                     // Records of the file already imported, skipped without validation
+	Progress   func(Progress) error      // Called after every stored batch, optional; an error stops the import
+}
+
+// RecordError is the error of a record, numbered from 1 in file order (CSV header excluded).
+type RecordError struct {
+	Record int64  `json:"record"`
+	Error  string `json:"error"`
+}
+
+// Progress is the state of an import.
+type Progress struct {
+	Records  int64         `json:"records"`          // Records read, including skipped ones: the checkpoint to resume from
+	Skipped  int64         `json:"skipped"`          // Records skipped up to the checkpoint
+	Imported int64         `json:"imported"`         // Valid records passed to the sink
+	Failed   int64         `json:"failed"`           // Malformed or invalid records
+	Errors   []RecordError `json:"errors,omitempty"` // Errors of the first failed records
+}
+
+// fail records the error of a record.
+func (p *Progress) fail(record int64, err error) {
+	p.Failed++
+	if len(p.Errors) < maxErrors {
+		p.Errors = append(p.Errors, RecordError{Record: record, Error: err.Error()})
+	}
+}
+
+// recordError is an error of a single record, which fails the record but not the import.
+type recordError struct {
+	err error
+}
+
+func (e recordError) Error() string {
+	return e.err.Error()
+}
+
+// packReader reads the packs of a file one record at a time.
+type packReader interface {
+	// next returns the next pack, a recordError if the record is malformed, or io.EOF.
+	next() (*models.Pack, error)
+}
+
+// Import reads the packs of the file from r and passes them to the sink in batches, starting after the
+// checkpoint. Malformed and invalid records are counted and reported in the progress; the import continues.
+// Returns the final progress and an error if the file can't be read, a batch can't be stored or the progress
+// callback fails; the progress then holds the checkpoint after the last stored batch.
+func Import(r io.Reader, sink Sink, opts Options) (Progress, error) {
+	progress := Progress{Records: max(opts.Checkpoint, 0)}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if batchSize > MaxBatchSize {
+		return progress, fmt.Errorf("batch size must not exceed %d", MaxBatchSize)
+	}
+
+	var reader packReader
+	switch opts.Format {
+	case FormatCSV:
+		reader = newCSVReader(r, opts.Precision)
+	case FormatNDJSON:
+		reader = newNDJSONReader(r, opts.Precision)
+	default:
+		return progress, fmt.Errorf("invalid import format %q", opts.Format)
+	}
+
+	batch := make([]*models.Pack, 0, batchSize)
+	numbers := make([]int64, 0, batchSize) // Record numbers of the batch packs
+	read := int64(0)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		errs, err := sink.ImportPacks(batch)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrStore, err)
+		}
+		for i := range batch {
+			if i < len(errs) && errs[i] != nil {
+				progress.fail(numbers[i], errs[i])
+			} else {
+				progress.Imported++
+			}
+		}
+		progress.Records = read
+		batch, numbers = batch[:0], numbers[:0]
+
+		if opts.Progress != nil {
+			return opts.Progress(progress)
+		}
+		return nil
+	}
+
+	for {
+		pack, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var recErr recordError
+		if err != nil && !errors.As(err, &recErr) {
+			return progress, err
+		}
+
+		read++
+		switch {
+		case read <= opts.Checkpoint:
+			progress.Skipped++
+			continue
+		case err != nil:
+			progress.fail(read, recErr)
+			continue
+		}
+
+		batch = append(batch, pack)
+		numbers = append(numbers, read)
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return progress, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return progress, err
+	}
+	progress.Records = read
+	return progress, nil
+}
@@ -0,0 +1,147 @@
+package importer
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/repository/repotest"
+	"xis-data-aggregator/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const csvFile = `ts,data,series,type,labels
+1,1;2;3,cpu,,"{""host"":""a""}"
+2,4.5,cpu,float64,
+3,,cpu,,
+1970-01-01T00:00:04Z,7,mem,,
+5s,8;9,mem,,{bad
+6000,x,mem,,
+`
+
+func TestImportCSV(t *testing.T) {
+	svc := service.NewDataService(repotest.New(t))
+
+	var batches []Progress
+	progress, err := Import(strings.NewReader(csvFile), svc.NewImport(false), Options{
+		Format:    FormatCSV,
+		Precision: models.PrecisionSeconds,
+		BatchSize: 2,
+		Progress:  func(p Progress) error { batches = append(batches, p); return nil },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(6), progress.Records)
+	assert.Equal(t, int64(2), progress.Imported)
+	assert.Equal(t, int64(4), progress.Failed)
+	records := make([]int64, len(progress.Errors))
+	for i, e := range progress.Errors {
+		records[i] = e.Record
+	}
+	assert.ElementsMatch(t, []int64{2, 3, 5, 6}, records)
+	assert.Contains(t, progress.Errors[0].Error, `series "cpu" has value type int64`) // fixed by record 1
+	require.Len(t, batches, 2)
+	assert.Equal(t, int64(4), batches[1].Records)
+
+	data, err := svc.ListByPeriod(0, math.MaxInt64, nil, models.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, data, 2)
+	assert.Equal(t, int64(1_000_000), data[0].Timestamp)
+	assert.Equal(t, models.IntValue(3), data[0].Max)
+	assert.Equal(t, map[string]string{"host": "a"}, data[0].Labels)
+	assert.Equal(t, "mem", data[1].Series)
+}
+
+const ndjsonFile = `{"ts":1,"data":[1],"series":"cpu"}
+
+{"ts":2,"data":[2],"series":"cpu"}
+{"ts":3,"data":
+{"ts":4,"data":[4.5],"series":"cpu","type":"float64"}
+{"ts":5,"data":[5],"series":"cpu"}`
+
+func TestImportNDJSON(t *testing.T) {
+	tests := []struct {
+		dryRun     bool
+		checkpoint int64
+		imported   int64
+		failed     int64
+		stored     int
+	}{
+		{false, 0, 3, 2, 3},
+		{false, 2, 2, 1, 2}, // resumed after the second record, cpu becomes float64 by record 4
+		{true, 0, 3, 2, 0},
+	}
+
+	for _, tt := range tests {
+		svc := service.NewDataService(repotest.New(t))
+		progress, err := Import(strings.NewReader(ndjsonFile), svc.NewImport(tt.dryRun), Options{
+			Format:     FormatNDJSON,
+			Precision:  models.PrecisionMicroseconds,
+			Checkpoint: tt.checkpoint,
+		})
+		require.NoError(t, err, "%+v", tt)
+
+		assert.Equal(t, int64(5), progress.Records, "%+v", tt)
+		assert.Equal(t, tt.checkpoint, progress.Skipped, "%+v", tt)
+		assert.Equal(t, tt.imported, progress.Imported, "%+v", tt)
+		assert.Equal(t, tt.failed, progress.Failed, "%+v", tt)
+
+		data, err := svc.ListByPeriod(0, math.MaxInt64, nil, models.ListOptions{})
+		if tt.stored == 0 {
+			assert.Error(t, err, "%+v: nothing stored", tt)
+			continue
+		}
+		require.NoError(t, err, "%+v", tt)
+		assert.Len(t, data, tt.stored, "%+v", tt)
+	}
+}
+
+type failingSink struct{}
+
+func (failingSink) ImportPacks([]*models.Pack) ([]error, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestImportSeriesTypes(t *testing.T) {
+	svc := service.NewDataService(repotest.New(t))
+	imp := svc.NewImport(false)
+
+	// An invalid first pack doesn't fix the series type, the first valid one does for the rest of the batch
+	errs, err := imp.ImportPacks([]*models.Pack{
+		{Timestamp: 1, Series: "temp", Data: models.IntValues([]int64{1}), Labels: map[string]string{"bad label": "x"}},
+		{Timestamp: 2, Series: "temp", Data: models.FloatValues([]float64{1.5}), ValueType: models.ValueTypeFloat64},
+		{Timestamp: 3, Series: "temp", Data: models.FloatValues([]float64{2.5})},
+		{Timestamp: 4, Series: "temp", Data: models.IntValues([]int64{3}), ValueType: models.ValueTypeInt64},
+	})
+	require.NoError(t, err)
+	require.Len(t, errs, 4)
+	assert.Error(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.NoError(t, errs[2])
+	assert.ErrorContains(t, errs[3], "has value type float64")
+
+	// The stored type applies to later batches and ingested packs
+	_, err = svc.Ingest(&models.Pack{Timestamp: 5, Series: "temp", Data: models.IntValues([]int64{4}), ValueType: models.ValueTypeInt64})
+	assert.ErrorIs(t, err, service.ErrInvalidPack)
+}
+
+func TestImportErrors(t *testing.T) {
+	_, err := Import(strings.NewReader("ts,series\n1,cpu\n"), failingSink{}, Options{Format: FormatCSV})
+	assert.ErrorContains(t, err, `no "data" column`)
+
+	progress, err := Import(strings.NewReader(ndjsonFile), failingSink{}, Options{Format: FormatNDJSON, Checkpoint: 1})
+	assert.ErrorIs(t, err, ErrStore)
+	assert.Equal(t, int64(1), progress.Records, "the checkpoint is kept")
+
+	_, err = Import(strings.NewReader(""), failingSink{}, Options{Format: "xml"})
+	assert.Error(t, err)
+
+	f, err := FormatOf("history/2024-01.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, FormatNDJSON, f)
+	_, err = FormatOf("packs.txt")
+	assert.Error(t, err)
+}
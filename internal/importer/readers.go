@@ -0,0 +1,161 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"xis-data-aggregator/internal/api"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// csvReader reads packs from CSV with a header row. The ts column is RFC3339, unit-suffixed or an integer in the
+// file precision; data holds the samples separated by ';'; labels is a JSON object.
+type csvReader struct {
+	r         *csv.Reader
+	precision models.TimestampPrecision
+	columns   map[string]int // Column index by name, read from the header
+}
+
+func newCSVReader(r io.Reader, precision models.TimestampPrecision) *csvReader {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	return &csvReader{r: cr, precision: precision}
+}
+
+// readHeader reads the header row; unknown columns are ignored.
+func (o *csvReader) readHeader() error {
+	header, err := o.r.Read()
+	switch {
+	case errors.Is(err, io.EOF):
+		return err
+	case err != nil:
+		return fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	o.columns = make(map[string]int, len(header))
+	for i, name := range header {
+		o.columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"ts", "data"} {
+		if _, ok := o.columns[name]; !ok {
+			return fmt.Errorf("invalid CSV header: no %q column", name)
+		}
+	}
+	return nil
+}
+
+func (o *csvReader) next() (*models.Pack, error) {
+	if o.columns == nil {
+		if err := o.readHeader(); err != nil {
+			return nil, err
+		}
+	}
+
+	record, err := o.r.Read()
+	var parseErr *csv.ParseError
+	switch {
+	case errors.As(err, &parseErr):
+		return nil, recordError{err}
+	case err != nil:
+		return nil, err
+	}
+
+	pack, err := o.parse(record)
+	if err != nil {
+		return nil, recordError{err}
+	}
+	return pack, nil
+}
+
+// parse converts a CSV record to a pack.
+func (o *csvReader) parse(record []string) (*models.Pack, error) {
+	column := func(name string) string {
+		if i, ok := o.columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var pack models.Pack
+	var err error
+
+	if s := column("id"); s != "" {
+		if pack.ID, err = uuid.Parse(s); err != nil {
+			return nil, fmt.Errorf("invalid id %q", s)
+		}
+	}
+
+	if pack.Timestamp, err = api.ParseTimestamp(column("ts"), o.precision); err != nil {
+		return nil, err
+	}
+
+	if s := column("data"); s != "" {
+		for _, sample := range strings.Split(s, ";") {
+			var v models.Value
+			if err := v.UnmarshalJSON([]byte(strings.TrimSpace(sample))); err != nil {
+				return nil, fmt.Errorf("invalid sample %q", sample)
+			}
+			pack.Data = append(pack.Data, v)
+		}
+	}
+
+	if s := column("type"); s != "" {
+		if pack.ValueType, err = models.ParseValueType(s); err != nil {
+			return nil, err
+		}
+	}
+
+	pack.Series = column("series")
+
+	if s := column("labels"); s != "" {
+		if err := json.Unmarshal([]byte(s), &pack.Labels); err != nil {
+			return nil, fmt.Errorf("invalid labels %q: expected a JSON object", s)
+		}
+	}
+
+	return &pack, nil
+}
+
+// ndjsonReader reads packs from one JSON object per line with integer timestamps in the file precision.
+// Blank lines are skipped.
+type ndjsonReader struct {
+	r         *bufio.Reader
+	precision models.TimestampPrecision
+}
+
+func newNDJSONReader(r io.Reader, precision models.TimestampPrecision) *ndjsonReader {
+	return &ndjsonReader{r: bufio.NewReader(r), precision: precision}
+}
+
+func (o *ndjsonReader) next() (*models.Pack, error) {
+	for {
+		line, err := o.r.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			if err != nil {
+				return nil, err // io.EOF
+			}
+			continue
+		}
+
+		var pack models.Pack
+		if err := json.Unmarshal(line, &pack); err != nil {
+			return nil, recordError{fmt.Errorf("invalid pack: %v", err)}
+		}
+		if pack.Timestamp, err = o.precision.ToStored(pack.Timestamp); err != nil {
+			return nil, recordError{fmt.Errorf("invalid pack: %v", err)}
+		}
+		return &pack, nil
+	}
+}
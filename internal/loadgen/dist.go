@@ -0,0 +1,159 @@
+// Package loadgen generates synthetic Pack traffic with configurable rate profiles and length and value
+// distributions, and drives an ingestion API with it while measuring throughput and latency.
+package loadgen
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LengthDist draws the number of samples of a pack.
+type LengthDist interface {
+	Length(rng *rand.Rand) int
+}
+
+// ValueDist draws the value of a sample of a series, elapsed since the start of the run.
+type ValueDist interface {
+	Value(rng *rand.Rand, elapsed time.Duration) float64
+}
+
+type fixedLength int
+
+func (d fixedLength) Length(*rand.Rand) int {
+	return int(d)
+}
+
+type uniformLength struct {
+	min, max int
+}
+
+func (d uniformLength) Length(rng *rand.Rand) int {
+	return d.min + rng.Intn(d.max-d.min+1)
+}
+
+type normalLength struct {
+	mean, stddev float64
+}
+
+func (d normalLength) Length(rng *rand.Rand) int {
+	return max(1, int(math.Round(d.mean+rng.NormFloat64()*d.stddev)))
+}
+
+// ParseLengthDist parses a pack length distribution:
+//
+//	N                  - fixed, N samples
+//	uniform:MIN-MAX    - uniform between MIN and MAX samples, inclusive
+//	normal:MEAN,STDDEV - normal, rounded, at least 1 sample
+func ParseLengthDist(s string) (LengthDist, error) {
+	kind, params, ok := strings.Cut(s, ":")
+	if !ok {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid pack length %q", s)
+		}
+		return fixedLength(n), nil
+	}
+
+	switch kind {
+	case "uniform":
+		lo, hi, ok := strings.Cut(params, "-")
+		minLen, err1 := strconv.Atoi(lo)
+		maxLen, err2 := strconv.Atoi(hi)
+		if !ok || err1 != nil || err2 != nil || minLen < 1 || maxLen < minLen {
+			return nil, fmt.Errorf("invalid uniform pack length %q, expected uniform:MIN-MAX", s)
+		}
+		return uniformLength{minLen, maxLen}, nil
+	case "normal":
+		v, err := parseFloats(params, 2)
+		if err != nil || v[0] < 1 || v[1] < 0 {
+			return nil, fmt.Errorf("invalid normal pack length %q, expected normal:MEAN,STDDEV", s)
+		}
+		return normalLength{v[0], v[1]}, nil
+	}
+	return nil, fmt.Errorf("invalid pack length distribution %q", kind)
+}
+
+// normalValues are normally distributed around a mean.
+type normalValues struct {
+	mean, stddev float64
+}
+
+func (d normalValues) Value(rng *rand.Rand, _ time.Duration) float64 {
+	return d.mean + rng.NormFloat64()*d.stddev
+}
+
+// spikeValues are normal values, of which a fraction is multiplied by a spike factor.
+type spikeValues struct {
+	normalValues
+	probability, factor float64
+}
+
+func (d spikeValues) Value(rng *rand.Rand, elapsed time.Duration) float64 {
+	v := d.normalValues.Value(rng, elapsed)
+	if rng.Float64() < d.probability {
+		v *= d.factor
+	}
+	return v
+}
+
+// trendValues grow linearly with the elapsed time, with normal noise.
+type trendValues struct {
+	start, slope, stddev float64 // slope per second
+}
+
+func (d trendValues) Value(rng *rand.Rand, elapsed time.Duration) float64 {
+	return d.start + d.slope*elapsed.Seconds() + rng.NormFloat64()*d.stddev
+}
+
+// ParseValueDist parses a sample value distribution:
+//
+//	normal:MEAN,STDDEV                - normal
+//	spikes:MEAN,STDDEV,PROB,FACTOR    - normal, a PROB fraction of the samples multiplied by FACTOR
+//	trend:START,SLOPE,STDDEV          - START + SLOPE per second elapsed, with normal noise
+func ParseValueDist(s string) (ValueDist, error) {
+	kind, params, _ := strings.Cut(s, ":")
+
+	switch kind {
+	case "normal":
+		v, err := parseFloats(params, 2)
+		if err != nil || v[1] < 0 {
+			return nil, fmt.Errorf("invalid normal values %q, expected normal:MEAN,STDDEV", s)
+		}
+		return normalValues{v[0], v[1]}, nil
+	case "spikes":
+		v, err := parseFloats(params, 4)
+		if err != nil || v[1] < 0 || v[2] < 0 || v[2] > 1 {
+			return nil, fmt.Errorf("invalid spike values %q, expected spikes:MEAN,STDDEV,PROB,FACTOR", s)
+		}
+		return spikeValues{normalValues{v[0], v[1]}, v[2], v[3]}, nil
+	case "trend":
+		v, err := parseFloats(params, 3)
+		if err != nil || v[2] < 0 {
+			return nil, fmt.Errorf("invalid trend values %q, expected trend:START,SLOPE,STDDEV", s)
+		}
+		return trendValues{v[0], v[1], v[2]}, nil
+	}
+	return nil, fmt.Errorf("invalid value distribution %q", kind)
+}
+
+// parseFloats parses n comma separated numbers.
+func parseFloats(s string, n int) ([]float64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected %d numbers", n)
+	}
+
+	v := make([]float64, n)
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("invalid number %q", part)
+		}
+		v[i] = f
+	}
+	return v, nil
+}
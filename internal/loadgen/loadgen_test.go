@@ -0,0 +1,133 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDists(t *testing.T) {
+	tests := []struct {
+		name    string
+		length  string
+		values  string
+		wantErr bool
+	}{
+		{name: "fixed and normal", length: "10", values: "normal:500,50"},
+		{name: "uniform and spikes", length: "uniform:5-20", values: "spikes:500,50,0.01,10"},
+		{name: "normal and trend", length: "normal:10,3", values: "trend:100,0.5,10"},
+		{name: "zero length", length: "0", values: "normal:1,1", wantErr: true},
+		{name: "inverted uniform", length: "uniform:20-5", values: "normal:1,1", wantErr: true},
+		{name: "missing parameter", length: "1", values: "spikes:500,50,0.01", wantErr: true},
+		{name: "probability above 1", length: "1", values: "spikes:500,50,2,10", wantErr: true},
+		{name: "unknown distribution", length: "1", values: "pareto:1,2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, lengthErr := ParseLengthDist(tt.length)
+			_, valuesErr := ParseValueDist(tt.values)
+			assert.Equal(t, tt.wantErr, lengthErr != nil || valuesErr != nil)
+		})
+	}
+}
+
+func TestProfileRateAt(t *testing.T) {
+	p := Profile{Rate: 100, RampUp: 10 * time.Second, BurstEvery: time.Minute, BurstLength: 10 * time.Second, BurstFactor: 3}
+	require.NoError(t, p.Validate())
+
+	tests := []struct {
+		elapsed time.Duration
+		want    float64
+	}{
+		{0, 0},
+		{5 * time.Second, 50},
+		{30 * time.Second, 100},
+		{55 * time.Second, 300},
+		{time.Minute, 100},
+	}
+	for _, tt := range tests {
+		assert.InDelta(t, tt.want, p.RateAt(tt.elapsed), 1e-9, tt.elapsed.String())
+	}
+
+	assert.Error(t, Profile{Rate: 100, BurstEvery: time.Second, BurstLength: time.Second, BurstFactor: 2}.Validate())
+}
+
+func TestGeneratorSeed(t *testing.T) {
+	length, err := ParseLengthDist("uniform:1-8")
+	require.NoError(t, err)
+	values, err := ParseValueDist("spikes:500,50,0.1,10")
+	require.NoError(t, err)
+
+	now := time.Now()
+	a := NewGenerator(42, length, values, []string{"a", "b"}, false, true)
+	b := NewGenerator(42, length, values, []string{"a", "b"}, false, true)
+	c := NewGenerator(42, length, values, []string{"a", "b"}, false, false)
+	for i := range 10 {
+		pa, pb, pc := a.Pack(now, 0), b.Pack(now, 0), c.Pack(now, 0)
+		assert.Equal(t, pa, pb, "replayed IDs")
+		assert.NotEqual(t, pa.ID, pc.ID, "random IDs")
+		pc.ID = pa.ID
+		assert.Equal(t, pa, pc, "same lengths and values")
+		assert.Equal(t, []string{"a", "b"}[i%2], pa.Series)
+		assert.NoError(t, pa.Validate())
+	}
+
+	d := NewGenerator(43, length, values, nil, true, true)
+	assert.NotEqual(t, a.Pack(now, 0).ID, d.Pack(now, 0).ID)
+}
+
+func TestPercentiles(t *testing.T) {
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[len(latencies)-1-i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	assert.Equal(t, Latency{
+		P50: 51 * time.Millisecond,
+		P90: 90 * time.Millisecond,
+		P95: 95 * time.Millisecond,
+		P99: 99 * time.Millisecond,
+		Max: 100 * time.Millisecond,
+	}, Percentiles(latencies))
+	assert.Equal(t, Latency{}, Percentiles(nil))
+}
+
+func TestRun(t *testing.T) {
+	length, _ := ParseLengthDist("3")
+	values, _ := ParseValueDist("normal:10,1")
+
+	var mu sync.Mutex
+	var received []*models.Pack
+	send := func(_ context.Context, packs []*models.Pack) ([]error, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, packs...)
+		if len(received) > 40 {
+			return nil, errors.New("unavailable")
+		}
+		return make([]error, len(packs)), nil
+	}
+
+	report, err := Run(context.Background(), send, Options{
+		Profile:   Profile{Rate: 1000},
+		Generator: NewGenerator(1, length, values, nil, false, false),
+		Count:     50,
+		Batch:     5,
+		Workers:   2,
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, received, 50)
+	assert.EqualValues(t, 50, report.Packs)
+	assert.GreaterOrEqual(t, report.Requests, int64(10))
+	assert.Positive(t, report.Failed)
+	assert.Equal(t, report.Failed, report.Errors["unavailable"])
+	assert.Positive(t, report.Throughput)
+}
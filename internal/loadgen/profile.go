@@ -0,0 +1,113 @@
+package loadgen
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Profile is the target pack rate over the run: a linear ramp-up to Rate, with periodic bursts.
+type Profile struct {
+	Rate        float64       // Target packs per second after the ramp-up
+	RampUp      time.Duration // Duration of the linear increase from 0 to Rate, 0 - none
+	BurstEvery  time.Duration // Period of the bursts, 0 - none
+	BurstLength time.Duration // Duration of a burst, at the end of each period
+	BurstFactor float64       // Rate multiplier during a burst
+}
+
+// Validate checks that the profile has a positive rate and consistent bursts.
+func (p Profile) Validate() error {
+	if p.Rate <= 0 || math.IsInf(p.Rate, 0) {
+		return errors.New("rate must be positive")
+	}
+	if p.RampUp < 0 {
+		return errors.New("ramp-up must not be negative")
+	}
+	if p.BurstEvery > 0 && (p.BurstLength <= 0 || p.BurstLength >= p.BurstEvery) {
+		return fmt.Errorf("burst length must be between 0 and the burst period %s", p.BurstEvery)
+	}
+	if p.BurstEvery > 0 && p.BurstFactor <= 0 {
+		return errors.New("burst factor must be positive")
+	}
+	return nil
+}
+
+// RateAt returns the target packs per second at the elapsed time of the run.
+func (p Profile) RateAt(elapsed time.Duration) float64 {
+	rate := p.Rate
+	if elapsed < p.RampUp {
+		rate *= float64(elapsed) / float64(p.RampUp)
+	}
+	if p.BurstEvery > 0 && elapsed%p.BurstEvery >= p.BurstEvery-p.BurstLength {
+		rate *= p.BurstFactor
+	}
+	return rate
+}
+
+// Generator generates the packs of a run, round-robin over its series. The same seed generates the same lengths
+// and values; only timestamps and trends depend on when the packs are generated. IDs are random unless replayed
+// from the seed too, so that a rerun stores new records rather than overwriting those of the previous run. It is
+// not safe for concurrent use.
+type Generator struct {
+	rng       *rand.Rand
+	ids       *rand.Rand // Source of the replayed IDs, nil - random IDs
+	length    LengthDist
+	values    ValueDist
+	series    []string
+	valueType models.ValueType
+	next      int
+}
+
+// NewGenerator creates a generator of packs of the given series, of float64 or int64 values, with IDs generated
+// from the seed if replayIDs.
+func NewGenerator(seed int64, length LengthDist, values ValueDist, series []string, float, replayIDs bool) *Generator {
+	valueType := models.ValueTypeInt64
+	if float {
+		valueType = models.ValueTypeFloat64
+	}
+	g := &Generator{
+		rng:       rand.New(rand.NewSource(seed)),
+		length:    length,
+		values:    values,
+		series:    series,
+		valueType: valueType,
+	}
+	if replayIDs {
+		g.ids = rand.New(rand.NewSource(^seed)) // apart from rng, so that lengths and values do not depend on it
+	}
+	return g
+}
+
+// Pack generates the next pack, at the given time elapsed since the start of the run.
+func (g *Generator) Pack(now time.Time, elapsed time.Duration) *models.Pack {
+	id := uuid.New()
+	if g.ids != nil {
+		id, _ = uuid.NewRandomFromReader(g.ids) // a rand.Rand never fails to read
+	}
+
+	pack := &models.Pack{
+		ID:        id,
+		Timestamp: models.Timestamp(now),
+		Data:      make([]models.Value, g.length.Length(g.rng)),
+		ValueType: g.valueType,
+	}
+	if len(g.series) > 0 {
+		pack.Series = g.series[g.next%len(g.series)]
+		g.next++
+	}
+
+	for i := range pack.Data {
+		v := g.values.Value(g.rng, elapsed)
+		if g.valueType == models.ValueTypeFloat64 {
+			pack.Data[i] = models.FloatValue(v)
+		} else {
+			pack.Data[i] = models.IntValue(int64(math.Round(v)))
+		}
+	}
+	return pack
+}
@@ -0,0 +1,247 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+	"xis-data-aggregator/internal/models"
+)
+
+// tick is the scheduling resolution of the pack rate.
+const tick = 5 * time.Millisecond
+
+// Sender submits packs to the ingestion API, returning the error of each rejected pack, or the error of the
+// whole request.
+type Sender func(ctx context.Context, packs []*models.Pack) ([]error, error)
+
+// Options configure a run.
+type Options struct {
+	Profile   Profile
+	Generator *Generator
+	Duration  time.Duration // Duration of the run, 0 - until Count packs or cancelled
+	Count     int64         // Packs to send, 0 - until Duration elapsed or cancelled
+	Batch     int           // Maximum packs per request; packs due at once are batched
+	Workers   int           // Concurrent requests
+	Report    time.Duration // Interval of OnReport calls, 0 - none
+	OnReport  func(Report)  // Called with the statistics of each Report interval, and of the last partial one
+}
+
+// Latency are the percentiles of the request latencies.
+type Latency struct {
+	P50 time.Duration `json:"p50"`
+	P90 time.Duration `json:"p90"`
+	P95 time.Duration `json:"p95"`
+	P99 time.Duration `json:"p99"`
+	Max time.Duration `json:"max"`
+}
+
+// Report are the statistics of a run or of an interval of it.
+type Report struct {
+	Elapsed    time.Duration    `json:"elapsed"`          // Duration covered by the report
+	Packs      int64            `json:"packs"`            // Packs sent
+	Requests   int64            `json:"requests"`         // Requests sent
+	Failed     int64            `json:"failed"`           // Packs rejected or lost with their request
+	Missed     int64            `json:"missed"`           // Packs of the profile not sent because the workers were busy
+	Throughput float64          `json:"throughput"`       // Accepted packs per second
+	Latency    Latency          `json:"latency"`          // Request latency
+	Errors     map[string]int64 `json:"errors,omitempty"` // Failed packs by error message
+}
+
+// stats accumulates the results of the requests.
+type stats struct {
+	mu        sync.Mutex
+	report    Report
+	latencies []time.Duration
+}
+
+func (s *stats) add(packs int, latency time.Duration, errs []error, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.report.Packs += int64(packs)
+	s.report.Requests++
+	s.latencies = append(s.latencies, latency)
+
+	if err != nil {
+		s.report.Failed += int64(packs)
+		s.addError(err, int64(packs))
+		return
+	}
+	for _, err := range errs {
+		if err != nil {
+			s.report.Failed++
+			s.addError(err, 1)
+		}
+	}
+}
+
+func (s *stats) addError(err error, packs int64) {
+	if s.report.Errors == nil {
+		s.report.Errors = map[string]int64{}
+	}
+	s.report.Errors[err.Error()] += packs
+}
+
+func (s *stats) addMissed(n int64) {
+	s.mu.Lock()
+	s.report.Missed += n
+	s.mu.Unlock()
+}
+
+// take returns the report of the accumulated results over elapsed, and its latencies.
+func (s *stats) take(elapsed time.Duration) (Report, []time.Duration) {
+	s.mu.Lock()
+	r, latencies := s.report, s.latencies
+	s.report, s.latencies = Report{}, nil
+	s.mu.Unlock()
+
+	r.Elapsed = elapsed
+	if elapsed > 0 {
+		r.Throughput = float64(r.Packs-r.Failed) / elapsed.Seconds()
+	}
+	r.Latency = Percentiles(latencies)
+	return r, latencies
+}
+
+// Percentiles returns the percentiles of latencies, sorting them.
+func Percentiles(latencies []time.Duration) Latency {
+	if len(latencies) == 0 {
+		return Latency{}
+	}
+	slices.Sort(latencies)
+
+	at := func(p float64) time.Duration {
+		return latencies[int(p*float64(len(latencies)-1)+0.5)]
+	}
+	return Latency{P50: at(0.5), P90: at(0.9), P95: at(0.95), P99: at(0.99), Max: latencies[len(latencies)-1]}
+}
+
+// merge adds an interval report to the run total.
+func (r *Report) merge(interval Report) {
+	r.Packs += interval.Packs
+	r.Requests += interval.Requests
+	r.Failed += interval.Failed
+	r.Missed += interval.Missed
+	for msg, n := range interval.Errors {
+		if r.Errors == nil {
+			r.Errors = map[string]int64{}
+		}
+		r.Errors[msg] += n
+	}
+}
+
+// Run sends the packs of the generator at the rate of the profile until the duration elapsed, the count is
+// reached or ctx is cancelled, and returns the statistics of the whole run. When all workers are busy, packs
+// due are held back up to a batch per worker and two ticks of the rate, and any further are counted as missed rather than sent late.
+// Requests in flight when ctx is cancelled are completed.
+func Run(ctx context.Context, send Sender, opts Options) (Report, error) {
+	if err := opts.Profile.Validate(); err != nil {
+		return Report{}, err
+	}
+	if opts.Generator == nil {
+		return Report{}, errors.New("no generator")
+	}
+	opts.Batch = max(opts.Batch, 1)
+	opts.Workers = max(opts.Workers, 1)
+
+	sendCtx := context.WithoutCancel(ctx)
+	var s stats
+	batches := make(chan []*models.Pack)
+	var wg sync.WaitGroup
+	for range opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for packs := range batches {
+				t0 := time.Now()
+				errs, err := send(sendCtx, packs)
+				s.add(len(packs), time.Since(t0), errs, err)
+			}
+		}()
+	}
+
+	start := time.Now()
+	var total Report
+	var latencies []time.Duration
+	lastReport := start
+	report := func(now time.Time) {
+		interval, l := s.take(now.Sub(lastReport))
+		lastReport = now
+		total.merge(interval)
+		latencies = append(latencies, l...)
+		if opts.Report > 0 && opts.OnReport != nil {
+			opts.OnReport(interval)
+		}
+	}
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	var reports <-chan time.Time
+	if opts.Report > 0 {
+		reportTicker := time.NewTicker(opts.Report)
+		defer reportTicker.Stop()
+		reports = reportTicker.C
+	}
+
+	var sent int64
+	var credit float64 // Packs due and not sent yet
+	last := start
+loop:
+	for opts.Count == 0 || sent < opts.Count {
+		var now time.Time
+		select {
+		case <-ctx.Done():
+			break loop
+		case now = <-reports:
+			report(now)
+			continue
+		case now = <-ticker.C:
+		}
+
+		elapsed := now.Sub(start)
+		if opts.Duration > 0 && elapsed >= opts.Duration {
+			break loop
+		}
+		rate := opts.Profile.RateAt(elapsed)
+		credit += rate * now.Sub(last).Seconds()
+		last = now
+		// A batch per worker and the packs of a couple of ticks can be due at once
+		limit := float64(opts.Batch*opts.Workers) + rate*(2*tick).Seconds()
+		if credit > limit {
+			s.addMissed(int64(credit - limit))
+			credit -= float64(int64(credit - limit))
+		}
+
+		for credit >= 1 && (opts.Count == 0 || sent < opts.Count) {
+			n := min(int64(credit), int64(opts.Batch))
+			if opts.Count > 0 {
+				n = min(n, opts.Count-sent)
+			}
+			packs := make([]*models.Pack, n)
+			for i := range packs {
+				packs[i] = opts.Generator.Pack(now, elapsed)
+			}
+
+			select {
+			case batches <- packs:
+			case <-ctx.Done():
+				break loop
+			}
+			credit -= float64(n)
+			sent += n
+		}
+	}
+
+	close(batches)
+	wg.Wait()
+	report(time.Now())
+
+	total.Elapsed = time.Since(start)
+	if total.Elapsed > 0 {
+		total.Throughput = float64(total.Packs-total.Failed) / total.Elapsed.Seconds()
+	}
+	total.Latency = Percentiles(latencies)
+	return total, nil
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import "sync"
+
+// TenantCounters holds per tenant ingestion and query counters.
+type TenantCounters struct {
+	// Ingested is the number of successfully stored packs.
+	Ingested int64 `json:"ingested"`
+	// Failed is the number of packs that failed to be processed or stored.
+	Failed int64 `json:"failed"`
+	// Queries is the number of read queries.
+	Queries int64 `json:"queries"`
+}
+
+// TenantStats collects TenantCounters per tenant. It is safe for concurrent use.
+type TenantStats struct {
+	mu       sync.Mutex
+	counters map[string]*TenantCounters
+}
+
+// NewTenantStats creates an empty TenantStats.
+func NewTenantStats() *TenantStats {
+	return &TenantStats{counters: make(map[string]*TenantCounters)}
+}
+
+// get returns the counters of the tenant, creating them if needed. Must be called with mu held.
+func (s *TenantStats) get(tenant string) *TenantCounters {
+	c, ok := s.counters[tenant]
+	if !ok {
+		c = &TenantCounters{}
+		s.counters[tenant] = c
+	}
+	return c
+}
+
+// Ingested records a processing result for the tenant. A nil TenantStats ignores the call.
+func (s *TenantStats) Ingested(tenant string, ok bool) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ok {
+		s.get(tenant).Ingested++
+	} else {
+		s.get(tenant).Failed++
+	}
+}
+
+// Queried records a read query for the tenant. A nil TenantStats ignores the call.
+func (s *TenantStats) Queried(tenant string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.get(tenant).Queries++
+}
+
+// Get returns a copy of the tenant counters.
+func (s *TenantStats) Get(tenant string) TenantCounters {
+	if s == nil {
+		return TenantCounters{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.counters[tenant]; ok {
+		return *c
+	}
+	return TenantCounters{}
+}
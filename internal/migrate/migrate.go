@@ -0,0 +1,208 @@
+// Package migrate copies the Data records of a repository to another one in parallel time range chunks,
+// verifying the record count and checksum of every chunk in the destination.
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"xis-data-aggregator/internal/api"
+	"xis-data-aggregator/internal/models"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// batchSize is the number of records read and written per repository call.
+const batchSize = 1000
+
+// ErrMismatch is returned when the records of a chunk in the destination differ from the source.
+var ErrMismatch = errors.New("chunk verification failed")
+
+// Chunk is the time range [From, To) of a tenant's records.
+type Chunk struct {
+	Tenant string `json:"tenant"`
+	From   int64  `json:"from"`
+	To     int64  `json:"to"`
+}
+
+// Key identifies the chunk in checkpoints.
+func (c Chunk) Key() string {
+	return fmt.Sprintf("%s/%d/%d", c.Tenant, c.From, c.To)
+}
+
+// Result is a migrated and verified chunk.
+type Result struct {
+	Chunk
+	Records  int64  `json:"records"`
+	Checksum string `json:"checksum"`
+}
+
+// Chunks divides [from, to) into chunks of size for every tenant, in tenant order.
+func Chunks(tenants []string, from, to, size int64) ([]Chunk, error) {
+	if from >= to {
+		return nil, fmt.Errorf("invalid time range: 'from' must be less than 'to'")
+	}
+	if size <= 0 {
+		return nil, fmt.Errorf("chunk size must be positive")
+	}
+
+	var chunks []Chunk
+	for _, tenant := range tenants {
+		for start := from; start < to; {
+			end := to
+			if to-start > size {
+				end = start + size
+			}
+			chunks = append(chunks, Chunk{Tenant: tenant, From: start, To: end})
+			start = end
+		}
+	}
+	return chunks, nil
+}
+
+// errStop stops a scan early.
+var errStop = errors.New("stop")
+
+// Earliest returns the timestamp of the earliest record of the tenants in repo, false if they have none.
+func Earliest(repo models.Repository, tenants []string) (int64, bool, error) {
+	var earliest int64
+	var found bool
+
+	for _, tenant := range tenants {
+		err := repo.ForTenant(tenant).ScanByPeriod(math.MinInt64, math.MaxInt64, 1, func(batch []models.Data) error {
+			if ts := batch[0].Timestamp; !found || ts < earliest {
+				earliest, found = ts, true
+			}
+			return errStop
+		})
+		if err != nil && !errors.Is(err, errStop) {
+			return 0, false, fmt.Errorf("tenant %q: %w", tenant, err)
+		}
+	}
+	return earliest, found, nil
+}
+
+// digest is an order independent checksum of a set of records: the lane-wise sum of their SHA-256 digests.
+type digest [4]uint64
+
+// add adds a record to the checksum.
+func (d *digest) add(data *models.Data) error {
+	pbData, err := api.DataToProto(data)
+	if err != nil {
+		return err
+	}
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(pbData)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(b)
+	for i := range d {
+		d[i] += binary.BigEndian.Uint64(sum[i*8:])
+	}
+	return nil
+}
+
+func (d *digest) String() string {
+	var b [32]byte
+	for i, lane := range d {
+		binary.BigEndian.PutUint64(b[i*8:], lane)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// scan passes the tenant records of the chunk to fn in batches, adding them to the returned count and checksum.
+func scan(repo models.Repository, c Chunk, fn func([]*models.Data) error) (int64, string, error) {
+	var count int64
+	var sum digest
+
+	err := repo.ForTenant(c.Tenant).ScanByPeriod(c.From, c.To-1, batchSize, func(batch []models.Data) error {
+		ptrs := make([]*models.Data, len(batch))
+		for i := range batch {
+			batch[i].Tenant = c.Tenant
+			if err := sum.add(&batch[i]); err != nil {
+				return err
+			}
+			ptrs[i] = &batch[i]
+		}
+		count += int64(len(batch))
+		return fn(ptrs)
+	})
+	return count, sum.String(), err
+}
+
+// MigrateChunk copies the records of the chunk from src to dst and verifies that the destination holds the
+// same records in the chunk: the same count and checksum. Records of the destination outside the source, e.g.
+// written before, fail the verification with ErrMismatch. Copying a chunk again overwrites the same records.
+func MigrateChunk(src, dst models.Repository, c Chunk) (Result, error) {
+	result := Result{Chunk: c}
+	target := dst.ForTenant(c.Tenant)
+
+	records, checksum, err := scan(src, c, target.PutBatch)
+	if err != nil {
+		return result, fmt.Errorf("chunk %s: %w", c.Key(), err)
+	}
+
+	copied, copiedChecksum, err := scan(dst, c, func([]*models.Data) error { return nil })
+	if err != nil {
+		return result, fmt.Errorf("chunk %s verification: %w", c.Key(), err)
+	}
+	if copied != records || copiedChecksum != checksum {
+		return result, fmt.Errorf("%w: chunk %s: source %d records %s, destination %d records %s",
+			ErrMismatch, c.Key(), records, checksum, copied, copiedChecksum)
+	}
+
+	result.Records, result.Checksum = records, checksum
+	return result, nil
+}
+
+// Run migrates the chunks with the given number of parallel workers, calling done (serialized) with every
+// verified chunk. Stops starting chunks at the first error, waits for the running ones and returns the error.
+func Run(src, dst models.Repository, chunks []Chunk, parallel int, done func(Result)) error {
+	parallel = max(parallel, 1)
+
+	queue := make(chan Chunk)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil
+	}
+
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range queue {
+				result, err := MigrateChunk(src, dst, c)
+
+				mu.Lock()
+				switch {
+				case err != nil && firstErr == nil:
+					firstErr = err
+				case err == nil && done != nil:
+					done(result)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, c := range chunks {
+		if failed() {
+			break
+		}
+		queue <- c
+	}
+	close(queue)
+	wg.Wait()
+
+	return firstErr
+}
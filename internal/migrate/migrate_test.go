@@ -0,0 +1,89 @@
+package migrate
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/repository/repotest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunks(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to int64
+		size     int64
+		want     []Chunk
+		wantErr  bool
+	}{
+		{"exact", 0, 20, 10, []Chunk{{"a", 0, 10}, {"a", 10, 20}}, false},
+		{"partial last", 0, 25, 10, []Chunk{{"a", 0, 10}, {"a", 10, 20}, {"a", 20, 25}}, false},
+		{"one", -5, 5, 100, []Chunk{{"a", -5, 5}}, false},
+		{"empty range", 5, 5, 10, nil, true},
+		{"zero size", 0, 5, 0, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks, err := Chunks([]string{"a"}, tt.from, tt.to, tt.size)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, chunks)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	src := repotest.New(t)
+	for i := int64(0); i < 3000; i++ {
+		require.NoError(t, src.Put(&models.Data{ID: uuid.New(), Timestamp: i, Max: models.IntValue(i), Series: "cpu"}))
+	}
+	require.NoError(t, src.ForTenant("acme").Put(&models.Data{ID: uuid.New(), Timestamp: 7, Max: models.FloatValue(1.5),
+		Labels: map[string]string{"host": "a", "dc": "b"}}))
+
+	earliest, found, err := Earliest(src, []string{"", "acme"})
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, int64(0), earliest)
+
+	chunks, err := Chunks([]string{"", "acme"}, earliest, 3000, 700)
+	require.NoError(t, err)
+
+	// An interrupted migration resumed with the chunks not verified before
+	dst := repotest.New(t)
+	results := make(map[string]Result)
+	require.NoError(t, Run(src, dst, chunks[:3], 2, func(r Result) { results[r.Key()] = r }))
+	require.NoError(t, Run(src, dst, chunks[3:], 3, func(r Result) { results[r.Key()] = r }))
+
+	var records int64
+	for _, c := range chunks {
+		records += results[c.Key()].Records
+	}
+	assert.Equal(t, int64(3001), records)
+
+	data, err := dst.ListByPeriod(math.MinInt64, math.MaxInt64)
+	require.NoError(t, err)
+	assert.Len(t, data, 3000)
+	acme, err := dst.ForTenant("acme").ListByPeriod(math.MinInt64, math.MaxInt64)
+	require.NoError(t, err)
+	require.Len(t, acme, 1)
+	assert.Equal(t, map[string]string{"host": "a", "dc": "b"}, acme[0].Labels)
+
+	// Migrating a chunk again overwrites the same records
+	again, err := MigrateChunk(src, dst, chunks[0])
+	require.NoError(t, err)
+	assert.Equal(t, results[chunks[0].Key()], again)
+
+	// A record the source does not have fails the verification
+	require.NoError(t, dst.Put(&models.Data{ID: uuid.New(), Timestamp: 1, Max: models.IntValue(0)}))
+	_, err = MigrateChunk(src, dst, chunks[0])
+	assert.True(t, errors.Is(err, ErrMismatch), err)
+	assert.Error(t, Run(src, dst, chunks, 2, nil))
+}
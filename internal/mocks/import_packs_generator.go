@@ -2,7 +2,9 @@
 package mocks
 
 import (
+	"encoding/json"
 	"errors"
+	"io"
 	"math/rand"
 	"time"
 	"xis-data-aggregator/config"
@@ -19,6 +21,8 @@ const valueLimit = 1000 // limitations for ease of interpretation of results
 type InputPacksGenerator struct {
 	Interval   time.Duration       // Time interval between generated packs
 	PackLength int                 // Number of data points in each generated pack
+	Source     *PackSource         // Clock and random source of the packs, nil - the current time and the global source
+	Recorder   io.Writer           // Receives every generated pack as a line of JSON, optional
 	OutputChan chan<- *models.Pack // Channel for outputting generated packs
 	StopChan   chan struct{}       // Channel to signal generator to stop
 }
@@ -29,18 +33,34 @@ func (g *InputPacksGenerator) Start(cfg *config.XisDataAggregatorConfig) {
 	ticker := time.NewTicker(g.Interval)
 	defer ticker.Stop()
 
-	for {
+	source := g.Source
+	if source == nil {
+		source = &PackSource{}
+	}
+	var recorder *json.Encoder
+	if g.Recorder != nil {
+		recorder = json.NewEncoder(g.Recorder)
+	}
+
+	for n := 1; ; n++ {
 		select {
 		case <-ticker.C:
-			pack, err := GeneratePack(g.PackLength)
+			pack, err := source.Pack(g.PackLength)
 			if err != nil {
-				glog.Errorln("Error creating pack: %s", err)
+				glog.Errorf("Error creating pack: %s", err)
 				continue
 			}
 
+			if recorder != nil {
+				if err := recorder.Encode(pack); err != nil {
+					glog.Errorf("Error recording pack, recording stopped: %s", err)
+					recorder = nil
+				}
+			}
+
 			g.OutputChan <- pack
 
-			if rand.Int()%cfg.MetricsBatchSize == 0 {
+			if n%cfg.MetricsBatchSize == 0 {
 				glog.Infof("dbg: generated pack: %v\n", *pack)
 			}
 
@@ -55,29 +75,60 @@ func (g *InputPacksGenerator) Start(cfg *config.XisDataAggregatorConfig) {
 	}
 }
 
-// GeneratePack creates a new Pack with a unique ID, current timestamp, and a slice of random integers.
+// PackSource generates packs with the timestamps of a clock and the IDs and values of a random source, so that
+// a seeded source with a fixed clock generates the same packs on every run. It is not safe for concurrent use.
+type PackSource struct {
+	Now  func() time.Time // Clock of the pack timestamps, nil - time.Now
+	Rand *rand.Rand       // Random source of the IDs and values, nil - the global source
+}
+
+// NewPackSource creates a pack source seeded with seed, stamping packs with now (nil - time.Now).
+func NewPackSource(seed int64, now func() time.Time) *PackSource {
+	return &PackSource{Now: now, Rand: rand.New(rand.NewSource(seed))}
+}
+
+// Pack creates a new Pack with a unique ID, the current timestamp, and a slice of random integers.
 // Returns an error if dataLength is not positive.
-func GeneratePack(dataLength int) (*models.Pack, error) {
+func (s *PackSource) Pack(dataLength int) (*models.Pack, error) {
 	if dataLength <= 0 {
 		return nil, errors.New("dataLength must be a positive integer")
 	}
 
+	now, intn := time.Now, rand.Intn
+	id := uuid.New
+	if s.Now != nil {
+		now = s.Now
+	}
+	if s.Rand != nil {
+		intn = s.Rand.Intn
+		id = func() uuid.UUID {
+			id, _ := uuid.NewRandomFromReader(s.Rand) // a rand.Rand never fails to read
+			return id
+		}
+	}
+
 	// Generate uniq UUID for the pack
-	id := uuid.New()
+	packID := id()
 
-	// Set Timestamp (Unix microseconds for high precision)
-	timestamp := time.Now().UnixMicro()
+	// Set Timestamp in stored units (see models.TimestampUnit)
+	timestamp := models.Timestamp(now())
 
 	// Generate Data slice of random integers
 	data := make([]int, dataLength)
 	for i := 0; i < dataLength; i++ {
-		data[i] = rand.Intn(valueLimit)
+		data[i] = intn(valueLimit)
 	}
 
 	// Create and return the Pack instance
 	return &models.Pack{
-		ID:        id,
+		ID:        packID,
 		Timestamp: timestamp,
-		Data:      data,
+		Data:      models.IntValues(data),
 	}, nil
 }
+
+// GeneratePack creates a new Pack with a unique ID, current timestamp, and a slice of random integers.
+// Returns an error if dataLength is not positive.
+func GeneratePack(dataLength int) (*models.Pack, error) {
+	return (&PackSource{}).Pack(dataLength)
+}
@@ -0,0 +1,106 @@
+package mocks
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/golang/glog"
+)
+
+// Replayer feeds the packs of a recording of an InputPacksGenerator back to an output channel, spaced by the
+// differences of their timestamps divided by Speed. Packs are sent as recorded, with their IDs and timestamps.
+type Replayer struct {
+	Recording  io.Reader           // Packs as lines of JSON, with timestamps in stored units
+	Speed      float64             // Replay speed factor: 1 - original speed, 2 - twice as fast, 0 - without delays
+	OutputChan chan<- *models.Pack // Channel for outputting replayed packs
+	StopChan   chan struct{}       // Channel to signal replayer to stop
+}
+
+// Start replays the recording, then waits until StopChan is closed and closes OutputChan, like
+// InputPacksGenerator.Start. Stops replaying at the first malformed line.
+func (r *Replayer) Start() {
+	defer close(r.OutputChan)
+
+	packs, err := r.replay()
+	switch {
+	case errors.Is(err, errStopped):
+		glog.Infof("Pack replay stopped after %d packs.", packs)
+		return
+	case err != nil:
+		glog.Errorf("Pack replay failed after %d packs: %s", packs, err)
+	default:
+		glog.Infof("Pack replay finished, %d packs replayed.", packs)
+	}
+
+	<-r.StopChan
+	glog.Infoln("Pack replayer stopping.")
+}
+
+// errStopped is returned by replay when StopChan is closed.
+var errStopped = errors.New("stopped")
+
+// replay sends the packs of the recording and returns their number.
+func (r *Replayer) replay() (int, error) {
+	in := bufio.NewReader(r.Recording)
+	var start time.Time // Replay time of the first pack
+	var first int64     // Timestamp of the first pack
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for packs, line := 0, 1; ; line++ {
+		pack, err := readPack(in)
+		if errors.Is(err, io.EOF) {
+			return packs, nil
+		}
+		if err != nil {
+			return packs, fmt.Errorf("line %d: %w", line, err)
+		}
+		if pack == nil {
+			continue // blank line
+		}
+
+		if packs == 0 {
+			start, first = time.Now(), pack.Timestamp
+		} else if r.Speed > 0 {
+			offset := time.Duration(float64(pack.Timestamp-first) / r.Speed * float64(time.Microsecond))
+			timer.Reset(time.Until(start.Add(offset)))
+			select {
+			case <-timer.C:
+			case <-r.StopChan:
+				return packs, errStopped
+			}
+		}
+
+		select {
+		case r.OutputChan <- pack:
+			packs++
+		case <-r.StopChan:
+			return packs, errStopped
+		}
+	}
+}
+
+// readPack reads a line of the recording, nil for a blank line.
+func readPack(in *bufio.Reader) (*models.Pack, error) {
+	line, err := in.ReadBytes('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || len(line) == 0) {
+		return nil, err
+	}
+
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return nil, nil
+	}
+	var pack models.Pack
+	if err := json.Unmarshal(line, &pack); err != nil {
+		return nil, fmt.Errorf("invalid pack: %w", err)
+	}
+	return &pack, nil
+}
@@ -0,0 +1,103 @@
+package mocks
+
+import (
+	"bytes"
+	"testing"
+	"time"
+	"xis-data-aggregator/config"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackSourceSeed(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := func() time.Time { return clock }
+
+	a, b := NewPackSource(7, now), NewPackSource(7, now)
+	for range 5 {
+		pa, err := a.Pack(10)
+		require.NoError(t, err)
+		pb, err := b.Pack(10)
+		require.NoError(t, err)
+
+		assert.Equal(t, pa, pb)
+		assert.Equal(t, models.Timestamp(clock), pa.Timestamp)
+	}
+
+	other, err := NewPackSource(8, now).Pack(10)
+	require.NoError(t, err)
+	first, err := NewPackSource(7, now).Pack(10)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, other.ID)
+
+	_, err = a.Pack(0)
+	assert.Error(t, err)
+}
+
+func TestRecordReplay(t *testing.T) {
+	// Record packs 10ms apart on the generator clock
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	source := NewPackSource(1, func() time.Time {
+		clock = clock.Add(10 * time.Millisecond)
+		return clock
+	})
+
+	var recording bytes.Buffer
+	generated := make(chan *models.Pack)
+	stop := make(chan struct{})
+	generator := InputPacksGenerator{
+		Interval:   time.Millisecond,
+		PackLength: 3,
+		Source:     source,
+		Recorder:   &recording,
+		OutputChan: generated,
+		StopChan:   stop,
+	}
+	go generator.Start(&config.XisDataAggregatorConfig{MetricsBatchSize: 10})
+
+	var want []*models.Pack
+	for range 5 {
+		want = append(want, <-generated)
+	}
+	close(stop)
+	for pack := range generated { // every recorded pack is sent
+		want = append(want, pack)
+	}
+
+	tests := []struct {
+		name    string
+		speed   float64
+		minTime time.Duration
+	}{
+		{name: "without delays", speed: 0},
+		{name: "accelerated", speed: 2, minTime: time.Duration(len(want)-1) * 5 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replayed := make(chan *models.Pack)
+			stop := make(chan struct{})
+			replayer := Replayer{
+				Recording:  bytes.NewReader(recording.Bytes()),
+				Speed:      tt.speed,
+				OutputChan: replayed,
+				StopChan:   stop,
+			}
+
+			start := time.Now()
+			go replayer.Start()
+			var got []*models.Pack
+			for range want {
+				got = append(got, <-replayed)
+			}
+			assert.GreaterOrEqual(t, time.Since(start), tt.minTime)
+			assert.Equal(t, want, got)
+
+			close(stop)
+			_, open := <-replayed
+			assert.False(t, open)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Comparison operators of alert rules.
+const (
+	OpGreater      = ">"
+	OpGreaterEqual = ">="
+	OpLess         = "<"
+	OpLessEqual    = "<="
+)
+
+// Aggregations of alert rule windows.
+const (
+	AggAvg   = "avg"
+	AggMin   = "min"
+	AggMax   = "max"
+	AggSum   = "sum"
+	AggCount = "count"
+)
+
+// Sources of alert rules.
+const (
+	RuleSourceConfig = "config" // Loaded from the rules file at startup, read-only
+	RuleSourceAPI    = "api"    // Managed via the API and persisted in the repository
+)
+
+// AlertState is the state of an alert.
+type AlertState string
+
+const (
+	AlertFiring   AlertState = "firing"
+	AlertResolved AlertState = "resolved"
+)
+
+// ruleIDPattern restricts rule IDs to URL and key safe strings.
+var ruleIDPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// Duration is a time.Duration encoded in JSON as a Go duration string, e.g. "5m".
+type Duration time.Duration
+
+// MarshalJSON encodes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON decodes a duration string.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string, e.g. \"5m\"")
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Rule is a threshold alerting rule evaluated on every stored record of the matching series.
+//
+// The evaluated value is the record Max, or with a Window the Aggregation of the Max values of the series
+// records within the Window before the record (by record timestamp). The rule fires once the value
+// satisfies `value Op Threshold` for For consecutive records of a series and resolves on the first one
+// that doesn't.
+type Rule struct {
+	ID          string   `json:"id"`                                    // Unique within the tenant
+	Tenant      string   `json:"tenant,omitempty"`                      // Owner tenant
+	Name        string   `json:"name,omitempty"`                        // Human readable name
+	Series      string   `json:"series,omitempty"`                      // Series name, empty - any series
+	Labels      []string `json:"labels,omitempty"`                      // Label matchers (name=value, name!=value, name=~regexp, name!~regexp)
+	Op          string   `json:"op"`                                    // Comparison: >, >=, < or <=
+	Threshold   float64  `json:"threshold"`                             // Compared value
+	Window      Duration `json:"window,omitempty" swaggertype:"string"` // Rolling window, e.g. "5m", 0 - the record alone
+	Aggregation string   `json:"aggregation,omitempty"`                 // Window aggregation: avg (default), min, max, sum or count
+	For         int      `json:"for,omitempty"`                         // Consecutive records the condition must hold, 0 or 1 - one record
+	Source      string   `json:"source,omitempty"`                      // config or api, set by the server
+}
+
+// Validate checks the rule and fills the defaults.
+func (r *Rule) Validate() error {
+	if !ruleIDPattern.MatchString(r.ID) {
+		return fmt.Errorf("invalid rule ID %q: 1-64 letters, digits, '_', '.' or '-'", r.ID)
+	}
+
+	switch r.Op {
+	case OpGreater, OpGreaterEqual, OpLess, OpLessEqual:
+	default:
+		return fmt.Errorf("invalid op %q, expected >, >=, < or <=", r.Op)
+	}
+
+	if r.Window < 0 {
+		return fmt.Errorf("negative window")
+	}
+	switch {
+	case r.Window == 0 && r.Aggregation != "":
+		return fmt.Errorf("aggregation requires a window")
+	case r.Window > 0 && r.Aggregation == "":
+		r.Aggregation = AggAvg
+	}
+	switch r.Aggregation {
+	case "", AggAvg, AggMin, AggMax, AggSum, AggCount:
+	default:
+		return fmt.Errorf("invalid aggregation %q, expected avg, min, max, sum or count", r.Aggregation)
+	}
+
+	if r.For < 0 {
+		return fmt.Errorf("negative for")
+	}
+
+	_, err := r.Filter()
+	return err
+}
+
+// Filter returns the series and label selector of the rule.
+func (r *Rule) Filter() (*Filter, error) {
+	filter := Filter{Series: r.Series}
+	for _, s := range r.Labels {
+		m, err := ParseLabelMatcher(s)
+		if err != nil {
+			return nil, err
+		}
+		filter.Matchers = append(filter.Matchers, m)
+	}
+	return &filter, nil
+}
+
+// Alert is the state of a rule for one series (series name and labels) of a tenant.
+type Alert struct {
+	Key        string            `json:"key"`                   // Rule ID and series, unique within the tenant
+	RuleID     string            `json:"rule_id"`               // Rule that raised the alert
+	Tenant     string            `json:"tenant,omitempty"`      // Owner tenant
+	Series     string            `json:"series,omitempty"`      // Series of the records
+	Labels     map[string]string `json:"labels,omitempty"`      // Labels of the records
+	State      AlertState        `json:"state"`                 // firing or resolved
+	Value      float64           `json:"value"`                 // Evaluated value of the last transition
+	StartsAt   time.Time         `json:"starts_at"`             // Time of the record that fired the alert
+	ResolvedAt *time.Time        `json:"resolved_at,omitempty"` // Time of the record that resolved the alert
+}
+
+// AlertStore defines the interface for persisting alert rules and alert states.
+type AlertStore interface {
+	// PutRule stores an alert rule, replacing a rule with the same tenant and ID.
+	//
+	// Parameters:
+	//   - rule: Rule to store
+	//
+	// Returns:
+	//   - error: Any error that occurred during the storage operation
+	PutRule(rule *Rule) error
+
+	// DeleteRule removes an alert rule. Removing a missing rule is not an error.
+	//
+	// Parameters:
+	//   - tenant: Owner tenant of the rule
+	//   - id: Rule ID
+	//
+	// Returns:
+	//   - error: Any error that occurred during the removal
+	DeleteRule(tenant, id string) error
+
+	// ListRules retrieves the stored alert rules of all tenants.
+	//
+	// Returns:
+	//   - []Rule: Stored rules, in no particular order
+	//   - error: Any error that occurred during the retrieval
+	ListRules() ([]Rule, error)
+
+	// PutAlert stores an alert state, replacing the state with the same tenant and key.
+	//
+	// Parameters:
+	//   - alert: Alert to store
+	//
+	// Returns:
+	//   - error: Any error that occurred during the storage operation
+	PutAlert(alert *Alert) error
+
+	// ListAlerts retrieves the alert states of a tenant.
+	//
+	// Parameters:
+	//   - tenant: Owner tenant of the alerts
+	//
+	// Returns:
+	//   - []Alert: Stored alerts, in no particular order
+	//   - error: Any error that occurred during the retrieval
+	ListAlerts(tenant string) ([]Alert, error)
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"xis-data-aggregator/pkg/utils"
 
 	"github.com/google/uuid"
@@ -10,9 +13,36 @@ import (
 // This struct contains the essential information extracted from raw Pack data
 // and is used for API responses and data storage.
 type Data struct {
-	ID        uuid.UUID `json:"id"`  // Unique identifier for the data record
-	Timestamp int64     `json:"ts"`  // Unix timestamp when the data was recorded
-	Max       int       `json:"max"` // Maximum value extracted from the original data array
+	ID        uuid.UUID         `json:"id"`                       // Unique identifier for the data record
+	Timestamp int64             `json:"ts"`                       // Unix timestamp when the data was recorded
+	Max       Value             `json:"max" swaggertype:"number"` // Maximum value extracted from the original data array, typed as the series
+	Tenant    string            `json:"tenant,omitempty"`         // Owner tenant
+	Series    string            `json:"series,omitempty"`         // Source/series name copied from the Pack
+	Labels    map[string]string `json:"labels,omitempty"`         // Source labels copied from the Pack
+
+	AnomalyScore float64 `json:"anomaly_score,omitempty"` // Deviation of Max from the recent series behavior in standard deviations, 0 during warm-up
+	Anomalous    bool    `json:"anomalous,omitempty"`     // AnomalyScore reached the detection threshold
+}
+
+// SeriesKey identifies the series of the record within its tenant: `name{label="value",...}` with labels sorted by name.
+func (d *Data) SeriesKey() string {
+	names := make([]string, 0, len(d.Labels))
+	for name := range d.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(d.Series)
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=%q", name, d.Labels[name])
+	}
+	b.WriteByte('}')
+	return b.String()
 }
 
 // MapPackToData converts a Pack struct to a Data struct by extracting
@@ -26,11 +56,42 @@ type Data struct {
 //   - *Data: Pointer to the converted Data struct
 //   - error: Any error that occurred during the conversion process
 func MapPackToData(pack *Pack) (*Data, error) {
-	data := Data{ID: pack.ID, Timestamp: pack.Timestamp}
+	data := Data{
+		ID:        pack.ID,
+		Timestamp: pack.Timestamp,
+		Tenant:    pack.Tenant,
+		Series:    pack.Series,
+		Labels:    pack.Labels,
+	}
 
 	var err error
-	data.Max, err = utils.GetMaxValue(pack.Data)
+	data.Max, err = maxValue(pack.Data, pack.ValueType)
 
 	return &data, err
 
 }
+
+// maxValue returns the maximum of the samples aggregated as the given value type.
+// Integer samples of a float64 pack are converted; float samples of an int64 pack are an error.
+func maxValue(values []Value, valueType ValueType) (Value, error) {
+	if valueType == ValueTypeFloat64 {
+		floats := make([]float64, len(values))
+		for i, v := range values {
+			floats[i] = v.Float64()
+		}
+
+		maxVal, err := utils.GetMaxValue(floats)
+		return FloatValue(maxVal), err
+	}
+
+	ints := make([]int64, len(values))
+	for i, v := range values {
+		if v.IsFloat() {
+			return Value{}, fmt.Errorf("floating-point value %v in %s pack", v, ValueTypeInt64)
+		}
+		ints[i] = v.Int
+	}
+
+	maxVal, err := utils.GetMaxValue(ints)
+	return IntValue(maxVal), err
+}
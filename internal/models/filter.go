@@ -0,0 +1,139 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MatchType is the comparison applied by a LabelMatcher.
+type MatchType int
+
+const (
+	MatchEqual     MatchType = iota // label == value
+	MatchNotEqual                   // label != value
+	MatchRegexp                     // label matches the regular expression
+	MatchNotRegexp                  // label doesn't match the regular expression
+)
+
+// matchOperators maps the textual operators to match types, longest first for parsing.
+var matchOperators = []struct {
+	op string
+	t  MatchType
+}{
+	{"!=", MatchNotEqual},
+	{"=~", MatchRegexp},
+	{"!~", MatchNotRegexp},
+	{"=", MatchEqual},
+}
+
+// labelNamePattern restricts label names to identifier-like strings.
+var labelNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// LabelMatcher selects records by a single label. A missing label matches as an empty value.
+type LabelMatcher struct {
+	Name  string
+	Type  MatchType
+	Value string
+
+	re *regexp.Regexp // Compiled, anchored Value for the regexp match types
+}
+
+// NewLabelMatcher creates a LabelMatcher, compiling the value for the regexp match types.
+func NewLabelMatcher(name string, t MatchType, value string) (*LabelMatcher, error) {
+	if !labelNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid label name %q", name)
+	}
+
+	m := LabelMatcher{Name: name, Type: t, Value: value}
+
+	switch t {
+	case MatchEqual, MatchNotEqual:
+	case MatchRegexp, MatchNotRegexp:
+		re, err := regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid label regexp %q: %w", value, err)
+		}
+		m.re = re
+	default:
+		return nil, fmt.Errorf("invalid match type %d", t)
+	}
+
+	return &m, nil
+}
+
+// ParseLabelMatcher parses a matcher in the form name=value, name!=value, name=~regexp or name!~regexp.
+func ParseLabelMatcher(s string) (*LabelMatcher, error) {
+	for _, o := range matchOperators {
+		if name, value, ok := strings.Cut(s, o.op); ok {
+			return NewLabelMatcher(strings.TrimSpace(name), o.t, value)
+		}
+	}
+	return nil, fmt.Errorf("invalid label matcher %q", s)
+}
+
+// Matches reports whether the labels satisfy the matcher.
+func (m *LabelMatcher) Matches(labels map[string]string) bool {
+	v := labels[m.Name]
+
+	switch m.Type {
+	case MatchEqual:
+		return v == m.Value
+	case MatchNotEqual:
+		return v != m.Value
+	case MatchRegexp:
+		return m.re.MatchString(v)
+	case MatchNotRegexp:
+		return !m.re.MatchString(v)
+	}
+	return false
+}
+
+// Filter selects records by series and labels. The zero Filter matches everything.
+type Filter struct {
+	Series    string          // Series name, empty - any series
+	Matchers  []*LabelMatcher // All matchers must match
+	Anomalous bool            // Only records flagged by the anomaly detector
+}
+
+// Matches reports whether the record satisfies the filter.
+func (f *Filter) Matches(data *Data) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.Anomalous && !data.Anomalous {
+		return false
+	}
+
+	return f.MatchesSeries(data.Series, data.Labels)
+}
+
+// MatchesSeries reports whether a series name and labels satisfy the series and label matchers of the filter.
+func (f *Filter) MatchesSeries(series string, labels map[string]string) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.Series != "" && series != f.Series {
+		return false
+	}
+
+	for _, m := range f.Matchers {
+		if !m.Matches(labels) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ValidateLabels returns an error if any label name is not a valid identifier.
+func ValidateLabels(labels map[string]string) error {
+	for name := range labels {
+		if !labelNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid label name %q", name)
+		}
+	}
+	return nil
+}
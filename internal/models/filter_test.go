@@ -0,0 +1,51 @@
+// Package models contains tests for series and label filtering.
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFilterMatches tests label matcher parsing and filter evaluation.
+func TestFilterMatches(t *testing.T) {
+	data := &Data{Series: "temperature", Labels: map[string]string{"host": "edge-1", "env": "prod"}}
+
+	// Define test cases for Filter.Matches
+	tests := []struct {
+		name     string   // Name of the test case
+		series   string   // Series filter
+		matchers []string // Label matchers
+		want     bool     // Expected result
+	}{
+		{name: "Empty filter", want: true},
+		{name: "Series match", series: "temperature", want: true},
+		{name: "Series mismatch", series: "humidity", want: false},
+		{name: "Equal", matchers: []string{"host=edge-1"}, want: true},
+		{name: "Not equal", matchers: []string{"env!=prod"}, want: false},
+		{name: "Regexp", matchers: []string{"host=~edge-.*"}, want: true},
+		{name: "Regexp is anchored", matchers: []string{"host=~edge"}, want: false},
+		{name: "Not regexp", matchers: []string{"host!~core-.*"}, want: true},
+		{name: "Missing label equals empty", matchers: []string{"rack="}, want: true},
+		{name: "All matchers must match", series: "temperature", matchers: []string{"host=edge-1", "env=dev"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := Filter{Series: tt.series}
+			for _, s := range tt.matchers {
+				m, err := ParseLabelMatcher(s)
+				require.NoError(t, err)
+				filter.Matchers = append(filter.Matchers, m)
+			}
+			assert.Equal(t, tt.want, filter.Matches(data))
+		})
+	}
+
+	// Invalid matchers are rejected
+	for _, s := range []string{"host", "1host=a", "host=~("} {
+		_, err := ParseLabelMatcher(s)
+		assert.Error(t, err, "matcher %q", s)
+	}
+}
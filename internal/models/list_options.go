@@ -0,0 +1,16 @@
+package models
+
+// SortOrder is the order of listed records by timestamp.
+type SortOrder int
+
+// Sort orders, numbered as the protobuf Order enum.
+const (
+	OrderAsc SortOrder = iota
+	OrderDesc
+)
+
+// ListOptions limits and orders the result of a range query.
+type ListOptions struct {
+	Limit int       // Maximum number of records, 0 returns all
+	Order SortOrder // Order by timestamp
+}
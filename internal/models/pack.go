@@ -1,6 +1,10 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"math"
+
 	"github.com/google/uuid"
 )
 
@@ -8,7 +12,32 @@ import (
 // This struct contains raw data received from external sources
 // and serves as the primary input format for the data aggregation system.
 type Pack struct {
-	ID        uuid.UUID // UUID RFC9562 (psql 16 bytes) - Unique identifier for the data pack
-	Timestamp int64     // Unix timestamp indicating when the data was collected
-	Data      []int     // Array of integer values representing the raw data points
+	ID        uuid.UUID         `json:"id"`                              // UUID RFC9562 (psql 16 bytes) - Unique identifier for the data pack
+	Timestamp int64             `json:"ts"`                              // Unix timestamp indicating when the data was collected
+	Data      []Value           `json:"data" swaggertype:"array,number"` // Array of sample values representing the raw data points
+	ValueType ValueType         `json:"type,omitempty"`                  // Declared sample value type, defaults to the series type or int64
+	Series    string            `json:"series,omitempty"`                // Source/series name, e.g. the sensor or producer
+	Labels    map[string]string `json:"labels,omitempty"`                // Arbitrary key-value metadata of the source
+	Tenant    string            `json:"-"`                               // Owner tenant, derived from the producer credentials
+}
+
+// Validate checks that the pack has samples, that they fit the declared value type and that label names are valid.
+func (p *Pack) Validate() error {
+	if len(p.Data) == 0 {
+		return errors.New("pack data is empty")
+	}
+
+	for _, v := range p.Data {
+		if !v.IsFloat() {
+			continue
+		}
+		if math.IsNaN(v.Float) || math.IsInf(v.Float, 0) {
+			return fmt.Errorf("unsupported value %v", v.Float)
+		}
+		if p.ValueType != ValueTypeFloat64 {
+			return fmt.Errorf("floating-point value %v in %s pack", v, ValueTypeInt64)
+		}
+	}
+
+	return ValidateLabels(p.Labels)
 }
@@ -1,6 +1,10 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // Repository defines the interface for data persistence operations.
 // This interface abstracts the storage layer and provides methods
@@ -14,6 +18,37 @@ type Repository interface {
 	// This method should be called when the repository is no longer needed.
 	Close() error
 
+	// Ping checks that the storage responds.
+	//
+	// Returns:
+	//   - error: Any error that occurred while reaching the storage
+	Ping() error
+
+	// ForTenant returns a view of the repository scoped to the given tenant.
+	// All operations of the view read and write only the tenant's records;
+	// records stored through the view are assigned to the tenant.
+	//
+	// Parameters:
+	//   - tenant: Tenant identifier, DefaultTenant for the shared namespace
+	//
+	// Returns:
+	//   - Repository: Tenant scoped repository sharing the underlying connection
+	ForTenant(tenant string) Repository
+
+	// Tenants lists the tenants with stored Data records, in no particular order.
+	//
+	// Returns:
+	//   - []string: Tenant identifiers, DefaultTenant included if it has records
+	//   - error: Any error that occurred during the listing
+	Tenants() ([]string, error)
+
+	// Retention returns how long the records of the view tenant are kept. Records stored with older
+	// timestamps are dropped by the next write.
+	//
+	// Returns:
+	//   - time.Duration: Retention of the tenant, 0 if records are kept forever
+	Retention() time.Duration
+
 	// Put stores a Data record in the repository.
 	// If a record with the same ID already exists, it will be overwritten.
 	//
@@ -24,6 +59,16 @@ type Repository interface {
 	//   - error: Any error that occurred during the storage operation
 	Put(data *Data) error
 
+	// PutBatch stores several Data records in one round trip, atomically where the storage allows.
+	// Records with the IDs of existing records overwrite them, like Put.
+	//
+	// Parameters:
+	//   - batch: Records to be stored
+	//
+	// Returns:
+	//   - error: Any error that occurred during the storage operation
+	PutBatch(batch []*Data) error
+
 	// GetByID retrieves a Data record by its unique identifier.
 	//
 	// Parameters:
@@ -34,6 +79,17 @@ type Repository interface {
 	//   - error: Any error that occurred during the retrieval operation
 	GetByID(id uuid.UUID) (*Data, error)
 
+	// GetByIDs retrieves several Data records by their unique identifiers in one round trip.
+	//
+	// Parameters:
+	//   - ids: UUIDs of the records to retrieve
+	//
+	// Returns:
+	//   - []Data: Records found, in the order of ids
+	//   - []uuid.UUID: IDs without a record, in the order of ids
+	//   - error: Any error that occurred during the retrieval operation
+	GetByIDs(ids []uuid.UUID) ([]Data, []uuid.UUID, error)
+
 	// ListByPeriod retrieves all Data records within a specified time period.
 	// The search is inclusive of both the 'from' and 'to' timestamps.
 	//
@@ -45,4 +101,55 @@ type Repository interface {
 	//   - []Data: Slice of Data records found within the specified period
 	//   - error: Any error that occurred during the search operation
 	ListByPeriod(from, to int64) ([]Data, error)
+
+	// Query retrieves the Data records within a specified time period that match a filter, ordered by timestamp
+	// and limited by the options. Unlike ListByPeriod, it stops reading the period once the limit is reached.
+	// The period is inclusive of both the 'from' and 'to' timestamps.
+	//
+	// Parameters:
+	//   - from: Start timestamp of the period (inclusive)
+	//   - to: End timestamp of the period (inclusive)
+	//   - filter: Series and label selector, nil matches all records
+	//   - opts: Limit and order of the records
+	//
+	// Returns:
+	//   - []Data: The matching records, empty if there are none
+	//   - error: Any error that occurred during the search operation
+	Query(from, to int64, filter *Filter, opts ListOptions) ([]Data, error)
+
+	// ScanByPeriod passes the Data records within a specified time period to fn in batches of ascending
+	// timestamp, so that large periods are never loaded at once. Records added or removed during the scan
+	// may be skipped or passed twice. The period is inclusive of both the 'from' and 'to' timestamps.
+	//
+	// Parameters:
+	//   - from: Start timestamp (inclusive) for the scan period
+	//   - to: End timestamp (inclusive) for the scan period
+	//   - batchSize: Maximum number of records per call of fn
+	//   - fn: Called with each batch; an error stops the scan and is returned
+	//
+	// Returns:
+	//   - error: The error of fn or any error that occurred during the scan; no records is not an error
+	ScanByPeriod(from, to int64, batchSize int, fn func([]Data) error) error
+
+	// Delete removes a Data record from both the ID index and the time range index.
+	//
+	// Parameters:
+	//   - id: UUID of the record to remove
+	//
+	// Returns:
+	//   - error: ErrNotFound of the implementation if there is no such record,
+	//     or any error that occurred during the removal
+	Delete(id uuid.UUID) error
+
+	// DeleteByPeriod removes all Data records within a specified time period from both indexes.
+	// The range is inclusive of both the 'from' and 'to' timestamps.
+	//
+	// Parameters:
+	//   - from: Start timestamp (inclusive) of the period
+	//   - to: End timestamp (inclusive) of the period
+	//
+	// Returns:
+	//   - int64: Number of removed records
+	//   - error: Any error that occurred during the removal
+	DeleteByPeriod(from, to int64) (int64, error)
 }
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefaultTenant is the tenant of unauthenticated callers and of the built-in input simulation.
+// Its records are stored under the legacy (non namespaced) keys.
+const DefaultTenant = ""
+
+// tenantPattern restricts tenant identifiers to characters that are safe in storage keys.
+var tenantPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ValidateTenant returns an error if the tenant identifier cannot be used for namespacing.
+func ValidateTenant(tenant string) error {
+	if tenant != DefaultTenant && !tenantPattern.MatchString(tenant) {
+		return fmt.Errorf("invalid tenant %q", tenant)
+	}
+	return nil
+}
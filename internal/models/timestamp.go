@@ -0,0 +1,84 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrTimestampRange is returned for timestamps that can't be represented in stored units.
+var ErrTimestampRange = errors.New("timestamp out of range")
+
+// TimestampUnit is the resolution of stored timestamps: Data and Pack timestamps are Unix microseconds.
+const TimestampUnit = time.Microsecond
+
+// Timestamp converts a time to the stored timestamp representation.
+func Timestamp(t time.Time) int64 {
+	return t.UnixMicro()
+}
+
+// TimeOf converts a stored timestamp to time.Time.
+func TimeOf(ts int64) time.Time {
+	return time.UnixMicro(ts)
+}
+
+// TimestampPrecision is the unit of integer timestamps exchanged with API clients and producers.
+type TimestampPrecision string
+
+const (
+	PrecisionSeconds      TimestampPrecision = "s"
+	PrecisionMilliseconds TimestampPrecision = "ms"
+	PrecisionMicroseconds TimestampPrecision = "us"
+	PrecisionNanoseconds  TimestampPrecision = "ns"
+)
+
+// precisionUnits maps precisions (and the "µs" alias) to their durations.
+var precisionUnits = map[TimestampPrecision]time.Duration{
+	PrecisionSeconds:      time.Second,
+	PrecisionMilliseconds: time.Millisecond,
+	PrecisionMicroseconds: time.Microsecond,
+	"µs":                  time.Microsecond,
+	PrecisionNanoseconds:  time.Nanosecond,
+}
+
+// ParseTimestampPrecision parses a precision name: s, ms, us (µs) or ns.
+func ParseTimestampPrecision(s string) (TimestampPrecision, error) {
+	p := TimestampPrecision(s)
+	if _, ok := precisionUnits[p]; !ok {
+		return "", fmt.Errorf("invalid timestamp precision %q", s)
+	}
+	return p, nil
+}
+
+// Unit returns the duration of one timestamp tick; unknown precisions fall back to TimestampUnit.
+func (p TimestampPrecision) Unit() time.Duration {
+	if unit, ok := precisionUnits[p]; ok {
+		return unit
+	}
+	return TimestampUnit
+}
+
+// ToStored converts a timestamp in this precision to stored units, truncating finer precisions.
+// Returns ErrTimestampRange if the timestamp overflows stored units.
+func (p TimestampPrecision) ToStored(ts int64) (int64, error) {
+	unit := p.Unit()
+	if unit < TimestampUnit {
+		return ts / int64(TimestampUnit/unit), nil
+	}
+
+	factor := int64(unit / TimestampUnit)
+	if ts > math.MaxInt64/factor || ts < math.MinInt64/factor {
+		return 0, fmt.Errorf("%w: %d%s", ErrTimestampRange, ts, p)
+	}
+	return ts * factor, nil
+}
+
+// FromStored converts a stored timestamp to this precision, truncating toward zero.
+func (p TimestampPrecision) FromStored(ts int64) int64 {
+	unit := p.Unit()
+	if unit >= TimestampUnit {
+		return ts / int64(unit/TimestampUnit)
+	}
+	return ts * int64(TimestampUnit/unit)
+}
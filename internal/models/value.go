@@ -0,0 +1,109 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ValueType is the sample value type of a series.
+type ValueType string
+
+const (
+	ValueTypeInt64   ValueType = "int64"   // Signed 64-bit integers (default)
+	ValueTypeFloat64 ValueType = "float64" // IEEE 754 double precision floating-point numbers
+)
+
+// ParseValueType parses a value type name; an empty name means "not declared".
+func ParseValueType(s string) (ValueType, error) {
+	switch t := ValueType(s); t {
+	case "", ValueTypeInt64, ValueTypeFloat64:
+		return t, nil
+	}
+	return "", fmt.Errorf("invalid value type %q", s)
+}
+
+// Value is a single sample value, either an int64 or a float64 depending on Type.
+// In JSON it is a plain number: integers are kept exact, numbers with a fraction or exponent are floats.
+type Value struct {
+	Type  ValueType // ValueTypeInt64 (or empty) - Int is set, ValueTypeFloat64 - Float is set
+	Int   int64
+	Float float64
+}
+
+// IntValue returns an int64 Value.
+func IntValue(v int64) Value {
+	return Value{Type: ValueTypeInt64, Int: v}
+}
+
+// FloatValue returns a float64 Value.
+func FloatValue(v float64) Value {
+	return Value{Type: ValueTypeFloat64, Float: v}
+}
+
+// IsFloat reports whether the value is a float64.
+func (v Value) IsFloat() bool {
+	return v.Type == ValueTypeFloat64
+}
+
+// Float64 returns the value as float64 (int64 values above 2^53 lose precision).
+func (v Value) Float64() float64 {
+	if v.IsFloat() {
+		return v.Float
+	}
+	return float64(v.Int)
+}
+
+// String formats the value as in JSON.
+func (v Value) String() string {
+	if v.IsFloat() {
+		return strconv.FormatFloat(v.Float, 'g', -1, 64)
+	}
+	return strconv.FormatInt(v.Int, 10)
+}
+
+// MarshalJSON encodes the value as a JSON number.
+func (v Value) MarshalJSON() ([]byte, error) {
+	if v.IsFloat() && (math.IsNaN(v.Float) || math.IsInf(v.Float, 0)) {
+		return nil, fmt.Errorf("unsupported value %v", v.Float)
+	}
+	return []byte(v.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number. Integers that don't fit int64 are decoded as floats.
+func (v *Value) UnmarshalJSON(b []byte) error {
+	s := string(b)
+
+	if !strings.ContainsAny(s, ".eE") {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			*v = IntValue(i)
+			return nil
+		}
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid value %s", s)
+	}
+	*v = FloatValue(f)
+	return nil
+}
+
+// IntValues converts integers to a slice of int64 Values.
+func IntValues[T ~int | ~int32 | ~int64](ints []T) []Value {
+	values := make([]Value, len(ints))
+	for i, v := range ints {
+		values[i] = IntValue(int64(v))
+	}
+	return values
+}
+
+// FloatValues converts floats to a slice of float64 Values.
+func FloatValues(floats []float64) []Value {
+	values := make([]Value, len(floats))
+	for i, v := range floats {
+		values[i] = FloatValue(v)
+	}
+	return values
+}
@@ -0,0 +1,36 @@
+// Package models contains tests for typed sample values.
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValueJSON tests that integers stay exact and fractional numbers decode as floats.
+func TestValueJSON(t *testing.T) {
+	var values []Value
+	require.NoError(t, json.Unmarshal([]byte(`[42, 9007199254740993, -1.5, 1e3]`), &values))
+
+	assert.Equal(t, []Value{IntValue(42), IntValue(9007199254740993), FloatValue(-1.5), FloatValue(1000)}, values)
+
+	out, err := json.Marshal(values)
+	require.NoError(t, err)
+	assert.Equal(t, `[42,9007199254740993,-1.5,1000]`, string(out))
+}
+
+// TestMapPackToDataValueTypes tests aggregation of int64 and float64 packs.
+func TestMapPackToDataValueTypes(t *testing.T) {
+	data, err := MapPackToData(&Pack{Data: IntValues([]int64{1 << 40, 7})})
+	require.NoError(t, err)
+	assert.Equal(t, IntValue(1<<40), data.Max)
+
+	data, err = MapPackToData(&Pack{ValueType: ValueTypeFloat64, Data: []Value{IntValue(2), FloatValue(2.5)}})
+	require.NoError(t, err)
+	assert.Equal(t, FloatValue(2.5), data.Max)
+
+	_, err = MapPackToData(&Pack{ValueType: ValueTypeInt64, Data: []Value{FloatValue(2.5)}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookEndpoint is an HTTP endpoint notified of the newly stored records of its tenant.
+type WebhookEndpoint struct {
+	ID     string   `json:"id"`                // Unique within the tenant
+	Tenant string   `json:"tenant,omitempty"`  // Owner tenant
+	URL    string   `json:"url"`               // Target URL, https unless Test
+	Secret string   `json:"secret,omitempty"`  // HMAC-SHA256 signing key, never returned by the API
+	Series string   `json:"series,omitempty"`  // Series name, empty - any series
+	Labels []string `json:"labels,omitempty"`  // Label matchers (name=value, name!=value, name=~regexp, name!~regexp)
+	MinMax *float64 `json:"min_max,omitempty"` // Only records with max >= min_max
+	Test   bool     `json:"test,omitempty"`    // Test mode: allows plain HTTP and loopback targets such as a local stand-in
+}
+
+// Validate checks the endpoint. Outside test mode the URL must be https and not target the loopback interface.
+func (e *WebhookEndpoint) Validate() error {
+	if !ruleIDPattern.MatchString(e.ID) {
+		return fmt.Errorf("invalid webhook ID %q: 1-64 letters, digits, '_', '.' or '-'", e.ID)
+	}
+	if e.Secret == "" {
+		return fmt.Errorf("webhook %q has no secret", e.ID)
+	}
+
+	u, err := url.Parse(e.URL)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return fmt.Errorf("invalid webhook URL %q", e.URL)
+	}
+	if !e.Test {
+		if u.Scheme != "https" {
+			return fmt.Errorf("webhook URL %q must use https outside test mode", e.URL)
+		}
+		ip := net.ParseIP(u.Hostname())
+		if u.Hostname() == "localhost" || (ip != nil && ip.IsLoopback()) {
+			return fmt.Errorf("webhook URL %q targets the loopback interface outside test mode", e.URL)
+		}
+	}
+
+	_, err = e.Filter()
+	return err
+}
+
+// Filter returns the series and label selector of the endpoint.
+func (e *WebhookEndpoint) Filter() (*Filter, error) {
+	rule := Rule{Series: e.Series, Labels: e.Labels}
+	return rule.Filter()
+}
+
+// WebhookDelivery is a delivery attempt of a record to a webhook endpoint.
+type WebhookDelivery struct {
+	ID         string    `json:"id"`                    // Delivery ID, the same for all attempts of a record
+	EndpointID string    `json:"endpoint_id"`           // Target endpoint
+	Tenant     string    `json:"tenant,omitempty"`      // Owner tenant
+	RecordID   uuid.UUID `json:"record_id"`             // Delivered record
+	Attempt    int       `json:"attempt"`               // Attempt number, starting at 1
+	Time       time.Time `json:"time"`                  // Start of the attempt
+	DurationMs int64     `json:"duration_ms"`           // Duration of the attempt
+	StatusCode int       `json:"status_code,omitempty"` // HTTP status code, 0 if no response
+	Error      string    `json:"error,omitempty"`       // Failure reason
+	Success    bool      `json:"success"`               // 2xx response
+	Final      bool      `json:"final"`                 // No further attempt follows
+	Test       bool      `json:"test,omitempty"`        // Test delivery
+}
+
+// DeliveryLog defines the interface for recording webhook delivery attempts.
+type DeliveryLog interface {
+	// AppendDelivery records a delivery attempt, keeping only the most recent attempts of the tenant.
+	//
+	// Parameters:
+	//   - delivery: Attempt to record
+	//   - keep: Number of most recent attempts kept per tenant
+	//
+	// Returns:
+	//   - error: Any error that occurred during the storage operation
+	AppendDelivery(delivery *WebhookDelivery, keep int) error
+
+	// ListDeliveries retrieves the most recent delivery attempts of a tenant, newest first.
+	//
+	// Parameters:
+	//   - tenant: Owner tenant of the deliveries
+	//   - limit: Maximum number of attempts to return
+	//
+	// Returns:
+	//   - []WebhookDelivery: Recorded attempts, newest first
+	//   - error: Any error that occurred during the retrieval
+	ListDeliveries(tenant string, limit int) ([]WebhookDelivery, error)
+}
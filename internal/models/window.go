@@ -0,0 +1,54 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// WindowKind is the kind of a stream aggregation window.
+type WindowKind string
+
+const (
+	WindowSliding WindowKind = "sliding" // Fixed size windows starting every slide
+	WindowSession WindowKind = "session" // Records of a series separated by less than the session gap
+)
+
+// ParseWindowKind parses a window kind; the empty string is returned as is and selects any kind in queries.
+func ParseWindowKind(s string) (WindowKind, error) {
+	switch k := WindowKind(s); k {
+	case "", WindowSliding, WindowSession:
+		return k, nil
+	}
+	return "", fmt.Errorf("invalid window kind %q", s)
+}
+
+// WindowResult is the aggregate of the Max values of the records of one series within an event time window.
+// It is emitted once the watermark of the series passes the window end and emitted again with the next
+// Revision whenever a late record within the allowed lateness changes it.
+type WindowResult struct {
+	ID       uuid.UUID         `json:"id"`                       // Derived from tenant, series, kind and start, stable across revisions
+	Kind     WindowKind        `json:"kind"`                     // sliding or session
+	Start    int64             `json:"ts"`                       // Window start, inclusive, stored Unix microseconds
+	End      int64             `json:"end"`                      // Window end, exclusive
+	Tenant   string            `json:"tenant,omitempty"`         // Owner tenant
+	Series   string            `json:"series,omitempty"`         // Series name
+	Labels   map[string]string `json:"labels,omitempty"`         // Series labels
+	Count    int64             `json:"count"`                    // Records within the window
+	Min      Value             `json:"min" swaggertype:"number"` // Minimum record Max, typed as the series
+	Max      Value             `json:"max" swaggertype:"number"` // Maximum record Max, typed as the series
+	Sum      float64           `json:"sum"`                      // Sum of the record Max values
+	Avg      float64           `json:"avg"`                      // Average of the record Max values
+	Revision int               `json:"revision"`                 // 0 for the first emission, incremented by every late update
+}
+
+// WindowStore persists emitted window results.
+type WindowStore interface {
+	// PutWindow stores a window result, replacing the result with the same tenant and ID.
+	PutWindow(w *WindowResult) error
+	// DeleteWindow removes a window result; removing a missing result is not an error.
+	DeleteWindow(tenant string, id uuid.UUID) error
+	// ListWindows retrieves the window results of a tenant starting within [from, to] that match
+	// (nil - all results), ordered by start and limited by the options.
+	ListWindows(tenant string, from, to int64, opts ListOptions, match func(*WindowResult) bool) ([]WindowResult, error)
+}
@@ -0,0 +1,94 @@
+// Package ratelimit provides per-client token bucket rate limiting shared by the REST and gRPC APIs.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// idleBucketTTL is how long an unused bucket is kept before it is evicted.
+const idleBucketTTL = 10 * time.Minute
+
+// bucket is a single client token bucket.
+type bucket struct {
+	tokens   float64   // Currently available tokens
+	lastSeen time.Time // Last refill time
+}
+
+// Limiter is a token bucket rate limiter keyed by client (API key, subject or IP address).
+// Each client gets its own bucket refilled at Rate tokens per second up to Burst tokens.
+type Limiter struct {
+	rate  float64 // Tokens added per second
+	burst float64 // Bucket capacity
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time // Clock, replaceable in tests
+}
+
+// NewLimiter creates a Limiter allowing ratePerSec requests per second with the given burst.
+// Returns nil (no limit) if ratePerSec is not positive.
+func NewLimiter(ratePerSec float64, burst int) *Limiter {
+	if ratePerSec <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = int(math.Ceil(ratePerSec))
+	}
+
+	return &Limiter{
+		rate:    ratePerSec,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the client bucket.
+// Returns false and the time until the next token is available if the bucket is empty.
+// A nil Limiter allows everything.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, lastSeen: now}
+		l.buckets[key] = b
+	}
+
+	// Refill according to the elapsed time
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.lastSeen).Seconds()*l.rate)
+	b.lastSeen = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	retryAfter := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, retryAfter
+}
+
+// sweep evicts idle buckets at most once per idleBucketTTL. Must be called with mu held.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleBucketTTL {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > idleBucketTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+// Package ratelimit contains tests for the token bucket limiter.
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLimiterAllow tests burst consumption, refill, retry hints and per-key isolation.
+func TestLimiterAllow(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l := NewLimiter(2, 3) // 2 tokens per second, burst of 3
+	l.now = func() time.Time { return now }
+
+	// The burst is available immediately
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("client-a")
+		assert.True(t, ok, "request %d within burst", i)
+	}
+
+	// The bucket is empty: the next token arrives in 1/rate seconds
+	ok, retryAfter := l.Allow("client-a")
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	// Other clients have their own bucket
+	ok, _ = l.Allow("client-b")
+	assert.True(t, ok)
+
+	// Refill after waiting
+	now = now.Add(500 * time.Millisecond)
+	ok, _ = l.Allow("client-a")
+	assert.True(t, ok)
+
+	// A nil limiter allows everything
+	var disabled *Limiter
+	ok, _ = disabled.Allow("client-a")
+	assert.True(t, ok)
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"xis-data-aggregator/internal/models"
+)
+
+const (
+	rulesKey  = "rules"  // Hash of the alert rules of all tenants, by tenant namespaced rule ID
+	alertsKey = "alerts" // Hash of the alert states of a tenant, by alert key
+)
+
+// PutRule stores an alert rule as JSON, replacing a rule with the same tenant and ID.
+func (o *RedisRepository) PutRule(rule *models.Rule) error {
+	b, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+	return o.Client.HSet(ctx, rulesKey, tenantKey(rule.Tenant, rule.ID), b).Err()
+}
+
+// DeleteRule removes an alert rule.
+func (o *RedisRepository) DeleteRule(tenant, id string) error {
+	return o.Client.HDel(ctx, rulesKey, tenantKey(tenant, id)).Err()
+}
+
+// ListRules retrieves the stored alert rules of all tenants.
+func (o *RedisRepository) ListRules() ([]models.Rule, error) {
+	values, err := o.Client.HVals(ctx, rulesKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]models.Rule, 0, len(values))
+	for _, v := range values {
+		var rule models.Rule
+		if err := json.Unmarshal([]byte(v), &rule); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// PutAlert stores an alert state as JSON, replacing the state with the same tenant and key.
+func (o *RedisRepository) PutAlert(alert *models.Alert) error {
+	b, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	return o.Client.HSet(ctx, tenantKey(alert.Tenant, alertsKey), alert.Key, b).Err()
+}
+
+// ListAlerts retrieves the alert states of a tenant.
+func (o *RedisRepository) ListAlerts(tenant string) ([]models.Alert, error) {
+	values, err := o.Client.HVals(ctx, tenantKey(tenant, alertsKey)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := make([]models.Alert, 0, len(values))
+	for _, v := range values {
+		var alert models.Alert
+		if err := json.Unmarshal([]byte(v), &alert); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 	"xis-data-aggregator/internal/api"
 	"xis-data-aggregator/pb"
@@ -19,8 +20,11 @@ import (
 )
 
 const (
-	zsetKey = "events"
-	ttlSec  = 500
+	zsetKey         = "events"
+	ttlSec          = 500
+	tenantPrefix    = "t:"
+	deleteBatchSize = 1000 // Records removed per transaction by DeleteByPeriod
+	queryPageSize   = 1000 // Largest page of the time range index read by Query
 )
 
 var (
@@ -32,15 +36,123 @@ var ctx = context.Background()
 
 type RedisRepository struct {
 	Client *redis.Client
+	Addr   string // Redis server address, empty - an embedded in-memory server
+
+	tenant    string                   // Tenant namespace of this view, models.DefaultTenant for the root
+	retention map[string]time.Duration // Per tenant retention, "*" - default, shared by all views
+}
+
+// ParseRetention parses per tenant retention settings in the form "*=720h;tenant1=72h".
+// The "*" entry applies to tenants without an own entry; zero keeps records forever.
+func ParseRetention(spec string) (map[string]time.Duration, error) {
+	retention := make(map[string]time.Duration)
+
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		tenant, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid retention entry %q", entry)
+		}
+
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid retention %q for tenant %q", value, tenant)
+		}
+		retention[strings.TrimSpace(tenant)] = d
+	}
+
+	return retention, nil
+}
+
+// SetRetention sets per tenant retention (see ParseRetention). Must be called before the repository is used.
+func (o *RedisRepository) SetRetention(retention map[string]time.Duration) {
+	o.retention = retention
+}
+
+// ForTenant returns a view of the repository with keys namespaced by tenant.
+// The default tenant uses the legacy keys.
+func (o *RedisRepository) ForTenant(tenant string) models.Repository {
+	return &RedisRepository{Client: o.Client, Addr: o.Addr, tenant: tenant, retention: o.retention}
+}
+
+// Tenants scans the keyspace for the time range indexes of the tenants.
+func (o *RedisRepository) Tenants() ([]string, error) {
+	var tenants []string
+
+	n, err := o.Client.Exists(ctx, zsetKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		tenants = append(tenants, models.DefaultTenant)
+	}
+
+	// SCAN may return a key more than once
+	seen := make(map[string]bool)
+	iter := o.Client.Scan(ctx, 0, tenantKey("*", zsetKey), 0).Iterator()
+	for iter.Next(ctx) {
+		tenant := strings.TrimSuffix(strings.TrimPrefix(iter.Val(), tenantPrefix), ":"+zsetKey)
+		if !seen[tenant] && models.ValidateTenant(tenant) == nil {
+			seen[tenant] = true
+			tenants = append(tenants, tenant)
+		}
+	}
+	return tenants, iter.Err()
+}
+
+// Retention returns the retention of the view tenant, 0 - keep forever.
+func (o *RedisRepository) Retention() time.Duration {
+	return o.retentionOf(o.tenant)
+}
+
+// retentionOf returns the retention of the tenant, 0 - keep forever.
+func (o *RedisRepository) retentionOf(tenant string) time.Duration {
+	if d, ok := o.retention[tenant]; ok {
+		return d
+	}
+	return o.retention["*"]
+}
+
+// tenantKey returns the key namespaced by tenant. The default tenant uses the legacy, unprefixed key.
+func tenantKey(tenant, key string) string {
+	if tenant == models.DefaultTenant {
+		return key
+	}
+	return tenantPrefix + tenant + ":" + key
+}
+
+// eventsKey returns the time range index key of the view tenant.
+func (o *RedisRepository) eventsKey() string {
+	return tenantKey(o.tenant, zsetKey)
+}
+
+// idKey returns the ID index key of the record in the view tenant.
+func (o *RedisRepository) idKey(id string) string {
+	return tenantKey(o.tenant, id)
 }
 
 func NewRedisRepository() (*RedisRepository, error) {
-	repo := RedisRepository{}
+	return NewRedisRepositoryAt("")
+}
+
+// NewRedisRepositoryAt creates a repository of the Redis server at addr, or of an embedded in-memory server
+// if addr is empty, so that commands run in separate processes can share the stored records.
+func NewRedisRepositoryAt(addr string) (*RedisRepository, error) {
+	repo := RedisRepository{Addr: addr}
 	err := repo.Open()
 	return &repo, err
 }
 
 func (o *RedisRepository) Open() error {
+	if o.Addr != "" {
+		o.Client = redis.NewClient(&redis.Options{Addr: o.Addr})
+		return o.Client.Ping(ctx).Err()
+	}
+
 	srv, err := miniredis.Run()
 	if err != nil {
 		return err
@@ -59,35 +171,108 @@ func (o *RedisRepository) Close() error {
 	return o.Client.Close()
 }
 
+func (o *RedisRepository) Ping() error {
+	return o.Client.Ping(ctx).Err()
+}
+
 func (o *RedisRepository) Put(data *models.Data) error {
+	return o.PutBatch([]*models.Data{data})
+}
 
-	pbData, err := api.DataToProto(data)
-	switch {
-	case err != nil:
-		return err
-	case pbData == nil:
-		return fmt.Errorf("pbData is nil")
+// PutBatch stores the records in one transaction, reading their previous versions in one round trip.
+// Of several records with the same ID in the batch, the last one is stored.
+func (o *RedisRepository) PutBatch(batch []*models.Data) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	batch = lastByID(batch)
+
+	members := make([][]byte, len(batch))
+	idKeys := make([]string, len(batch))
+	for i, data := range batch {
+		if data != nil {
+			data.Tenant = o.tenant
+		}
+
+		pbData, err := api.DataToProto(data)
+		switch {
+		case err != nil:
+			return err
+		case pbData == nil:
+			return fmt.Errorf("pbData is nil")
+		}
+
+		if members[i], err = proto.Marshal(pbData); err != nil {
+			return err
+		}
+		idKeys[i] = o.idKey(pbData.Id)
 	}
 
-	bytes, err := proto.Marshal(pbData)
+	// The previous versions of the records, if their ID keys have not expired yet
+	prev, err := o.Client.MGet(ctx, idKeys...).Result()
 	if err != nil {
 		return err
 	}
 
-	// Time range `table` without TTL. Partitioning is recommended, by month for example
-	_, err = o.Client.ZAdd(ctx, zsetKey, redis.Z{Score: float64(pbData.Timestamp), Member: bytes}).Result()
+	ttl := ttlSec * time.Second
+	retention := o.Retention()
+	if retention > 0 && retention < ttl {
+		ttl = retention
+	}
+
+	// Update both indexes atomically
+	_, err = o.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, bytes := range members {
+			// Replace the previous version in the time range index
+			if p, ok := prev[i].(string); ok && p != string(bytes) {
+				pipe.ZRem(ctx, o.eventsKey(), p)
+			}
+
+			// Time range `table` without TTL. Partitioning is recommended, by month for example
+			pipe.ZAdd(ctx, o.eventsKey(), redis.Z{Score: float64(batch[i].Timestamp), Member: bytes})
+
+			// Fast key-value `table` with TTL
+			pipe.Set(ctx, idKeys[i], bytes, ttl)
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	// Fast key-value `table` with TTL
-	err = o.Client.Set(ctx, pbData.Id, bytes, ttlSec*time.Second).Err()
+	// Drop records older than the tenant retention
+	if retention > 0 {
+		cutoff := models.Timestamp(time.Now().Add(-retention))
+		err = o.Client.ZRemRangeByScore(ctx, o.eventsKey(), "-inf", "("+strconv.FormatInt(cutoff, 10)).Err()
+	}
 
 	return err
 }
 
+// lastByID returns the batch without the records followed by another one with the same ID, which would be
+// stored as separate members of the time range index, as the previous versions are read before the batch.
+func lastByID(batch []*models.Data) []*models.Data {
+	last := make(map[uuid.UUID]int, len(batch))
+	for i, data := range batch {
+		if data != nil {
+			last[data.ID] = i
+		}
+	}
+	if len(last) == len(batch) {
+		return batch
+	}
+
+	unique := make([]*models.Data, 0, len(last))
+	for i, data := range batch {
+		if data == nil || last[data.ID] == i {
+			unique = append(unique, data)
+		}
+	}
+	return unique
+}
+
 func (o *RedisRepository) GetByID(id uuid.UUID) (*models.Data, error) {
-	val, err := o.Client.Get(ctx, id.String()).Bytes()
+	val, err := o.Client.Get(ctx, o.idKey(id.String())).Bytes()
 
 	switch {
 	case errors.Is(err, redis.Nil):
@@ -106,10 +291,52 @@ func (o *RedisRepository) GetByID(id uuid.UUID) (*models.Data, error) {
 
 }
 
+// GetByIDs fetches the records with a single MGET. Records that fail to decode are reported as ErrCorrupt.
+func (o *RedisRepository) GetByIDs(ids []uuid.UUID) ([]models.Data, []uuid.UUID, error) {
+	if len(ids) == 0 {
+		return []models.Data{}, []uuid.UUID{}, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = o.idKey(id.String())
+	}
+
+	values, err := o.Client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	found := make([]models.Data, 0, len(ids))
+	missing := make([]uuid.UUID, 0)
+
+	for i, value := range values {
+		str, ok := value.(string)
+		if !ok { // nil - no such key
+			missing = append(missing, ids[i])
+			continue
+		}
+
+		var umData pb.Data
+		if err := proto.Unmarshal([]byte(str), &umData); err != nil {
+			return nil, nil, fmt.Errorf("%w: %s: %v", ErrCorrupt, ids[i], err)
+		}
+
+		data, err := api.ProtoToData(&umData)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %s: %v", ErrCorrupt, ids[i], err)
+		}
+		found = append(found, *data)
+	}
+
+	return found, missing, nil
+}
+
 func (o *RedisRepository) ListByPeriod(from, to int64) ([]models.Data, error) {
 	var res []models.Data
 
-	results, err := o.Client.ZRangeByScoreWithScores(ctx, zsetKey, &redis.ZRangeBy{
+	results, err := o.Client.ZRangeByScoreWithScores(ctx, o.eventsKey(), &redis.ZRangeBy{
+
 		Min: strconv.Itoa(int(from)),
 		Max: strconv.Itoa(int(to)),
 	}).Result()
@@ -126,7 +353,12 @@ func (o *RedisRepository) ListByPeriod(from, to int64) ([]models.Data, error) {
 	// Note: any invalid entry compromises the entire set -> 1 error -> return
 	for _, result := range results {
 		var umData pb.Data
-		err = proto.Unmarshal(result.Member.([]byte), &umData)
+		member, ok := result.Member.(string) // go-redis returns members as strings
+		if !ok {
+			return nil, ErrCorrupt
+		}
+		err = proto.Unmarshal([]byte(member), &umData)
+
 		if err != nil {
 			return nil, ErrCorrupt
 		}
@@ -140,6 +372,136 @@ func (o *RedisRepository) ListByPeriod(from, to int64) ([]models.Data, error) {
 	return res, nil
 }
 
+// errStopPaging ends a page walk from its callback without an error.
+var errStopPaging = errors.New("stop paging")
+
+// pageRange passes the members of a sorted set scored within [from, to] to fn in pages of at most size, by
+// ascending score or, when desc is set, by descending score. Pages are read by keyset: each page continues from
+// the score of the last member read, skipping the members of that score already passed, which ZRANGEBYSCORE
+// orders lexicographically (ZREVRANGEBYSCORE in reverse). Unlike LIMIT offsets, pages cost the same all along and
+// members removed before the position (by the retention trim of concurrent writes) don't shift later ones out of
+// the walk. A callback returning errStopPaging ends the walk without an error.
+func (o *RedisRepository) pageRange(key string, from, to int64, size int, desc bool, fn func([]string) error) error {
+	size = max(size, 1)
+
+	lower, upper := strconv.FormatInt(from, 10), strconv.FormatInt(to, 10)
+	var lastScore int64
+	var lastMember string // Last member of lastScore passed
+	seen := 0             // Members of lastScore passed, read again at the start of the next page
+
+	for {
+		by := &redis.ZRangeBy{Min: lower, Max: upper, Count: int64(seen + size)}
+		var results []redis.Z
+		var err error
+		if desc {
+			results, err = o.Client.ZRevRangeByScoreWithScores(ctx, key, by).Result()
+		} else {
+			results, err = o.Client.ZRangeByScoreWithScores(ctx, key, by).Result()
+		}
+		if err != nil {
+			return err
+		}
+		full := len(results) == seen+size
+
+		page := make([]string, 0, len(results))
+		for _, result := range results {
+			member, ok := result.Member.(string) // go-redis returns members as strings
+			if !ok {
+				return ErrCorrupt
+			}
+			score := int64(result.Score)
+			if seen > 0 && score == lastScore && (member == lastMember || (member < lastMember) != desc) {
+				continue // passed on a previous page
+			}
+			if len(page) == size {
+				full = true // members of the last page removed since, the rest is read again on the next page
+				break
+			}
+			page = append(page, member)
+
+			if seen == 0 || score != lastScore {
+				lastScore, seen = score, 0
+			}
+			lastMember = member
+			seen++
+		}
+
+		switch {
+		case len(page) == 0 && full:
+			seen = len(results) // members inserted before the position since the last page
+			continue
+		case len(page) == 0:
+			return nil
+		}
+		if err := fn(page); err != nil {
+			if errors.Is(err, errStopPaging) {
+				return nil
+			}
+			return err
+		}
+		if !full {
+			return nil
+		}
+		if desc {
+			upper = strconv.FormatInt(lastScore, 10)
+		} else {
+			lower = strconv.FormatInt(lastScore, 10)
+		}
+	}
+}
+
+// scan passes the records within [from, to] to fn in batches of at most batchSize, by ascending timestamp or,
+// when desc is set, by descending timestamp.
+func (o *RedisRepository) scan(from, to int64, batchSize int, desc bool, fn func([]models.Data) error) error {
+	return o.pageRange(o.eventsKey(), from, to, batchSize, desc, func(members []string) error {
+		batch := make([]models.Data, 0, len(members))
+		for _, member := range members {
+			var umData pb.Data
+			if err := proto.Unmarshal([]byte(member), &umData); err != nil {
+				return fmt.Errorf("%w: %v", ErrCorrupt, err)
+			}
+			data, err := api.ProtoToData(&umData)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrCorrupt, err)
+			}
+			batch = append(batch, *data)
+		}
+		return fn(batch)
+	})
+}
+
+// ScanByPeriod pages through the time range index by keyset, see pageRange.
+func (o *RedisRepository) ScanByPeriod(from, to int64, batchSize int, fn func([]models.Data) error) error {
+	return o.scan(from, to, batchSize, false, fn)
+}
+
+// Query reads the time range index in the order of opts, in pages of the limit, until the limit of records
+// matching the filter is reached, so that a limited query doesn't load the whole period.
+func (o *RedisRepository) Query(from, to int64, filter *models.Filter, opts models.ListOptions) ([]models.Data, error) {
+	pageSize := queryPageSize
+	if opts.Limit > 0 {
+		pageSize = min(opts.Limit, queryPageSize)
+	}
+
+	res := []models.Data{}
+	err := o.scan(from, to, pageSize, opts.Order == models.OrderDesc, func(batch []models.Data) error {
+		for i := range batch {
+			if !filter.Matches(&batch[i]) {
+				continue
+			}
+			res = append(res, batch[i])
+			if len(res) == opts.Limit {
+				return errStopPaging
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 /*Общий Принцип и Рекомендации
 Repository: Должен быть источником истины о том, что объект не найден. Он должен возвращать nil для объекта и специальную, экспортируемую ошибку (например, repository.ErrNotFound). Используйте errors.Is для проверки этой ошибки.
 
@@ -157,3 +519,102 @@ Service просто возвращает nil, service.ErrUserNotFound (испо
 
 HTTP-обработчик проверяет errors.Is(err, service.ErrUserNotFound) и возвращает 404.
 */
+
+// Delete removes the record from the ID index and its member from the time range index in one transaction.
+func (o *RedisRepository) Delete(id uuid.UUID) error {
+	members, err := o.membersByID(id)
+	switch {
+	case err != nil:
+		return err
+	case len(members) == 0:
+		return ErrNotFound
+	}
+
+	_, err = o.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, o.eventsKey(), members...)
+		pipe.Del(ctx, o.idKey(id.String()))
+		return nil
+	})
+
+	return err
+}
+
+// membersByID returns the time range index members of the record. The member is read from the ID index;
+// once the ID key has expired the time range index is scanned for it, which is O(N).
+func (o *RedisRepository) membersByID(id uuid.UUID) ([]interface{}, error) {
+	value, err := o.Client.Get(ctx, o.idKey(id.String())).Result()
+	switch {
+	case err == nil:
+		return []interface{}{value}, nil
+	case !errors.Is(err, redis.Nil):
+		return nil, err
+	}
+
+	var members []interface{}
+	var cursor uint64
+	for {
+		// ZSCAN returns members and scores interleaved; the serialized record contains the ID as text
+		keys, next, err := o.Client.ZScan(ctx, o.eventsKey(), cursor, "*"+id.String()+"*", deleteBatchSize).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		for i := 0; i < len(keys); i += 2 {
+			var umData pb.Data
+			if proto.Unmarshal([]byte(keys[i]), &umData) == nil && umData.Id == id.String() {
+				members = append(members, keys[i])
+			}
+		}
+
+		if next == 0 {
+			return members, nil
+		}
+		cursor = next
+	}
+}
+
+// DeleteByPeriod removes the records of the period in batches. Each batch removes the exact members read
+// and their ID keys in one transaction, so records added concurrently are left in both indexes.
+func (o *RedisRepository) DeleteByPeriod(from, to int64) (int64, error) {
+	var deleted int64
+
+	for {
+		members, err := o.Client.ZRangeByScore(ctx, o.eventsKey(), &redis.ZRangeBy{
+			Min:   strconv.FormatInt(from, 10),
+			Max:   strconv.FormatInt(to, 10),
+			Count: deleteBatchSize,
+		}).Result()
+		if err != nil {
+			return deleted, err
+		}
+		if len(members) == 0 {
+			return deleted, nil
+		}
+
+		zMembers := make([]interface{}, len(members))
+		idKeys := make([]string, 0, len(members))
+		for i, member := range members {
+			zMembers[i] = member
+
+			// Undecodable members are removed from the time range index only
+			var umData pb.Data
+			if proto.Unmarshal([]byte(member), &umData) == nil && umData.Id != "" {
+				idKeys = append(idKeys, o.idKey(umData.Id))
+			}
+		}
+
+		var removed *redis.IntCmd
+		_, err = o.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			removed = pipe.ZRem(ctx, o.eventsKey(), zMembers...)
+			if len(idKeys) > 0 {
+				pipe.Del(ctx, idKeys...)
+			}
+			return nil
+		})
+		if err != nil {
+			return deleted, err
+		}
+
+		deleted += removed.Val()
+	}
+}
@@ -0,0 +1,288 @@
+// Package repository contains tests for the Redis repository.
+package repository
+
+import (
+	"testing"
+	"time"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTenantIsolation tests that tenant views only see their own records.
+func TestTenantIsolation(t *testing.T) {
+	repo, err := NewRedisRepository()
+	require.NoError(t, err)
+	defer repo.Close()
+
+	ts := time.Now().UnixMicro()
+	shared := &models.Data{ID: uuid.New(), Timestamp: ts, Max: models.IntValue(1)}
+	acme := &models.Data{ID: uuid.New(), Timestamp: ts, Max: models.IntValue(2)}
+
+	require.NoError(t, repo.ForTenant(models.DefaultTenant).Put(shared))
+	require.NoError(t, repo.ForTenant("acme").Put(acme))
+
+	// Records are assigned to the view tenant
+	got, err := repo.ForTenant("acme").GetByID(acme.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", got.Tenant)
+	assert.Equal(t, models.IntValue(2), got.Max)
+
+	// Other tenants can't read them by ID or by range
+	_, err = repo.ForTenant(models.DefaultTenant).GetByID(acme.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = repo.ForTenant("other").GetByID(acme.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	list, err := repo.ListByPeriod(ts, ts)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, shared.ID, list[0].ID)
+
+	list, err = repo.ForTenant("acme").ListByPeriod(ts, ts)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, acme.ID, list[0].ID)
+}
+
+// TestGetByIDs tests that a batch lookup returns found records in request order and lists missing IDs.
+func TestGetByIDs(t *testing.T) {
+	repo, err := NewRedisRepository()
+	require.NoError(t, err)
+	defer repo.Close()
+
+	ts := time.Now().UnixMicro()
+	first := &models.Data{ID: uuid.New(), Timestamp: ts, Max: models.IntValue(1)}
+	second := &models.Data{ID: uuid.New(), Timestamp: ts, Max: models.FloatValue(2.5)}
+	other := &models.Data{ID: uuid.New(), Timestamp: ts, Max: models.IntValue(3)}
+
+	require.NoError(t, repo.Put(first))
+	require.NoError(t, repo.Put(second))
+	require.NoError(t, repo.ForTenant("acme").Put(other))
+
+	missing := uuid.New()
+	found, notFound, err := repo.GetByIDs([]uuid.UUID{second.ID, missing, first.ID, other.ID})
+	require.NoError(t, err)
+
+	require.Len(t, found, 2)
+	assert.Equal(t, second.ID, found[0].ID)
+	assert.Equal(t, models.FloatValue(2.5), found[0].Max)
+	assert.Equal(t, first.ID, found[1].ID)
+
+	// Records of other tenants are reported missing
+	assert.Equal(t, []uuid.UUID{missing, other.ID}, notFound)
+
+	found, notFound, err = repo.GetByIDs(nil)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+	assert.Empty(t, notFound)
+}
+
+// TestDelete tests that deletes and overwrites keep the ID index and the time range index consistent.
+func TestDelete(t *testing.T) {
+	repo, err := NewRedisRepository()
+	require.NoError(t, err)
+	defer repo.Close()
+
+	records := make([]*models.Data, 5)
+	for i := range records {
+		records[i] = &models.Data{ID: uuid.New(), Timestamp: int64(100 + i*10), Max: models.IntValue(int64(i))}
+		require.NoError(t, repo.Put(records[i]))
+	}
+
+	// Overwriting a record moves it in the time range index
+	records[4].Timestamp = 200
+	require.NoError(t, repo.Put(records[4]))
+	list, err := repo.ListByPeriod(0, 1000)
+	require.NoError(t, err)
+	assert.Len(t, list, 5)
+
+	// Delete by ID
+	require.NoError(t, repo.Delete(records[0].ID))
+	_, err = repo.GetByID(records[0].ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, repo.Delete(records[0].ID), ErrNotFound)
+
+	// Delete by ID after the ID key has expired
+	require.NoError(t, repo.Client.Del(ctx, records[1].ID.String()).Err())
+	require.NoError(t, repo.Delete(records[1].ID))
+
+	list, err = repo.ListByPeriod(0, 1000)
+	require.NoError(t, err)
+	assert.Len(t, list, 3)
+
+	// Delete by period removes both indexes of the records in the range only
+	deleted, err := repo.DeleteByPeriod(120, 130)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	_, err = repo.GetByID(records[2].ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	list, err = repo.ListByPeriod(0, 1000)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, records[4].ID, list[0].ID)
+
+	// Tenant views don't delete other tenants' records
+	deleted, err = repo.ForTenant("acme").DeleteByPeriod(0, 1000)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+	assert.ErrorIs(t, repo.ForTenant("acme").Delete(records[4].ID), ErrNotFound)
+}
+
+// TestScanByPeriod tests that a scan passes all records of the period once, in batches of ascending timestamp.
+func TestScanByPeriod(t *testing.T) {
+	repo, err := NewRedisRepository()
+	require.NoError(t, err)
+	defer repo.Close()
+
+	for ts := int64(1); ts <= 25; ts++ {
+		require.NoError(t, repo.Put(&models.Data{ID: uuid.New(), Timestamp: ts, Max: models.IntValue(ts)}))
+	}
+
+	var sizes []int
+	var got []int64
+	err = repo.ScanByPeriod(3, 22, 7, func(batch []models.Data) error {
+		sizes = append(sizes, len(batch))
+		for _, data := range batch {
+			got = append(got, data.Timestamp)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{7, 7, 6}, sizes)
+	require.Len(t, got, 20)
+	assert.IsIncreasing(t, got)
+	assert.Equal(t, int64(3), got[0])
+
+	// Records sharing a timestamp across pages are passed once
+	for i := 0; i < 12; i++ {
+		require.NoError(t, repo.Put(&models.Data{ID: uuid.New(), Timestamp: 50, Max: models.IntValue(int64(i))}))
+	}
+	ids := map[uuid.UUID]bool{}
+	err = repo.ScanByPeriod(22, 50, 5, func(batch []models.Data) error {
+		assert.LessOrEqual(t, len(batch), 5)
+		for _, data := range batch {
+			assert.False(t, ids[data.ID], "passed twice")
+			ids[data.ID] = true
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, ids, 16) // 22 to 25 and the 12 of 50
+
+	// Records removed behind the position, like by the retention trim, don't shift later ones out of the scan
+	got = nil
+	err = repo.ScanByPeriod(1, 25, 4, func(batch []models.Data) error {
+		for _, data := range batch {
+			got = append(got, data.Timestamp)
+		}
+		_, err := repo.DeleteByPeriod(0, batch[len(batch)-1].Timestamp)
+		return err
+	})
+	require.NoError(t, err)
+	assert.Len(t, got, 25)
+	assert.IsIncreasing(t, got)
+
+	// Errors of fn stop the scan
+	calls := 0
+	err = repo.ScanByPeriod(0, 100, 5, func([]models.Data) error {
+		calls++
+		return ErrCorrupt
+	})
+	assert.ErrorIs(t, err, ErrCorrupt)
+	assert.Equal(t, 1, calls)
+
+	// No records is not an error
+	err = repo.ScanByPeriod(100, 200, 5, func([]models.Data) error { t.Fatal("unexpected batch"); return nil })
+	assert.NoError(t, err)
+}
+
+// TestQuery tests that a query returns the matching records of the period in the requested order up to the limit.
+func TestQuery(t *testing.T) {
+	repo, err := NewRedisRepository()
+	require.NoError(t, err)
+	defer repo.Close()
+
+	for ts := int64(1); ts <= 20; ts++ {
+		series := "odd"
+		if ts%2 == 0 {
+			series = "even"
+		}
+		require.NoError(t, repo.Put(&models.Data{ID: uuid.New(), Timestamp: ts, Series: series, Max: models.IntValue(ts)}))
+	}
+	// Records sharing a timestamp across pages
+	for i := 0; i < 5; i++ {
+		require.NoError(t, repo.Put(&models.Data{ID: uuid.New(), Timestamp: 10, Series: "even", Max: models.IntValue(10)}))
+	}
+
+	timestamps := func(list []models.Data) []int64 {
+		res := make([]int64, len(list))
+		for i, data := range list {
+			res[i] = data.Timestamp
+		}
+		return res
+	}
+
+	tests := []struct {
+		name   string
+		from   int64
+		to     int64
+		filter *models.Filter
+		opts   models.ListOptions
+		want   []int64
+	}{
+		{"all ascending", 18, 20, nil, models.ListOptions{}, []int64{18, 19, 20}},
+		{"limit ascending", 1, 20, nil, models.ListOptions{Limit: 3}, []int64{1, 2, 3}},
+		{"limit descending", 1, 20, nil, models.ListOptions{Limit: 3, Order: models.OrderDesc}, []int64{20, 19, 18}},
+		{"filter descending", 1, 20, &models.Filter{Series: "odd"}, models.ListOptions{Limit: 2, Order: models.OrderDesc}, []int64{19, 17}},
+		{"shared timestamp descending", 9, 11, &models.Filter{Series: "even"}, models.ListOptions{Limit: 2, Order: models.OrderDesc}, []int64{10, 10}},
+		{"none", 30, 40, nil, models.ListOptions{Limit: 3}, []int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.Query(tt.from, tt.to, tt.filter, tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, timestamps(got))
+		})
+	}
+
+	// Pages of the limit pass every record sharing a timestamp once in both orders
+	for _, order := range []models.SortOrder{models.OrderAsc, models.OrderDesc} {
+		ids := map[uuid.UUID]bool{}
+		err = repo.scan(9, 11, 2, order == models.OrderDesc, func(batch []models.Data) error {
+			for _, data := range batch {
+				assert.False(t, ids[data.ID], "passed twice")
+				ids[data.ID] = true
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Len(t, ids, 8) // 9, 11 and the 6 of 10
+	}
+}
+
+// TestPutBatchDuplicateIDs tests that of several records with the same ID in a batch only the last one is stored.
+func TestPutBatchDuplicateIDs(t *testing.T) {
+	repo, err := NewRedisRepository()
+	require.NoError(t, err)
+	defer repo.Close()
+
+	id := uuid.New()
+	require.NoError(t, repo.PutBatch([]*models.Data{
+		{ID: id, Timestamp: 10, Max: models.IntValue(1)},
+		{ID: uuid.New(), Timestamp: 20, Max: models.IntValue(2)},
+		{ID: id, Timestamp: 30, Max: models.IntValue(3)},
+	}))
+
+	list, err := repo.ListByPeriod(0, 100)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, int64(20), list[0].Timestamp)
+	assert.Equal(t, id, list[1].ID)
+	assert.Equal(t, models.IntValue(3), list[1].Max)
+}
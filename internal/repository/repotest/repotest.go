@@ -0,0 +1,19 @@
+// Package repotest provides repositories for tests.
+package repotest
+
+import (
+	"testing"
+	"xis-data-aggregator/internal/repository"
+)
+
+// New returns a repository on an embedded Redis server, closed when the test ends.
+func New(t testing.TB) *repository.RedisRepository {
+	t.Helper()
+
+	repo, err := repository.NewRedisRepository()
+	if err != nil {
+		t.Fatalf("repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
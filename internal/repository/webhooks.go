@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// deliveriesKey is the list of the webhook delivery attempts of a tenant, newest first.
+const deliveriesKey = "webhook_deliveries"
+
+// AppendDelivery records a webhook delivery attempt as JSON, trimming the tenant log to keep attempts.
+func (o *RedisRepository) AppendDelivery(delivery *models.WebhookDelivery, keep int) error {
+	b, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+
+	key := tenantKey(delivery.Tenant, deliveriesKey)
+	_, err = o.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, key, b)
+		pipe.LTrim(ctx, key, 0, int64(keep)-1)
+		return nil
+	})
+	return err
+}
+
+// ListDeliveries retrieves the most recent webhook delivery attempts of a tenant, newest first.
+func (o *RedisRepository) ListDeliveries(tenant string, limit int) ([]models.WebhookDelivery, error) {
+	values, err := o.Client.LRange(ctx, tenantKey(tenant, deliveriesKey), 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0, len(values))
+	for _, v := range values {
+		var delivery models.WebhookDelivery
+		if err := json.Unmarshal([]byte(v), &delivery); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	windowsKey       = "windows"        // Sorted set of the window result IDs of a tenant, scored by window start
+	windowResultsKey = "window_results" // Hash of the window results of a tenant as JSON, by ID
+)
+
+// PutWindow stores a window result as JSON, replacing the result with the same tenant and ID,
+// and drops the results starting before the tenant retention.
+func (o *RedisRepository) PutWindow(w *models.WindowResult) error {
+	b, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+
+	id := w.ID.String()
+	_, err = o.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, tenantKey(w.Tenant, windowsKey), redis.Z{Score: float64(w.Start), Member: id})
+		pipe.HSet(ctx, tenantKey(w.Tenant, windowResultsKey), id, b)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if retention := o.retentionOf(w.Tenant); retention > 0 {
+		return o.expireWindows(w.Tenant, models.Timestamp(time.Now().Add(-retention)))
+	}
+	return nil
+}
+
+// expireWindows removes the window results of the tenant starting before the cutoff.
+func (o *RedisRepository) expireWindows(tenant string, cutoff int64) error {
+	ids, err := o.Client.ZRangeByScore(ctx, tenantKey(tenant, windowsKey), &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   "(" + strconv.FormatInt(cutoff, 10),
+		Count: deleteBatchSize,
+	}).Result()
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	members := make([]interface{}, len(ids))
+	for i, id := range ids {
+		members[i] = id
+	}
+
+	_, err = o.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, tenantKey(tenant, windowsKey), members...)
+		pipe.HDel(ctx, tenantKey(tenant, windowResultsKey), ids...)
+		return nil
+	})
+	return err
+}
+
+// DeleteWindow removes a window result from both keys.
+func (o *RedisRepository) DeleteWindow(tenant string, id uuid.UUID) error {
+	_, err := o.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, tenantKey(tenant, windowsKey), id.String())
+		pipe.HDel(ctx, tenantKey(tenant, windowResultsKey), id.String())
+		return nil
+	})
+	return err
+}
+
+// ListWindows retrieves the window results of a tenant starting within [from, to] that match (nil - all results),
+// ordered by start and limited by the options. The window index is read in pages of the limit by pageRange,
+// so that a limited listing doesn't load the whole range. Returns an empty slice if there are none.
+func (o *RedisRepository) ListWindows(tenant string, from, to int64, opts models.ListOptions, match func(*models.WindowResult) bool) ([]models.WindowResult, error) {
+	pageSize := queryPageSize
+	if opts.Limit > 0 {
+		pageSize = min(opts.Limit, queryPageSize)
+	}
+
+	windows := []models.WindowResult{}
+	err := o.pageRange(tenantKey(tenant, windowsKey), from, to, pageSize, opts.Order == models.OrderDesc, func(ids []string) error {
+		values, err := o.Client.HMGet(ctx, tenantKey(tenant, windowResultsKey), ids...).Result()
+		if err != nil {
+			return err
+		}
+
+		for i, v := range values {
+			s, ok := v.(string)
+			if !ok { // removed concurrently
+				continue
+			}
+
+			var w models.WindowResult
+			if err := json.Unmarshal([]byte(s), &w); err != nil {
+				return fmt.Errorf("%w: window %s: %v", ErrCorrupt, ids[i], err)
+			}
+			if match != nil && !match(&w) {
+				continue
+			}
+			windows = append(windows, w)
+			if len(windows) == opts.Limit {
+				return errStopPaging
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return windows, nil
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"io"
+	"xis-data-aggregator/internal/backup"
+)
+
+// Backup writes an archive of the records of the service tenant to w, see backup.Write.
+func (o *DataService) Backup(w io.Writer) (backup.Manifest, error) {
+	return backup.Write(w, o.repo, []string{o.tenant})
+}
+
+// Restore verifies an archive and stores all its records into the service tenant, whatever tenant they
+// were archived from, see backup.Restore.
+func (o *DataService) Restore(r io.ReadSeeker) (backup.Result, error) {
+	return backup.Restore(r, o.repo, func(string) string { return o.tenant })
+}
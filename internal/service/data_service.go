@@ -2,29 +2,224 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"xis-data-aggregator/internal/alerting"
+	"xis-data-aggregator/internal/anomaly"
+	"xis-data-aggregator/internal/downsample"
+	"xis-data-aggregator/internal/hub"
+	"xis-data-aggregator/internal/metrics"
 	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/webhook"
+	"xis-data-aggregator/internal/windowing"
 
 	"github.com/google/uuid"
 )
 
 var (
-	ErrNotFound = errors.New("not found")
-	ErrCorrupt  = errors.New("corrupted data")
+	ErrNotFound      = errors.New("not found")
+	ErrCorrupt       = errors.New("corrupted data")
+	ErrRangeTooLarge = errors.New("time range too large")
+	ErrInvalidPack   = errors.New("invalid pack")
+	ErrBatchTooLarge = errors.New("batch too large")
+	ErrNoHub         = errors.New("live subscriptions are disabled")
+	ErrNoAlerting    = errors.New("alerting is disabled")
+	ErrNoWebhooks    = errors.New("webhooks are disabled")
+	ErrNoWindows     = errors.New("window aggregation is disabled")
+	ErrInvalidQuery  = errors.New("invalid query")
+	ErrReplayTooLong = errors.New("too many records to replay")
+)
+
+const (
+	maxBatchSize  = 1000 // Maximum number of IDs of a GetByIDs call
+	scanBatchSize = 1000 // Records read per repository call by range scans
 )
 
 type DataService struct {
-	repo models.Repository
+	repo         models.Repository
+	tenant       string                // Tenant the service is scoped to
+	stats        *metrics.TenantStats  // Per tenant counters, optional
+	types        *seriesTypes          // Value type per tenant series, shared by tenant copies
+	maxQuerySpan int64                 // Maximum `to - from` span of a range query, 0 - unlimited
+	hub          *hub.Hub              // Live subscriptions to stored records, optional
+	alerts       *alerting.Engine      // Alert rules evaluated on stored records, optional
+	webhooks     *webhook.Dispatcher   // Webhook notifications of stored records, optional
+	detector     *anomaly.Detector     // Anomaly scoring of records before they are stored, optional
+	windows      *windowing.Aggregator // Sliding and session windows of stored records, optional
 }
 
 func NewDataService(repo models.Repository) *DataService {
-	return &DataService{repo: repo}
+	return &DataService{repo: repo, types: newSeriesTypes(maxSeriesTypes)}
+}
+
+// ForTenant returns a copy of the service whose operations are scoped to the tenant's data.
+func (o *DataService) ForTenant(tenant string) *DataService {
+	scoped := *o
+	scoped.tenant = tenant
+	scoped.repo = o.repo.ForTenant(tenant)
+	return &scoped
+}
+
+// Tenant returns the tenant the service is scoped to.
+func (o *DataService) Tenant() string {
+	return o.tenant
+}
+
+// SetTenantStats enables per tenant counters. Must be called before ForTenant.
+func (o *DataService) SetTenantStats(stats *metrics.TenantStats) {
+	o.stats = stats
+}
+
+// SetHub enables live subscriptions: records stored by Ingest are published to the hub.
+// Must be called before ForTenant.
+func (o *DataService) SetHub(h *hub.Hub) {
+	o.hub = h
+}
+
+// SetAlerting enables alert rules: records stored by Ingest are evaluated by the engine.
+// Must be called before ForTenant.
+func (o *DataService) SetAlerting(engine *alerting.Engine) {
+	o.alerts = engine
+}
+
+// SetWebhooks enables webhook notifications: records stored by Ingest are queued for delivery.
+// Must be called before ForTenant.
+func (o *DataService) SetWebhooks(d *webhook.Dispatcher) {
+	o.webhooks = d
+}
+
+// SetDetector enables anomaly detection: records are scored before they are stored.
+// Must be called before ForTenant.
+func (o *DataService) SetDetector(d *anomaly.Detector) {
+	o.detector = d
+}
+
+// SetWindows enables window aggregation: records stored by Ingest are added to the windows of their series.
+// Must be called before ForTenant.
+func (o *DataService) SetWindows(a *windowing.Aggregator) {
+	o.windows = a
+}
+
+// Subscribe registers a live subscription to the tenant records stored from now on.
+// The filter tenant is overridden with the service tenant. Returns ErrNoHub if subscriptions are disabled.
+func (o *DataService) Subscribe(filter hub.Filter, policy hub.Policy, buffer int) (*hub.Subscription, error) {
+	if o.hub == nil {
+		return nil, ErrNoHub
+	}
+
+	filter.Tenant = o.tenant
+	return o.hub.Subscribe(filter, policy, buffer), nil
+}
+
+// Stats returns the counters of the service tenant.
+func (o *DataService) Stats() metrics.TenantCounters {
+	return o.stats.Get(o.tenant)
+}
+
+// Health checks that the repository responds.
+func (o *DataService) Health() error {
+	return o.repo.Ping()
+}
+
+// SetMaxQuerySpan caps the `to - from` span accepted by ListByPeriod. Zero disables the cap.
+func (o *DataService) SetMaxQuerySpan(span int64) {
+	o.maxQuerySpan = span
+}
+
+// checkSpan rejects reversed ranges and ranges wider than the maximum query span. The span is computed unsigned,
+// as `to - from` overflows int64 for bounds far apart.
+func (o *DataService) checkSpan(from, to int64) error {
+	if err := checkRange(from, to); err != nil {
+		return err
+	}
+	if span := uint64(to) - uint64(from); o.maxQuerySpan > 0 && span > uint64(o.maxQuerySpan) {
+		return fmt.Errorf("%w: span %d exceeds %d", ErrRangeTooLarge, span, o.maxQuerySpan)
+	}
+	return nil
+}
+
+// checkRange rejects reversed ranges.
+func checkRange(from, to int64) error {
+	if from > to {
+		return fmt.Errorf("%w: 'from' must not be greater than 'to'", ErrInvalidQuery)
+	}
+	return nil
 }
 
 func (o *DataService) Put(data *models.Data) error {
 	return o.repo.Put(data)
 }
 
+// Ingest maps a single pack to Data and stores it for the service tenant.
+// A pack without an ID gets a newly generated one.
+func (o *DataService) Ingest(pack *models.Pack) (*models.Data, error) {
+	data, err := o.ingest(pack)
+	o.stats.Ingested(o.tenant, err == nil)
+	return data, err
+}
+
+func (o *DataService) ingest(pack *models.Pack) (*models.Data, error) {
+	known, _ := o.types.lookup(o.tenant, pack.Series)
+	data, err := o.mapPack(pack, known)
+	if err != nil {
+		return nil, err
+	}
+
+	// Score against the recent behavior of the series, the score is stored with the record
+	o.detector.Score(data)
+
+	//  Try save to DB
+	err = o.Put(data)
+	if err != nil {
+		return nil, err
+	}
+	o.types.fix(o.tenant, pack.Series, pack.ValueType)
+
+	// Notify live subscribers and webhooks, evaluate alert rules and aggregate windows once the record is stored
+	o.hub.Publish(data)
+	o.webhooks.Publish(data)
+	o.alerts.Evaluate(data)
+	o.windows.Add(data)
+
+	return data, nil
+}
+
+// mapPack validates a pack of the service tenant against the known value type of its series (empty for
+// new series) and maps it to Data. A pack without an ID gets a newly generated one. The caller fixes the
+// type of a new series once the record is stored.
+func (o *DataService) mapPack(pack *models.Pack, known models.ValueType) (*models.Data, error) {
+	if pack == nil {
+		return nil, fmt.Errorf("pack is nil")
+	}
+	if pack.ID == uuid.Nil {
+		pack.ID = uuid.New()
+	}
+	pack.Tenant = o.tenant
+
+	valueType, err := resolveType(pack.Series, known, pack.ValueType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPack, err)
+	}
+	pack.ValueType = valueType
+
+	if err := pack.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPack, err)
+	}
+
+	// Try map pack to data
+	data, err := models.MapPackToData(pack)
+	switch {
+	case err != nil:
+		return nil, err
+	case data == nil: // extremely unlikely, reservation from nil pointer exception
+		return nil, fmt.Errorf("data is nil")
+	}
+
+	return data, nil
+}
+
 func (o *DataService) GetByID(id uuid.UUID) (*models.Data, error) {
+	o.stats.Queried(o.tenant)
 
 	data, err := o.repo.GetByID(id)
 	switch {
@@ -39,17 +234,229 @@ func (o *DataService) GetByID(id uuid.UUID) (*models.Data, error) {
 	return data, nil
 }
 
-func (o *DataService) ListByPeriod(from, to int64) ([]models.Data, error) {
-	data, err := o.repo.ListByPeriod(from, to)
+// GetByIDs returns the tenant records with the given IDs in request order and the IDs that were not found.
+// Duplicate IDs are looked up once.
+func (o *DataService) GetByIDs(ids []uuid.UUID) ([]models.Data, []uuid.UUID, error) {
+	if len(ids) > maxBatchSize {
+		return nil, nil, fmt.Errorf("%w: %d IDs exceed %d", ErrBatchTooLarge, len(ids), maxBatchSize)
+	}
+
+	o.stats.Queried(o.tenant)
+
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	return o.repo.GetByIDs(unique)
+}
+
+// ListByPeriod returns the tenant records within [from, to] that match the filter (nil - all records),
+// ordered and limited by opts.
+func (o *DataService) ListByPeriod(from, to int64, filter *models.Filter, opts models.ListOptions) ([]models.Data, error) {
+	if err := o.checkSpan(from, to); err != nil {
+		return nil, err
+	}
+
+	o.stats.Queried(o.tenant)
 
+	data, err := o.repo.Query(from, to, filter, opts)
 	switch {
 	case err != nil:
 		return []models.Data{}, err
-	case data == nil:
-		return nil, ErrNotFound
 	case len(data) == 0:
 		return []models.Data{}, ErrNotFound
 	}
 
 	return data, nil
 }
+
+// ListAnomalies returns the anomalous records within [from, to] that match the filter (nil - all records).
+// Returns the same errors as ListByPeriod, including not found errors if there are no anomalies.
+func (o *DataService) ListAnomalies(from, to int64, filter *models.Filter, opts models.ListOptions) ([]models.Data, error) {
+	anomalous := models.Filter{}
+	if filter != nil {
+		anomalous = *filter
+	}
+	anomalous.Anomalous = true
+
+	return o.ListByPeriod(from, to, &anomalous, opts)
+}
+
+// Downsample returns at most points tenant records per series within [from, to] matching the filter
+// (nil - all records), selected by the method, sorted by timestamp. The range is scanned in batches, yet it is
+// capped by the maximum query span like ListByPeriod. Returns an empty slice if there are no records.
+func (o *DataService) Downsample(from, to int64, filter *models.Filter, method downsample.Method, points int) ([]models.Data, error) {
+	if err := o.checkSpan(from, to); err != nil {
+		return nil, err
+	}
+
+	d, err := downsample.New(method, from, to, points)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+
+	err = o.Scan(from, to, filter, func(batch []models.Data) error {
+		for i := range batch {
+			d.Add(&batch[i])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return d.Result(), nil
+}
+
+// Scan passes the tenant records within [from, to] matching the filter (nil - all records) to fn in batches of
+// ascending timestamp, stopping at the first error of fn, which is returned. The range is capped by the maximum
+// query span like ListByPeriod, but an empty range is not an error.
+func (o *DataService) Scan(from, to int64, filter *models.Filter, fn func([]models.Data) error) error {
+	if err := o.checkSpan(from, to); err != nil {
+		return err
+	}
+
+	o.stats.Queried(o.tenant)
+
+	return o.repo.ScanByPeriod(from, to, scanBatchSize, func(batch []models.Data) error {
+		matched := batch[:0]
+		for i := range batch {
+			if filter.Matches(&batch[i]) {
+				matched = append(matched, batch[i])
+			}
+		}
+		if len(matched) == 0 {
+			return nil
+		}
+		return fn(matched)
+	})
+}
+
+// ListWindows returns the tenant window results of the kind (empty - any kind) starting within [from, to]
+// whose series match the filter (nil - all series), ordered and limited by opts.
+// Returns an empty slice if there are none, ErrNoWindows if window aggregation is disabled.
+func (o *DataService) ListWindows(from, to int64, kind models.WindowKind, filter *models.Filter, opts models.ListOptions) ([]models.WindowResult, error) {
+	if o.windows == nil {
+		return nil, ErrNoWindows
+	}
+	if err := o.checkSpan(from, to); err != nil {
+		return nil, err
+	}
+
+	o.stats.Queried(o.tenant)
+
+	return o.windows.List(o.tenant, from, to, opts, func(w *models.WindowResult) bool {
+		return (kind == "" || w.Kind == kind) && filter.MatchesSeries(w.Series, w.Labels)
+	})
+}
+
+// ListSince returns the tenant records with a timestamp of at least from that match the filter
+// (nil - all records), oldest first. Used to replay missed records to live subscribers, so the
+// range is not capped by the maximum query span. At most limit+1 records are read; returns
+// ErrReplayTooLong if more than limit records match, so that the subscriber re-syncs instead.
+func (o *DataService) ListSince(from int64, filter *models.Filter, limit int) ([]models.Data, error) {
+	data, err := o.repo.Query(from, math.MaxInt64, filter, models.ListOptions{Limit: limit + 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > limit {
+		return nil, fmt.Errorf("%w: more than %d records", ErrReplayTooLong, limit)
+	}
+
+	return data, nil
+}
+
+// Delete removes the tenant record with the given ID.
+func (o *DataService) Delete(id uuid.UUID) error {
+	return o.repo.Delete(id)
+}
+
+// DeleteByPeriod removes all tenant records within [from, to] and returns their number.
+// Returns ErrInvalidQuery if the range is reversed; the range is not capped by the maximum query span.
+func (o *DataService) DeleteByPeriod(from, to int64) (int64, error) {
+	if err := checkRange(from, to); err != nil {
+		return 0, err
+	}
+	return o.repo.DeleteByPeriod(from, to)
+}
+
+// Rules returns the alert rules of the service tenant. Returns ErrNoAlerting if alerting is disabled.
+func (o *DataService) Rules() ([]models.Rule, error) {
+	if o.alerts == nil {
+		return nil, ErrNoAlerting
+	}
+	return o.alerts.Rules(o.tenant), nil
+}
+
+// Rule returns an alert rule of the service tenant, see alerting.Engine.Rule.
+func (o *DataService) Rule(id string) (models.Rule, error) {
+	if o.alerts == nil {
+		return models.Rule{}, ErrNoAlerting
+	}
+	return o.alerts.Rule(o.tenant, id)
+}
+
+// CreateRule creates an alert rule of the service tenant, see alerting.Engine.CreateRule.
+func (o *DataService) CreateRule(rule models.Rule) (models.Rule, error) {
+	if o.alerts == nil {
+		return models.Rule{}, ErrNoAlerting
+	}
+	rule.Tenant = o.tenant
+	return o.alerts.CreateRule(rule)
+}
+
+// UpdateRule replaces an alert rule of the service tenant, see alerting.Engine.UpdateRule.
+func (o *DataService) UpdateRule(rule models.Rule) (models.Rule, error) {
+	if o.alerts == nil {
+		return models.Rule{}, ErrNoAlerting
+	}
+	rule.Tenant = o.tenant
+	return o.alerts.UpdateRule(rule)
+}
+
+// DeleteRule removes an alert rule of the service tenant, see alerting.Engine.DeleteRule.
+func (o *DataService) DeleteRule(id string) error {
+	if o.alerts == nil {
+		return ErrNoAlerting
+	}
+	return o.alerts.DeleteRule(o.tenant, id)
+}
+
+// Alerts returns the alerts of the service tenant, optionally only those in the state or of the rule.
+func (o *DataService) Alerts(state models.AlertState, ruleID string) ([]models.Alert, error) {
+	if o.alerts == nil {
+		return nil, ErrNoAlerting
+	}
+	return o.alerts.Alerts(o.tenant, state, ruleID)
+}
+
+// Webhooks returns the webhook endpoints of the service tenant without their secrets.
+// Returns ErrNoWebhooks if webhooks are disabled.
+func (o *DataService) Webhooks() ([]models.WebhookEndpoint, error) {
+	if o.webhooks == nil {
+		return nil, ErrNoWebhooks
+	}
+	return o.webhooks.Endpoints(o.tenant), nil
+}
+
+// WebhookDeliveries returns the most recent webhook delivery attempts of the service tenant, newest first,
+// optionally only those to the endpoint.
+func (o *DataService) WebhookDeliveries(endpointID string, limit int) ([]models.WebhookDelivery, error) {
+	if o.webhooks == nil {
+		return nil, ErrNoWebhooks
+	}
+	return o.webhooks.Deliveries(o.tenant, endpointID, limit)
+}
+
+// TestWebhook sends a sample record to a webhook endpoint of the service tenant, see webhook.Dispatcher.Test.
+func (o *DataService) TestWebhook(endpointID string) (models.WebhookDelivery, error) {
+	if o.webhooks == nil {
+		return models.WebhookDelivery{}, ErrNoWebhooks
+	}
+	return o.webhooks.Test(o.tenant, endpointID)
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCheckSpan tests that reversed ranges and spans above the maximum are rejected, also when `to - from` overflows.
+func TestCheckSpan(t *testing.T) {
+	o := &DataService{maxQuerySpan: 100}
+
+	tests := []struct {
+		name     string
+		from, to int64
+		wantErr  error
+	}{
+		{name: "within", from: 0, to: 100},
+		{name: "too large", from: 0, to: 101, wantErr: ErrRangeTooLarge},
+		{name: "reversed", from: 10, to: 0, wantErr: ErrInvalidQuery},
+		{name: "overflowing span", from: -9e18, to: 9e18, wantErr: ErrRangeTooLarge},
+		{name: "whole range", from: math.MinInt64, to: math.MaxInt64, wantErr: ErrRangeTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := o.checkSpan(tt.from, tt.to)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestDeleteByPeriodRange tests that a reversed range is rejected before anything is deleted.
+func TestDeleteByPeriodRange(t *testing.T) {
+	o := &DataService{} // no repository: it must not be reached
+
+	deleted, err := o.DeleteByPeriod(10, 0)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	assert.Zero(t, deleted)
+}
@@ -1,7 +1,6 @@
 package service
 
 import (
-	"fmt"
 	"sync"
 	"xis-data-aggregator/internal/models"
 
@@ -31,19 +30,9 @@ func ProcessData(wg *sync.WaitGroup, ds *DataService, inputChan <-chan *models.P
 
 func ProcessPack(pack *models.Pack, ds *DataService, metricsChan chan<- bool) error {
 
-	// Try map pack to data
-	var data, err = models.MapPackToData(pack)
-	switch {
-	case err != nil:
-		metricsChan <- false
-		return err
-	case data == nil: // extremely unlikely, reservation from nil pointer exception
-		metricsChan <- false
-		return fmt.Errorf("data is nil")
-	}
+	// Try map pack to data and save to DB of the pack tenant
+	_, err := ds.ForTenant(pack.Tenant).Ingest(pack)
 
-	//  Try save to DB
-	err = ds.Put(data)
 	if err != nil {
 		metricsChan <- false
 		return err
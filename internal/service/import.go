@@ -0,0 +1,65 @@
+package service
+
+import (
+	"xis-data-aggregator/internal/models"
+)
+
+// PackImport is a bulk import of historical packs into the service tenant, see NewImport.
+type PackImport struct {
+	svc    *DataService
+	types  *seriesTypes
+	dryRun bool
+}
+
+// NewImport starts a bulk import. Imported packs are validated and mapped like ingested ones, but stored
+// with one repository write per batch and not scored, published, evaluated by alert rules or windowed,
+// as they are history rather than live readings. A dry run validates the packs without storing them or
+// fixing the value types of new series.
+func (o *DataService) NewImport(dryRun bool) *PackImport {
+	types := o.types
+	if dryRun {
+		types = o.types.clone()
+	}
+	return &PackImport{svc: o, types: types, dryRun: dryRun}
+}
+
+// ImportPacks validates the packs and stores the valid ones in one batch. Returns the ErrInvalidPack error of
+// every invalid pack by index (nil for valid ones), or an error if the batch could not be stored.
+func (i *PackImport) ImportPacks(packs []*models.Pack) ([]error, error) {
+	errs := make([]error, len(packs))
+	batch := make([]*models.Data, 0, len(packs))
+	batchTypes := make(map[string]models.ValueType) // Series types of the batch, fixed once it is stored
+	for j, pack := range packs {
+		known, ok := batchTypes[pack.Series]
+		if !ok {
+			known, _ = i.types.lookup(i.svc.tenant, pack.Series)
+		}
+
+		data, err := i.svc.mapPack(pack, known)
+		if err != nil {
+			errs[j] = err
+			continue
+		}
+		if pack.Series != "" {
+			batchTypes[pack.Series] = pack.ValueType
+		}
+		batch = append(batch, data)
+	}
+
+	if !i.dryRun {
+		if err := i.svc.repo.PutBatch(batch); err != nil {
+			return nil, err
+		}
+	}
+	for series, t := range batchTypes {
+		i.types.fix(i.svc.tenant, series, t)
+	}
+	if i.dryRun {
+		return errs, nil
+	}
+
+	for _, err := range errs {
+		i.svc.stats.Ingested(i.svc.tenant, err == nil)
+	}
+	return errs, nil
+}
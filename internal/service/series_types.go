@@ -0,0 +1,99 @@
+package service
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"xis-data-aggregator/internal/models"
+)
+
+// maxSeriesTypes caps the series types remembered; the least recently used series are forgotten first,
+// and the next stored pack of a forgotten series fixes its type again.
+const maxSeriesTypes = 100_000
+
+// seriesType is the value type of a tenant series.
+type seriesType struct {
+	key       string // tenant + "/" + series
+	valueType models.ValueType
+}
+
+// seriesTypes remembers the value type of each tenant series, fixed by its first stored pack, up to a limit of
+// series. It is safe for concurrent use.
+type seriesTypes struct {
+	mu    sync.Mutex
+	limit int
+	types map[string]*list.Element // tenant + "/" + series -> element of order
+	order *list.List               // *seriesType, most recently used first
+}
+
+// newSeriesTypes creates an empty registry of at most limit series.
+func newSeriesTypes(limit int) *seriesTypes {
+	return &seriesTypes{limit: limit, types: make(map[string]*list.Element), order: list.New()}
+}
+
+// lookup returns the known value type of a named series, or false if the series is new.
+func (r *seriesTypes) lookup(tenant, series string) (models.ValueType, bool) {
+	if series == "" {
+		return "", false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.types[tenant+"/"+series]
+	if !ok {
+		return "", false
+	}
+	r.order.MoveToFront(e)
+	return e.Value.(*seriesType).valueType, true
+}
+
+// fix remembers the value type of a named series once a pack of it is stored. A known series keeps its type.
+func (r *seriesTypes) fix(tenant, series string, t models.ValueType) {
+	if series == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := tenant + "/" + series
+	if e, ok := r.types[key]; ok {
+		r.order.MoveToFront(e)
+		return
+	}
+
+	r.types[key] = r.order.PushFront(&seriesType{key: key, valueType: t})
+	for r.order.Len() > r.limit {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.types, oldest.Value.(*seriesType).key)
+	}
+}
+
+// clone returns a registry with the types known so far, whose later changes don't affect the original.
+func (r *seriesTypes) clone() *seriesTypes {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := newSeriesTypes(r.limit)
+	for e := r.order.Back(); e != nil; e = e.Prev() {
+		t := *e.Value.(*seriesType)
+		c.types[t.key] = c.order.PushFront(&t)
+	}
+	return c
+}
+
+// resolveType returns the value type of a pack: the declared type, the known type of its series, or int64.
+// known is empty for new and unnamed series; a declared type different from the known one is an error.
+func resolveType(series string, known, declared models.ValueType) (models.ValueType, error) {
+	switch {
+	case known != "" && declared != "" && declared != known:
+		return "", fmt.Errorf("series %q has value type %s, got %s", series, known, declared)
+	case known != "":
+		return known, nil
+	case declared == "":
+		return models.ValueTypeInt64, nil
+	}
+	return declared, nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"testing"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResolveType tests that declared types apply to new series and must match the known type of others.
+func TestResolveType(t *testing.T) {
+	tests := []struct {
+		name     string
+		series   string
+		known    models.ValueType
+		declared models.ValueType
+		want     models.ValueType
+		wantErr  bool
+	}{
+		{name: "new series default", series: "cpu", want: models.ValueTypeInt64},
+		{name: "new series declared", series: "cpu", declared: models.ValueTypeFloat64, want: models.ValueTypeFloat64},
+		{name: "known series", series: "cpu", known: models.ValueTypeFloat64, want: models.ValueTypeFloat64},
+		{name: "known series same type", series: "cpu", known: models.ValueTypeFloat64, declared: models.ValueTypeFloat64, want: models.ValueTypeFloat64},
+		{name: "known series other type", series: "cpu", known: models.ValueTypeInt64, declared: models.ValueTypeFloat64, wantErr: true},
+		{name: "unnamed series", declared: models.ValueTypeFloat64, want: models.ValueTypeFloat64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveType(tt.series, tt.known, tt.declared)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestSeriesTypes tests that known types are kept, the least recently used series are evicted over the limit
+// and clones are independent.
+func TestSeriesTypes(t *testing.T) {
+	r := newSeriesTypes(2)
+	r.fix("t", "a", models.ValueTypeFloat64)
+	r.fix("t", "a", models.ValueTypeInt64) // a known series keeps its type
+	r.fix("t", "b", models.ValueTypeInt64)
+	r.fix("", "", models.ValueTypeFloat64) // unnamed series are not remembered
+
+	got, ok := r.lookup("t", "a")
+	require.True(t, ok)
+	assert.Equal(t, models.ValueTypeFloat64, got)
+	_, ok = r.lookup("other", "a")
+	assert.False(t, ok, "types are per tenant")
+
+	// b is the least recently used one
+	r.fix("t", "c", models.ValueTypeInt64)
+	_, ok = r.lookup("t", "b")
+	assert.False(t, ok)
+	_, ok = r.lookup("t", "a")
+	assert.True(t, ok)
+
+	c := r.clone()
+	c.fix("t", "d", models.ValueTypeInt64)
+	_, ok = r.lookup("t", "d")
+	assert.False(t, ok)
+	_, ok = c.lookup("t", "a")
+	assert.True(t, ok)
+}
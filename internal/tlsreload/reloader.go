@@ -0,0 +1,172 @@
+// Package tlsreload provides TLS server configuration with certificate reload on file change.
+package tlsreload
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// Reloader keeps the server certificate and the client CA pool in sync with their files.
+// Changed files are detected by polling the modification time, so rotating certificates
+// doesn't require a restart.
+type Reloader struct {
+	certFile string // PEM certificate (chain) file
+	keyFile  string // PEM private key file
+	caFile   string // Optional PEM client CA bundle for mTLS
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTime   time.Time // Latest modification time among the watched files
+}
+
+// ClientAuthType returns the client certificate policy for mTLS:
+// no verification without a client CA, otherwise verify if given or require, as configured.
+func ClientAuthType(caFile string, requireClientCert bool) tls.ClientAuthType {
+	switch {
+	case caFile == "":
+		return tls.NoClientCert
+	case requireClientCert:
+		return tls.RequireAndVerifyClientCert
+	default:
+		return tls.VerifyClientCertIfGiven
+	}
+}
+
+// NewReloader loads the certificate, key and optional client CA bundle.
+// Returns an error if any of the files cannot be loaded.
+func NewReloader(certFile, keyFile, caFile string) (*Reloader, error) {
+	r := Reloader{certFile: certFile, keyFile: keyFile, caFile: caFile}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// load reads all files and swaps the current certificate and CA pool.
+func (r *Reloader) load() error {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load key pair: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("read client CA: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in client CA %s", r.caFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCAs = pool
+	r.modTime = modTime
+	r.mu.Unlock()
+
+	return nil
+}
+
+// latestModTime returns the most recent modification time among the watched files.
+func (r *Reloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+
+	for _, f := range []string{r.certFile, r.keyFile, r.caFile} {
+		if f == "" {
+			continue
+		}
+		info, err := os.Stat(f)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
+
+// Reload reloads the files if any of them changed since the last successful load.
+// On failure the previous certificate stays in use.
+func (r *Reloader) Reload() error {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+
+	r.mu.RLock()
+	changed := modTime.After(r.modTime)
+	r.mu.RUnlock()
+
+	if !changed {
+		return nil
+	}
+
+	if err := r.load(); err != nil {
+		return err
+	}
+
+	glog.Infof("TLS certificate reloaded from %s", r.certFile)
+	return nil
+}
+
+// Watch polls the files at the given interval until stopChan is closed.
+// It should be run as a goroutine.
+func (r *Reloader) Watch(interval time.Duration, stopChan <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.Reload(); err != nil {
+				glog.Errorf("TLS certificate reload error: %v", err)
+			}
+		case <-stopChan:
+			return
+		}
+	}
+}
+
+// ServerConfig returns a tls.Config that always serves the current certificate.
+// Client certificates are verified against the current client CA bundle
+// according to clientAuth; tls.NoClientCert disables client verification.
+func (r *Reloader) ServerConfig(clientAuth tls.ClientAuthType) *tls.Config {
+	base := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+
+		cfg := base.Clone()
+		cfg.GetConfigForClient = nil
+		cfg.Certificates = []tls.Certificate{*r.cert}
+
+		if clientAuth != tls.NoClientCert {
+			cfg.ClientCAs = r.clientCAs
+			cfg.ClientAuth = clientAuth
+		}
+
+		return cfg, nil
+	}
+
+	return base
+}
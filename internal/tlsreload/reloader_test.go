@@ -0,0 +1,79 @@
+// Package tlsreload contains tests for certificate reload on file change.
+package tlsreload
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSelfSigned writes a self-signed certificate with the given common name and its key to the files.
+func writeSelfSigned(t *testing.T, certFile, keyFile, commonName string, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+}
+
+// servedCommonName returns the common name of the certificate served by the config.
+func servedCommonName(t *testing.T, cfg *tls.Config) string {
+	served, err := cfg.GetConfigForClient(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	require.Len(t, served.Certificates, 1)
+
+	leaf, err := x509.ParseCertificate(served.Certificates[0].Certificate[0])
+	require.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+// TestReload tests that a rotated certificate is served after Reload and an unchanged one is kept.
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "server.crt")
+	keyFile := filepath.Join(dir, "server.key")
+	start := time.Now().Add(-time.Minute)
+
+	writeSelfSigned(t, certFile, keyFile, "first", start)
+
+	r, err := NewReloader(certFile, keyFile, "")
+	require.NoError(t, err)
+
+	cfg := r.ServerConfig(tls.NoClientCert)
+	assert.Equal(t, "first", servedCommonName(t, cfg))
+
+	// No change on disk: the certificate is kept
+	require.NoError(t, r.Reload())
+	assert.Equal(t, "first", servedCommonName(t, cfg))
+
+	// Rotate the certificate
+	writeSelfSigned(t, certFile, keyFile, "second", start.Add(time.Second))
+	require.NoError(t, r.Reload())
+	assert.Equal(t, "second", servedCommonName(t, cfg))
+}
@@ -0,0 +1,345 @@
+// Package webhook delivers newly stored Data records to HTTP endpoints as signed JSON payloads.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/golang/glog"
+	"github.com/google/uuid"
+)
+
+// Headers of webhook requests.
+const (
+	HeaderID        = "X-Webhook-ID"        // Delivery ID, the same for all attempts of a record
+	HeaderTimestamp = "X-Webhook-Timestamp" // Unix seconds of the attempt, part of the signature
+	HeaderSignature = "X-Webhook-Signature" // "sha256=" + hex HMAC-SHA256 of "<timestamp>.<body>"
+)
+
+// Payload events.
+const (
+	EventDataStored = "data.stored"
+	EventTest       = "test"
+)
+
+const (
+	requestTimeout = 10 * time.Second // Timeout of a single attempt
+	maxBackoff     = time.Minute      // Cap of the delay between attempts
+	logSize        = 1000             // Delivery attempts kept per tenant
+)
+
+var (
+	ErrEndpointNotFound = errors.New("webhook endpoint not found")
+)
+
+// Payload is the JSON body of a webhook request.
+type Payload struct {
+	ID       string      `json:"id"`       // Delivery ID, the same for all attempts of a record
+	Event    string      `json:"event"`    // data.stored or test
+	Endpoint string      `json:"endpoint"` // Endpoint ID
+	Time     time.Time   `json:"time"`     // Record time
+	Data     models.Data `json:"data"`     // Record, ts in stored Unix microseconds
+}
+
+// endpoint is a validated endpoint with its compiled selector.
+type endpoint struct {
+	models.WebhookEndpoint
+	filter *models.Filter
+}
+
+// job is a pending delivery attempt.
+type job struct {
+	endpoint *endpoint
+	payload  []byte
+	delivery models.WebhookDelivery
+}
+
+// Dispatcher queues the records published after they are stored and delivers them to the endpoints of their
+// tenant. Publishing never blocks: records that don't fit the queue are dropped and counted.
+// Failed attempts are retried with exponential backoff and jitter until maxAttempts.
+type Dispatcher struct {
+	endpoints   map[string][]*endpoint // By tenant, sorted by ID
+	log         models.DeliveryLog
+	client      *http.Client
+	queue       chan job
+	maxAttempts int
+	baseBackoff time.Duration // Delay before the second attempt, doubled for every further attempt
+	dropped     atomic.Uint64
+}
+
+// New creates a dispatcher for the endpoints, recording the attempts in the delivery log.
+// Returns an error if an endpoint is invalid or an ID is not unique within its tenant.
+func New(endpoints []models.WebhookEndpoint, log models.DeliveryLog, queueSize, maxAttempts int) (*Dispatcher, error) {
+	d := Dispatcher{
+		endpoints:   make(map[string][]*endpoint),
+		log:         log,
+		client:      &http.Client{Timeout: requestTimeout},
+		queue:       make(chan job, max(queueSize, 1)),
+		maxAttempts: max(maxAttempts, 1),
+		baseBackoff: time.Second,
+	}
+
+	seen := make(map[string]bool)
+	for _, e := range endpoints {
+		if err := e.Validate(); err != nil {
+			return nil, err
+		}
+		key := e.Tenant + "\x00" + e.ID
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate webhook ID %q of tenant %q", e.ID, e.Tenant)
+		}
+		seen[key] = true
+
+		filter, _ := e.Filter() // checked by Validate
+		d.endpoints[e.Tenant] = append(d.endpoints[e.Tenant], &endpoint{WebhookEndpoint: e, filter: filter})
+	}
+	for _, list := range d.endpoints {
+		sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	}
+
+	return &d, nil
+}
+
+// ReadEndpointsFile reads a JSON array of endpoints, e.g. [{"id": "ops", "url": "https://...", "secret": "..."}].
+func ReadEndpointsFile(path string) ([]models.WebhookEndpoint, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []models.WebhookEndpoint
+	if err := json.Unmarshal(b, &endpoints); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return endpoints, nil
+}
+
+// Sign returns the signature header value of a request body sent at the Unix timestamp (seconds).
+// Receivers recompute it with the shared secret and compare it with hmac.Equal.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Run delivers the queued records with the given number of workers until stop is closed.
+// Pending retries are abandoned on stop.
+func (d *Dispatcher) Run(workers int, stop <-chan struct{}) {
+	for i := 0; i < max(workers, 1); i++ {
+		go func() {
+			for {
+				select {
+				case <-stop:
+					return
+				case j := <-d.queue:
+					d.attempt(j)
+				}
+			}
+		}()
+	}
+	<-stop
+}
+
+// Publish queues the record for the matching endpoints of its tenant. Safe to call on a nil dispatcher.
+func (d *Dispatcher) Publish(data *models.Data) {
+	if d == nil || data == nil {
+		return
+	}
+
+	for _, e := range d.endpoints[data.Tenant] {
+		if !e.filter.Matches(data) || (e.MinMax != nil && data.Max.Float64() < *e.MinMax) {
+			continue
+		}
+
+		j, err := newJob(e, EventDataStored, data)
+		if err != nil {
+			glog.Errorf("Webhook %q payload error: %v", e.ID, err)
+			continue
+		}
+		d.enqueue(j)
+	}
+}
+
+// Dropped returns the number of deliveries dropped because the queue was full.
+func (d *Dispatcher) Dropped() uint64 {
+	return d.dropped.Load()
+}
+
+// Endpoints returns the endpoints of the tenant sorted by ID, without their secrets.
+func (d *Dispatcher) Endpoints(tenant string) []models.WebhookEndpoint {
+	endpoints := make([]models.WebhookEndpoint, 0, len(d.endpoints[tenant]))
+	for _, e := range d.endpoints[tenant] {
+		public := e.WebhookEndpoint
+		public.Secret = ""
+		endpoints = append(endpoints, public)
+	}
+	return endpoints
+}
+
+// Deliveries returns the most recent delivery attempts of the tenant, newest first,
+// optionally only those to the endpoint.
+func (d *Dispatcher) Deliveries(tenant, endpointID string, limit int) ([]models.WebhookDelivery, error) {
+	if endpointID == "" {
+		return d.log.ListDeliveries(tenant, limit)
+	}
+
+	all, err := d.log.ListDeliveries(tenant, logSize)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := all[:0]
+	for _, delivery := range all {
+		if delivery.EndpointID == endpointID && len(deliveries) < limit {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+// Test synchronously sends a sample record to the endpoint, once and without retries, and returns the
+// recorded attempt. Returns ErrEndpointNotFound if the tenant has no such endpoint.
+func (d *Dispatcher) Test(tenant, endpointID string) (models.WebhookDelivery, error) {
+	var target *endpoint
+	for _, e := range d.endpoints[tenant] {
+		if e.ID == endpointID {
+			target = e
+		}
+	}
+	if target == nil {
+		return models.WebhookDelivery{}, ErrEndpointNotFound
+	}
+
+	sample := models.Data{
+		ID:        uuid.New(),
+		Timestamp: models.Timestamp(time.Now()),
+		Max:       models.IntValue(0),
+		Tenant:    tenant,
+		Series:    "test",
+	}
+	j, err := newJob(target, EventTest, &sample)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	j.delivery.Test = true
+
+	return d.send(j, true), nil
+}
+
+// newJob creates the first attempt of a delivery of the record to the endpoint.
+func newJob(e *endpoint, event string, data *models.Data) (job, error) {
+	id := uuid.NewString()
+	payload, err := json.Marshal(Payload{
+		ID:       id,
+		Event:    event,
+		Endpoint: e.ID,
+		Time:     models.TimeOf(data.Timestamp).UTC(),
+		Data:     *data,
+	})
+	if err != nil {
+		return job{}, err
+	}
+
+	return job{
+		endpoint: e,
+		payload:  payload,
+		delivery: models.WebhookDelivery{ID: id, EndpointID: e.ID, Tenant: data.Tenant, RecordID: data.ID, Attempt: 1},
+	}, nil
+}
+
+// enqueue queues an attempt without blocking, dropping it if the queue is full.
+func (d *Dispatcher) enqueue(j job) {
+	select {
+	case d.queue <- j:
+	default:
+		d.dropped.Add(1)
+		glog.Warningf("Webhook %q delivery %s dropped: queue full", j.endpoint.ID, j.delivery.ID)
+	}
+}
+
+// attempt sends a queued attempt and schedules a retry if it failed.
+func (d *Dispatcher) attempt(j job) {
+	final := j.delivery.Attempt >= d.maxAttempts
+	if d.send(j, final).Success || final {
+		return
+	}
+
+	next := j
+	next.delivery.Attempt++
+	time.AfterFunc(d.backoff(j.delivery.Attempt), func() { d.enqueue(next) })
+}
+
+// send performs one attempt and records it in the delivery log.
+// A retry follows a failure unless final; the attempt is recorded as final if it succeeded or final is set.
+func (d *Dispatcher) send(j job, final bool) models.WebhookDelivery {
+	delivery := j.delivery
+	delivery.Time = time.Now().UTC()
+
+	status, err := d.post(j)
+	delivery.DurationMs = time.Since(delivery.Time).Milliseconds()
+	delivery.StatusCode = status
+	delivery.Success = err == nil
+	delivery.Final = delivery.Success || final
+	if err != nil {
+		delivery.Error = err.Error()
+		glog.Warningf("Webhook %q delivery %s attempt %d failed: %v", j.endpoint.ID, delivery.ID, delivery.Attempt, err)
+	}
+
+	if err := d.log.AppendDelivery(&delivery, logSize); err != nil {
+		glog.Errorf("Webhook delivery log error: %v", err)
+	}
+	return delivery
+}
+
+// post sends the signed payload and returns the response status. Any non-2xx status is an error.
+func (d *Dispatcher) post(j job) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, j.endpoint.URL, bytes.NewReader(j.payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "xis-data-aggregator-webhook")
+	req.Header.Set(HeaderID, j.delivery.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(j.endpoint.Secret, timestamp, j.payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // allow connection reuse
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay after the failed attempt: the base delay doubled per attempt, capped at maxBackoff,
+// randomized between half and the full delay so that retries to a recovering endpoint are spread out.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.baseBackoff << min(attempt-1, 30)
+	if delay <= 0 || delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
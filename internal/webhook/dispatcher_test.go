@@ -0,0 +1,166 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/repository/repotest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secret = "s3cret"
+
+// standIn is a local webhook receiver failing the first `failures` requests with 503.
+type standIn struct {
+	failures int32
+	requests atomic.Int32
+	payloads chan Payload
+}
+
+func (s *standIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if !hmac.Equal([]byte(r.Header.Get(HeaderSignature)), []byte(Sign(secret, r.Header.Get(HeaderTimestamp), body))) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if s.requests.Add(1) <= s.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	var p Payload
+	if err := json.Unmarshal(body, &p); err != nil || p.ID != r.Header.Get(HeaderID) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.payloads <- p
+}
+
+func newTestDispatcher(t *testing.T, failures int32, endpoints ...models.WebhookEndpoint) (*Dispatcher, *standIn) {
+	repo := repotest.New(t)
+
+	s := &standIn{failures: failures, payloads: make(chan Payload, 10)}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+
+	for i := range endpoints {
+		endpoints[i].URL = srv.URL
+		endpoints[i].Secret = secret
+		endpoints[i].Test = true
+	}
+
+	d, err := New(endpoints, repo, 10, 3)
+	require.NoError(t, err)
+	d.baseBackoff = time.Millisecond
+
+	stop := make(chan struct{})
+	t.Cleanup(func() { close(stop) })
+	go d.Run(2, stop)
+
+	return d, s
+}
+
+func TestDeliveryWithRetry(t *testing.T) {
+	minMax := 10.0
+	d, s := newTestDispatcher(t, 1, models.WebhookEndpoint{ID: "hook", Series: "cpu", MinMax: &minMax})
+
+	d.Publish(&models.Data{ID: uuid.New(), Series: "mem", Max: models.IntValue(50)}) // Other series
+	d.Publish(&models.Data{ID: uuid.New(), Series: "cpu", Max: models.IntValue(5)})  // Below min_max
+	data := models.Data{ID: uuid.New(), Series: "cpu", Max: models.IntValue(50), Timestamp: 1_700_000_000_000_000}
+	d.Publish(&data)
+
+	select {
+	case p := <-s.payloads:
+		assert.Equal(t, EventDataStored, p.Event)
+		assert.Equal(t, "hook", p.Endpoint)
+		assert.Equal(t, data.ID, p.Data.ID)
+		assert.Equal(t, models.TimeOf(data.Timestamp).UTC(), p.Time)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no delivery")
+	}
+
+	// The failed first attempt and the successful retry are logged, newest first
+	var log []models.WebhookDelivery
+	require.Eventually(t, func() bool {
+		var err error
+		log, err = d.Deliveries(models.DefaultTenant, "hook", 10)
+		return err == nil && len(log) == 2
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, log[0].ID, log[1].ID)
+	assert.Equal(t, 2, log[0].Attempt)
+	assert.True(t, log[0].Success && log[0].Final)
+	assert.Equal(t, http.StatusServiceUnavailable, log[1].StatusCode)
+	assert.False(t, log[1].Success || log[1].Final)
+}
+
+func TestDeliveryGivesUp(t *testing.T) {
+	d, _ := newTestDispatcher(t, 100, models.WebhookEndpoint{ID: "hook"})
+	d.Publish(&models.Data{ID: uuid.New()})
+
+	require.Eventually(t, func() bool {
+		log, err := d.Deliveries(models.DefaultTenant, "", 10)
+		return err == nil && len(log) == 3 && log[0].Final
+	}, 2*time.Second, time.Millisecond)
+}
+
+func TestTestDelivery(t *testing.T) {
+	d, s := newTestDispatcher(t, 0, models.WebhookEndpoint{ID: "hook"})
+
+	delivery, err := d.Test(models.DefaultTenant, "hook")
+	require.NoError(t, err)
+	assert.True(t, delivery.Success && delivery.Test)
+	assert.Equal(t, EventTest, (<-s.payloads).Event)
+
+	_, err = d.Test(models.DefaultTenant, "missing")
+	assert.ErrorIs(t, err, ErrEndpointNotFound)
+	_, err = d.Test("other", "hook")
+	assert.ErrorIs(t, err, ErrEndpointNotFound)
+
+	assert.Empty(t, d.Endpoints(models.DefaultTenant)[0].Secret)
+}
+
+func TestEndpointValidation(t *testing.T) {
+	tests := []struct {
+		endpoint models.WebhookEndpoint
+		valid    bool
+	}{
+		{models.WebhookEndpoint{ID: "a", URL: "https://example.com/hook", Secret: secret}, true},
+		{models.WebhookEndpoint{ID: "a", URL: "http://example.com/hook", Secret: secret}, false},
+		{models.WebhookEndpoint{ID: "a", URL: "https://127.0.0.1/hook", Secret: secret}, false},
+		{models.WebhookEndpoint{ID: "a", URL: "https://localhost/hook", Secret: secret}, false},
+		{models.WebhookEndpoint{ID: "a", URL: "http://localhost:9000/hook", Secret: secret, Test: true}, true},
+		{models.WebhookEndpoint{ID: "a", URL: "ftp://example.com", Secret: secret, Test: true}, false},
+		{models.WebhookEndpoint{ID: "a", URL: "https://example.com/hook"}, false},
+		{models.WebhookEndpoint{ID: "a b", URL: "https://example.com/hook", Secret: secret}, false},
+		{models.WebhookEndpoint{ID: "a", URL: "https://example.com/hook", Secret: secret, Labels: []string{"bad"}}, false},
+	}
+
+	for _, tt := range tests {
+		_, err := New([]models.WebhookEndpoint{tt.endpoint}, nil, 1, 1)
+		assert.Equal(t, tt.valid, err == nil, "%+v: %v", tt.endpoint, err)
+	}
+
+	dup := models.WebhookEndpoint{ID: "a", URL: "https://example.com/hook", Secret: secret}
+	_, err := New([]models.WebhookEndpoint{dup, dup}, nil, 1, 1)
+	assert.Error(t, err)
+}
+
+func TestQueueFull(t *testing.T) {
+	d, err := New([]models.WebhookEndpoint{{ID: "a", URL: "https://example.com/hook", Secret: secret}}, nil, 1, 1)
+	require.NoError(t, err)
+
+	// Not running: the second record doesn't fit the queue
+	d.Publish(&models.Data{ID: uuid.New()})
+	d.Publish(&models.Data{ID: uuid.New()})
+	assert.Equal(t, uint64(1), d.Dropped())
+}
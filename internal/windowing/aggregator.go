@@ -0,0 +1,376 @@
+// Package windowing aggregates stored Data records across packs into sliding and session windows per series,
+// by event time, and persists the window results.
+package windowing
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"xis-data-aggregator/internal/models"
+
+	"github.com/golang/glog"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxPanes caps the sliding windows a record belongs to (size / slide).
+	maxPanes = 1000
+	// idleSeriesTTL is how long a series without records is kept, at least the window size, gap and lateness.
+	idleSeriesTTL = time.Hour
+)
+
+// idNamespace is the UUID namespace of window result IDs.
+var idNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("xis-data-aggregator/windows"))
+
+// pane is the state of one window of a series.
+type pane struct {
+	start, end int64 // [start, end), stored Unix microseconds
+	count      int64
+	min, max   models.Value
+	sum        float64
+	emitted    uuid.UUID // ID of the stored result, uuid.Nil until the window is emitted
+	revision   int       // Revision of the stored result
+}
+
+// add adds a record value to the pane.
+func (p *pane) add(v models.Value) {
+	if p.count == 0 || v.Float64() < p.min.Float64() {
+		p.min = v
+	}
+	if p.count == 0 || v.Float64() > p.max.Float64() {
+		p.max = v
+	}
+	p.sum += v.Float64()
+	p.count++
+}
+
+// merge adds the records of another pane to the pane and extends its bounds.
+func (p *pane) merge(o *pane) {
+	if o.min.Float64() < p.min.Float64() {
+		p.min = o.min
+	}
+	if o.max.Float64() > p.max.Float64() {
+		p.max = o.max
+	}
+	p.sum += o.sum
+	p.count += o.count
+	p.start = min(p.start, o.start)
+	p.end = max(p.end, o.end)
+}
+
+// series is the window state of one tenant series.
+type series struct {
+	tenant    string
+	name      string
+	labels    map[string]string
+	key       string          // Series key within the tenant
+	watermark int64           // Latest record timestamp seen, the event time of the series
+	sliding   map[int64]*pane // Open sliding windows by start
+	sessions  []*pane         // Open session windows, sorted by start, not overlapping
+	lastSeen  time.Time       // Last record arrival
+}
+
+// write is a queued store write: a window result to store or, if nil, the result ID to remove.
+type write struct {
+	tenant string
+	id     uuid.UUID
+	result *models.WindowResult
+}
+
+// Aggregator assigns every stored record to the sliding and session windows of its series by record timestamp.
+//
+// The watermark of a series is its latest record timestamp. A window is emitted to the store once the watermark
+// reaches its end. Records up to the allowed lateness behind the watermark are still merged into their windows
+// (session windows may grow or merge); windows already emitted are then stored again with the next revision.
+// Older records are dropped and counted. Windows are forgotten once the watermark passes their end by the
+// allowed lateness. Open windows are kept in memory and lost on restart; the last windows of a series are
+// emitted when a later record advances its watermark or when the series is evicted, after idleSeriesTTL without
+// records. Results are written to the store after the lock is released.
+type Aggregator struct {
+	mu        sync.Mutex
+	store     models.WindowStore
+	size      int64              // Sliding window size, 0 - sliding windows disabled
+	slide     int64              // Sliding window start interval
+	gap       int64              // Session gap, 0 - session windows disabled
+	lateness  int64              // Allowed lateness behind the watermark
+	series    map[string]*series // By tenant and series key
+	pending   []write            // Store writes, in order
+	lastSweep time.Time
+	now       func() time.Time // Clock, replaceable in tests
+	dropped   atomic.Uint64
+
+	writeMu sync.Mutex // Held while writing to the store, so that concurrent writes keep their order
+}
+
+// New creates an aggregator of sliding windows of the given size starting every slide (a slide of 0 or above
+// the size makes them tumbling) and of session windows closed by the given gap, accepting records up to the
+// allowed lateness behind the series watermark. A zero size or gap disables the respective windows.
+// Returns an error if a duration is negative or a record would belong to more than 1000 sliding windows.
+func New(store models.WindowStore, size, slide, gap, lateness time.Duration) (*Aggregator, error) {
+	if size < 0 || slide < 0 || gap < 0 || lateness < 0 {
+		return nil, fmt.Errorf("window durations must not be negative")
+	}
+	if slide == 0 || slide > size {
+		slide = size
+	}
+	if size > 0 && slide < time.Microsecond {
+		return nil, fmt.Errorf("window slide %v is below a microsecond", slide)
+	}
+
+	a := Aggregator{
+		store:    store,
+		size:     size.Microseconds(),
+		slide:    slide.Microseconds(),
+		gap:      gap.Microseconds(),
+		lateness: lateness.Microseconds(),
+		series:   make(map[string]*series),
+		now:      time.Now,
+	}
+	if a.size > 0 && a.size/a.slide > maxPanes {
+		return nil, fmt.Errorf("window size %v / slide %v exceeds %d windows per record", size, slide, maxPanes)
+	}
+
+	return &a, nil
+}
+
+// Dropped returns the number of records dropped because they were later than the allowed lateness.
+func (a *Aggregator) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// List returns the window results of the tenant starting within [from, to] that match (nil - all results),
+// ordered by start and limited by the options.
+func (a *Aggregator) List(tenant string, from, to int64, opts models.ListOptions, match func(*models.WindowResult) bool) ([]models.WindowResult, error) {
+	return a.store.ListWindows(tenant, from, to, opts, match)
+}
+
+// Add assigns a stored record to its windows and emits the windows it completes or updates.
+// Safe to call on a nil aggregator.
+func (a *Aggregator) Add(data *models.Data) {
+	if a == nil || data == nil {
+		return
+	}
+
+	a.mu.Lock()
+	now := a.now()
+	a.sweep(now)
+	a.add(data, now)
+	queued := len(a.pending) > 0
+	a.mu.Unlock()
+
+	if queued {
+		a.flush()
+	}
+}
+
+// add assigns the record to its windows. Must be called with mu held.
+func (a *Aggregator) add(data *models.Data, now time.Time) {
+	key := data.SeriesKey()
+	ts := data.Timestamp
+
+	s := a.series[data.Tenant+"\x00"+key]
+	if s == nil {
+		s = &series{
+			tenant:    data.Tenant,
+			name:      data.Series,
+			labels:    data.Labels,
+			key:       key,
+			watermark: ts,
+			sliding:   make(map[int64]*pane),
+		}
+		a.series[data.Tenant+"\x00"+key] = s
+	}
+	s.lastSeen = now
+
+	if ts < s.watermark-a.lateness {
+		a.dropped.Add(1)
+		glog.V(1).Infof("Window record %s of series %s dropped: %d behind the watermark", data.ID, key, s.watermark-ts)
+		return
+	}
+
+	if a.size > 0 {
+		a.addSliding(s, ts, data.Max)
+	}
+	if a.gap > 0 {
+		a.addSession(s, ts, data.Max)
+	}
+
+	if ts > s.watermark {
+		s.watermark = ts
+		a.advance(s)
+	}
+}
+
+// addSliding adds the value to every sliding window containing ts, re-emitting the updated windows.
+func (a *Aggregator) addSliding(s *series, ts int64, v models.Value) {
+	for start := floorDiv(ts, a.slide) * a.slide; start > ts-a.size; start -= a.slide {
+		p := s.sliding[start]
+		if p == nil {
+			p = &pane{start: start, end: start + a.size}
+			s.sliding[start] = p
+		}
+		p.add(v)
+
+		if p.emitted != uuid.Nil || p.end <= s.watermark {
+			a.emit(s, models.WindowSliding, p)
+		}
+	}
+}
+
+// addSession adds the value to the session window [ts, ts+gap), merging the sessions it overlaps.
+// Emitted sessions that are merged into another one are removed from the store.
+func (a *Aggregator) addSession(s *series, ts int64, v models.Value) {
+	p := &pane{start: ts, end: ts + a.gap}
+	p.add(v)
+
+	var merged []*pane
+	kept := s.sessions[:0]
+	for _, o := range s.sessions {
+		if o.start < p.end && ts < o.end {
+			merged = append(merged, o)
+		} else {
+			kept = append(kept, o)
+		}
+	}
+	for _, o := range merged {
+		p.merge(o)
+	}
+
+	// The merged session is the next revision of the emitted sessions it contains. It keeps the result ID of
+	// the session with the same start, the results of the others are removed.
+	id := a.windowID(s, models.WindowSession, p.start)
+	for _, o := range merged {
+		if o.emitted == uuid.Nil {
+			continue
+		}
+		if p.emitted == uuid.Nil || o.revision > p.revision {
+			p.emitted, p.revision = o.emitted, o.revision
+		}
+		if o.emitted != id {
+			a.pending = append(a.pending, write{tenant: s.tenant, id: o.emitted})
+		}
+	}
+
+	s.sessions = append(kept, p)
+	sort.Slice(s.sessions, func(i, j int) bool { return s.sessions[i].start < s.sessions[j].start })
+
+	if p.emitted != uuid.Nil || p.end <= s.watermark {
+		a.emit(s, models.WindowSession, p)
+	}
+}
+
+// advance emits the windows the series watermark has reached and forgets those beyond the allowed lateness.
+func (a *Aggregator) advance(s *series) {
+	horizon := s.watermark - a.lateness
+
+	for start, p := range s.sliding {
+		if p.emitted == uuid.Nil && p.end <= s.watermark {
+			a.emit(s, models.WindowSliding, p)
+		}
+		if p.end <= horizon {
+			delete(s.sliding, start)
+		}
+	}
+
+	open := s.sessions[:0]
+	for _, p := range s.sessions {
+		if p.emitted == uuid.Nil && p.end <= s.watermark {
+			a.emit(s, models.WindowSession, p)
+		}
+		if p.end > horizon {
+			open = append(open, p)
+		}
+	}
+	s.sessions = open
+}
+
+// windowID returns the result ID of the series window of the kind starting at start.
+func (a *Aggregator) windowID(s *series, kind models.WindowKind, start int64) uuid.UUID {
+	return uuid.NewSHA1(idNamespace, []byte(s.tenant+"\x00"+s.key+"\x00"+string(kind)+"\x00"+strconv.FormatInt(start, 10)))
+}
+
+// sweep evicts the series idle for idleSeriesTTL, or the window size, gap or lateness if longer, at most once
+// per idleSeriesTTL, emitting their open windows. Must be called with mu held.
+func (a *Aggregator) sweep(now time.Time) {
+	if now.Sub(a.lastSweep) < idleSeriesTTL {
+		return
+	}
+	a.lastSweep = now
+
+	ttl := max(idleSeriesTTL, time.Duration(max(a.size, a.gap, a.lateness))*time.Microsecond)
+	for key, s := range a.series {
+		if now.Sub(s.lastSeen) <= ttl {
+			continue
+		}
+		for _, p := range s.sliding {
+			if p.emitted == uuid.Nil {
+				a.emit(s, models.WindowSliding, p)
+			}
+		}
+		for _, p := range s.sessions {
+			if p.emitted == uuid.Nil {
+				a.emit(s, models.WindowSession, p)
+			}
+		}
+		delete(a.series, key)
+	}
+}
+
+// emit queues the result of the window, as the next revision if it was emitted before.
+func (a *Aggregator) emit(s *series, kind models.WindowKind, p *pane) {
+	id := a.windowID(s, kind, p.start)
+	if p.emitted != uuid.Nil {
+		p.revision++
+	}
+	p.emitted = id
+
+	w := models.WindowResult{
+		ID:       id,
+		Kind:     kind,
+		Start:    p.start,
+		End:      p.end,
+		Tenant:   s.tenant,
+		Series:   s.name,
+		Labels:   s.labels,
+		Count:    p.count,
+		Min:      p.min,
+		Max:      p.max,
+		Sum:      p.sum,
+		Avg:      p.sum / float64(p.count),
+		Revision: p.revision,
+	}
+	a.pending = append(a.pending, write{tenant: s.tenant, id: id, result: &w})
+}
+
+// flush writes the queued results to the store in order. Must be called without mu held.
+func (a *Aggregator) flush() {
+	a.writeMu.Lock()
+	defer a.writeMu.Unlock()
+
+	a.mu.Lock()
+	pending := a.pending
+	a.pending = nil
+	a.mu.Unlock()
+
+	for _, w := range pending {
+		if w.result == nil {
+			if err := a.store.DeleteWindow(w.tenant, w.id); err != nil {
+				glog.Errorf("Window %s of tenant %q delete error: %v", w.id, w.tenant, err)
+			}
+		} else if err := a.store.PutWindow(w.result); err != nil {
+			glog.Errorf("Window %s of tenant %q store error: %v", w.id, w.tenant, err)
+		}
+	}
+}
+
+// floorDiv returns x / y rounded towards negative infinity.
+func floorDiv(x, y int64) int64 {
+	q := x / y
+	if x%y != 0 && (x < 0) != (y < 0) {
+		q--
+	}
+	return q
+}
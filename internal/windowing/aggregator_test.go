@@ -0,0 +1,160 @@
+package windowing
+
+import (
+	"math"
+	"testing"
+	"time"
+	"xis-data-aggregator/internal/models"
+	"xis-data-aggregator/internal/repository/repotest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sec = int64(time.Second / time.Microsecond)
+
+func newTestAggregator(t *testing.T, size, slide, gap, lateness time.Duration) *Aggregator {
+	a, err := New(repotest.New(t), size, slide, gap, lateness)
+	require.NoError(t, err)
+	return a
+}
+
+func add(a *Aggregator, ts, v int64) {
+	a.Add(&models.Data{ID: uuid.New(), Timestamp: ts * sec, Max: models.IntValue(v), Series: "cpu"})
+}
+
+// windows returns the stored results of the kind by start second.
+func windows(t *testing.T, a *Aggregator, kind models.WindowKind) map[int64]models.WindowResult {
+	list, err := a.List(models.DefaultTenant, math.MinInt64, math.MaxInt64, models.ListOptions{}, nil)
+	require.NoError(t, err)
+
+	byStart := make(map[int64]models.WindowResult)
+	for _, w := range list {
+		if w.Kind == kind {
+			byStart[w.Start/sec] = w
+		}
+	}
+	return byStart
+}
+
+func TestSlidingWindows(t *testing.T) {
+	a := newTestAggregator(t, 10*time.Second, 5*time.Second, 0, 5*time.Second)
+
+	add(a, 1, 1)
+	assert.Empty(t, windows(t, a, models.WindowSliding))
+
+	// The watermark reaches the end of [-5s, 5s)
+	add(a, 6, 2)
+	got := windows(t, a, models.WindowSliding)
+	require.Len(t, got, 1)
+	assert.Equal(t, int64(1), got[-5].Count)
+
+	// and of [0s, 10s)
+	add(a, 11, 3)
+	got = windows(t, a, models.WindowSliding)
+	require.Len(t, got, 2)
+	assert.Equal(t, 10*sec, got[0].End)
+	assert.Equal(t, int64(2), got[0].Count)
+	assert.Equal(t, models.IntValue(1), got[0].Min)
+	assert.Equal(t, models.IntValue(2), got[0].Max)
+	assert.Equal(t, 1.5, got[0].Avg)
+	assert.Zero(t, got[0].Revision)
+
+	// A late record within the allowed lateness updates the emitted window
+	add(a, 8, 10)
+	got = windows(t, a, models.WindowSliding)
+	assert.Equal(t, int64(3), got[0].Count)
+	assert.Equal(t, models.IntValue(10), got[0].Max)
+	assert.Equal(t, 1, got[0].Revision)
+	assert.Equal(t, uint64(0), a.Dropped())
+
+	// Later ones are dropped
+	add(a, 2, 100)
+	assert.Equal(t, uint64(1), a.Dropped())
+	assert.Equal(t, int64(3), windows(t, a, models.WindowSliding)[0].Count)
+
+	// [5s, 15s) holds 6s, 8s and 11s once the watermark passes it
+	add(a, 16, 4)
+	got = windows(t, a, models.WindowSliding)
+	assert.Equal(t, int64(3), got[5].Count)
+	assert.Equal(t, 15.0, got[5].Sum)
+}
+
+func TestSessionWindows(t *testing.T) {
+	a := newTestAggregator(t, 0, 0, 3*time.Second, 10*time.Second)
+
+	add(a, 1, 1)
+	add(a, 2, 2)
+	add(a, 10, 3)
+	got := windows(t, a, models.WindowSession)
+	require.Len(t, got, 1)
+	assert.Equal(t, 5*sec, got[1].End)
+	assert.Equal(t, int64(2), got[1].Count)
+
+	// A late record opens a session between the others, emitted at once as the watermark passed it
+	add(a, 6, 4)
+	got = windows(t, a, models.WindowSession)
+	require.Len(t, got, 2)
+	assert.Equal(t, 9*sec, got[6].End)
+
+	// Another one bridges both sessions: the merged session replaces them
+	add(a, 4, 5)
+	got = windows(t, a, models.WindowSession)
+	require.Len(t, got, 1)
+	assert.Equal(t, 9*sec, got[1].End)
+	assert.Equal(t, int64(4), got[1].Count)
+	assert.Equal(t, models.IntValue(5), got[1].Max)
+	assert.Equal(t, 1, got[1].Revision)
+	assert.Empty(t, windows(t, a, models.WindowSliding))
+}
+
+func TestSeriesIsolation(t *testing.T) {
+	a := newTestAggregator(t, 10*time.Second, 0, 0, 0)
+
+	a.Add(&models.Data{ID: uuid.New(), Timestamp: 1 * sec, Max: models.IntValue(1), Series: "cpu"})
+	a.Add(&models.Data{ID: uuid.New(), Timestamp: 1 * sec, Max: models.IntValue(1), Series: "cpu", Tenant: "t1"})
+	a.Add(&models.Data{ID: uuid.New(), Timestamp: 20 * sec, Max: models.IntValue(1), Series: "mem"})
+	assert.Empty(t, windows(t, a, models.WindowSliding))
+
+	// Each series has its own watermark
+	a.Add(&models.Data{ID: uuid.New(), Timestamp: 20 * sec, Max: models.IntValue(1), Series: "cpu", Tenant: "t1"})
+	assert.Empty(t, windows(t, a, models.WindowSliding))
+
+	list, err := a.List("t1", 0, math.MaxInt64, models.ListOptions{}, nil)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "t1", list[0].Tenant)
+	assert.Equal(t, "cpu", list[0].Series)
+}
+
+func TestSweep(t *testing.T) {
+	a := newTestAggregator(t, 10*time.Second, 0, 3*time.Second, 0)
+	now := time.Now()
+	a.now = func() time.Time { return now }
+
+	add(a, 1, 1)
+	a.Add(&models.Data{ID: uuid.New(), Timestamp: 1 * sec, Max: models.IntValue(1), Series: "mem"})
+	now = now.Add(idleSeriesTTL + time.Second)
+	assert.Empty(t, windows(t, a, models.WindowSliding))
+
+	// The next record evicts the idle series, emitting their open windows
+	add(a, 2, 2)
+	assert.Len(t, a.series, 1)
+	list, err := a.List(models.DefaultTenant, math.MinInt64, math.MaxInt64, models.ListOptions{}, nil)
+	require.NoError(t, err)
+	require.Len(t, list, 4)
+	for _, w := range list {
+		assert.Equal(t, int64(1), w.Count)
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(nil, time.Hour, time.Microsecond, 0, 0)
+	assert.Error(t, err)
+	_, err = New(nil, -time.Second, 0, 0, 0)
+	assert.Error(t, err)
+
+	var a *Aggregator
+	a.Add(&models.Data{}) // nil-safe
+}
@@ -15,6 +15,7 @@ import (
 
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
@@ -24,6 +25,352 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Outcome of a single item of a stream or batch, sent instead of aborting the stream
+type ItemStatus int32
+
+const (
+	ItemStatus_ITEM_STATUS_OK        ItemStatus = 0 // Found or stored
+	ItemStatus_ITEM_STATUS_NOT_FOUND ItemStatus = 1
+	ItemStatus_ITEM_STATUS_INVALID   ItemStatus = 2 // Invalid request item
+	ItemStatus_ITEM_STATUS_ERROR     ItemStatus = 3 // Internal error
+)
+
+// Enum value maps for ItemStatus.
+var (
+	ItemStatus_name = map[int32]string{
+		0: "ITEM_STATUS_OK",
+		1: "ITEM_STATUS_NOT_FOUND",
+		2: "ITEM_STATUS_INVALID",
+		3: "ITEM_STATUS_ERROR",
+	}
+	ItemStatus_value = map[string]int32{
+		"ITEM_STATUS_OK":        0,
+		"ITEM_STATUS_NOT_FOUND": 1,
+		"ITEM_STATUS_INVALID":   2,
+		"ITEM_STATUS_ERROR":     3,
+	}
+)
+
+func (x ItemStatus) Enum() *ItemStatus {
+	p := new(ItemStatus)
+	*p = x
+	return p
+}
+
+func (x ItemStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ItemStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_data_proto_enumTypes[0].Descriptor()
+}
+
+func (ItemStatus) Type() protoreflect.EnumType {
+	return &file_proto_data_proto_enumTypes[0]
+}
+
+func (x ItemStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ItemStatus.Descriptor instead.
+func (ItemStatus) EnumDescriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{0}
+}
+
+// Sort order by timestamp
+type Order int32
+
+const (
+	Order_ORDER_ASC  Order = 0
+	Order_ORDER_DESC Order = 1
+)
+
+// Enum value maps for Order.
+var (
+	Order_name = map[int32]string{
+		0: "ORDER_ASC",
+		1: "ORDER_DESC",
+	}
+	Order_value = map[string]int32{
+		"ORDER_ASC":  0,
+		"ORDER_DESC": 1,
+	}
+)
+
+func (x Order) Enum() *Order {
+	p := new(Order)
+	*p = x
+	return p
+}
+
+func (x Order) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Order) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_data_proto_enumTypes[1].Descriptor()
+}
+
+func (Order) Type() protoreflect.EnumType {
+	return &file_proto_data_proto_enumTypes[1]
+}
+
+func (x Order) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Order.Descriptor instead.
+func (Order) EnumDescriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{1}
+}
+
+// Sample value type of a series
+type ValueType int32
+
+const (
+	ValueType_VALUE_TYPE_UNSPECIFIED ValueType = 0 // Series type, int64 for new series
+	ValueType_VALUE_TYPE_INT64       ValueType = 1
+	ValueType_VALUE_TYPE_FLOAT64     ValueType = 2
+)
+
+// Enum value maps for ValueType.
+var (
+	ValueType_name = map[int32]string{
+		0: "VALUE_TYPE_UNSPECIFIED",
+		1: "VALUE_TYPE_INT64",
+		2: "VALUE_TYPE_FLOAT64",
+	}
+	ValueType_value = map[string]int32{
+		"VALUE_TYPE_UNSPECIFIED": 0,
+		"VALUE_TYPE_INT64":       1,
+		"VALUE_TYPE_FLOAT64":     2,
+	}
+)
+
+func (x ValueType) Enum() *ValueType {
+	p := new(ValueType)
+	*p = x
+	return p
+}
+
+func (x ValueType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ValueType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_data_proto_enumTypes[2].Descriptor()
+}
+
+func (ValueType) Type() protoreflect.EnumType {
+	return &file_proto_data_proto_enumTypes[2]
+}
+
+func (x ValueType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ValueType.Descriptor instead.
+func (ValueType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{2}
+}
+
+// Handling of a subscriber whose buffer is full
+type SlowConsumerPolicy int32
+
+const (
+	SlowConsumerPolicy_SLOW_CONSUMER_POLICY_DROP       SlowConsumerPolicy = 0 // Drop records that don't fit the buffer
+	SlowConsumerPolicy_SLOW_CONSUMER_POLICY_DISCONNECT SlowConsumerPolicy = 1 // End the stream with RESOURCE_EXHAUSTED
+)
+
+// Enum value maps for SlowConsumerPolicy.
+var (
+	SlowConsumerPolicy_name = map[int32]string{
+		0: "SLOW_CONSUMER_POLICY_DROP",
+		1: "SLOW_CONSUMER_POLICY_DISCONNECT",
+	}
+	SlowConsumerPolicy_value = map[string]int32{
+		"SLOW_CONSUMER_POLICY_DROP":       0,
+		"SLOW_CONSUMER_POLICY_DISCONNECT": 1,
+	}
+)
+
+func (x SlowConsumerPolicy) Enum() *SlowConsumerPolicy {
+	p := new(SlowConsumerPolicy)
+	*p = x
+	return p
+}
+
+func (x SlowConsumerPolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SlowConsumerPolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_data_proto_enumTypes[3].Descriptor()
+}
+
+func (SlowConsumerPolicy) Type() protoreflect.EnumType {
+	return &file_proto_data_proto_enumTypes[3]
+}
+
+func (x SlowConsumerPolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SlowConsumerPolicy.Descriptor instead.
+func (SlowConsumerPolicy) EnumDescriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{3}
+}
+
+// Downsampling algorithm
+type DownsampleMethod int32
+
+const (
+	DownsampleMethod_DOWNSAMPLE_METHOD_LTTB   DownsampleMethod = 0 // Largest-Triangle-Three-Buckets
+	DownsampleMethod_DOWNSAMPLE_METHOD_MINMAX DownsampleMethod = 1 // Minimum and maximum record per time bucket
+)
+
+// Enum value maps for DownsampleMethod.
+var (
+	DownsampleMethod_name = map[int32]string{
+		0: "DOWNSAMPLE_METHOD_LTTB",
+		1: "DOWNSAMPLE_METHOD_MINMAX",
+	}
+	DownsampleMethod_value = map[string]int32{
+		"DOWNSAMPLE_METHOD_LTTB":   0,
+		"DOWNSAMPLE_METHOD_MINMAX": 1,
+	}
+)
+
+func (x DownsampleMethod) Enum() *DownsampleMethod {
+	p := new(DownsampleMethod)
+	*p = x
+	return p
+}
+
+func (x DownsampleMethod) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DownsampleMethod) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_data_proto_enumTypes[4].Descriptor()
+}
+
+func (DownsampleMethod) Type() protoreflect.EnumType {
+	return &file_proto_data_proto_enumTypes[4]
+}
+
+func (x DownsampleMethod) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DownsampleMethod.Descriptor instead.
+func (DownsampleMethod) EnumDescriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{4}
+}
+
+// Kind of a stream aggregation window
+type WindowKind int32
+
+const (
+	WindowKind_WINDOW_KIND_UNSPECIFIED WindowKind = 0 // Any kind in requests
+	WindowKind_WINDOW_KIND_SLIDING     WindowKind = 1 // Fixed size windows starting every slide
+	WindowKind_WINDOW_KIND_SESSION     WindowKind = 2 // Records of a series separated by less than the session gap
+)
+
+// Enum value maps for WindowKind.
+var (
+	WindowKind_name = map[int32]string{
+		0: "WINDOW_KIND_UNSPECIFIED",
+		1: "WINDOW_KIND_SLIDING",
+		2: "WINDOW_KIND_SESSION",
+	}
+	WindowKind_value = map[string]int32{
+		"WINDOW_KIND_UNSPECIFIED": 0,
+		"WINDOW_KIND_SLIDING":     1,
+		"WINDOW_KIND_SESSION":     2,
+	}
+)
+
+func (x WindowKind) Enum() *WindowKind {
+	p := new(WindowKind)
+	*p = x
+	return p
+}
+
+func (x WindowKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WindowKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_data_proto_enumTypes[5].Descriptor()
+}
+
+func (WindowKind) Type() protoreflect.EnumType {
+	return &file_proto_data_proto_enumTypes[5]
+}
+
+func (x WindowKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WindowKind.Descriptor instead.
+func (WindowKind) EnumDescriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{5}
+}
+
+type LabelMatcher_Type int32
+
+const (
+	LabelMatcher_EQUAL     LabelMatcher_Type = 0
+	LabelMatcher_NOT_EQUAL LabelMatcher_Type = 1
+	LabelMatcher_REGEX     LabelMatcher_Type = 2
+	LabelMatcher_NOT_REGEX LabelMatcher_Type = 3
+)
+
+// Enum value maps for LabelMatcher_Type.
+var (
+	LabelMatcher_Type_name = map[int32]string{
+		0: "EQUAL",
+		1: "NOT_EQUAL",
+		2: "REGEX",
+		3: "NOT_REGEX",
+	}
+	LabelMatcher_Type_value = map[string]int32{
+		"EQUAL":     0,
+		"NOT_EQUAL": 1,
+		"REGEX":     2,
+		"NOT_REGEX": 3,
+	}
+)
+
+func (x LabelMatcher_Type) Enum() *LabelMatcher_Type {
+	p := new(LabelMatcher_Type)
+	*p = x
+	return p
+}
+
+func (x LabelMatcher_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LabelMatcher_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_data_proto_enumTypes[6].Descriptor()
+}
+
+func (LabelMatcher_Type) Type() protoreflect.EnumType {
+	return &file_proto_data_proto_enumTypes[6]
+}
+
+func (x LabelMatcher_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LabelMatcher_Type.Descriptor instead.
+func (LabelMatcher_Type) EnumDescriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{4, 0}
+}
+
 // Single request
 type GetDataByIDRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -69,11 +416,15 @@ func (x *GetDataByIDRequest) GetId() string {
 	return ""
 }
 
-// Request of packet
+// Request of packet, superseded by ListDataByTimeRangeRequestV2
 type ListDataByTimeRangeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"` // RFC3339, unit-suffixed ("1704207845s") or plain integer in the server precision
 	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Series        string                 `protobuf:"bytes,3,opt,name=series,proto3" json:"series,omitempty"`                     // Optional series name
+	Matchers      []*LabelMatcher        `protobuf:"bytes,4,rep,name=matchers,proto3" json:"matchers,omitempty"`                 // Optional label matchers, all must match
+	FromTime      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=from_time,json=fromTime,proto3" json:"from_time,omitempty"` // Takes precedence over `from`
+	ToTime        *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=to_time,json=toTime,proto3" json:"to_time,omitempty"`       // Takes precedence over `to`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -122,30 +473,60 @@ func (x *ListDataByTimeRangeRequest) GetTo() string {
 	return ""
 }
 
-// Single response or part of packet response
-type Data struct {
+func (x *ListDataByTimeRangeRequest) GetSeries() string {
+	if x != nil {
+		return x.Series
+	}
+	return ""
+}
+
+func (x *ListDataByTimeRangeRequest) GetMatchers() []*LabelMatcher {
+	if x != nil {
+		return x.Matchers
+	}
+	return nil
+}
+
+func (x *ListDataByTimeRangeRequest) GetFromTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FromTime
+	}
+	return nil
+}
+
+func (x *ListDataByTimeRangeRequest) GetToTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ToTime
+	}
+	return nil
+}
+
+// Typed request of packet
+type ListDataByTimeRangeRequestV2 struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Timestamp     int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Max           int32                  `protobuf:"varint,3,opt,name=max,proto3" json:"max,omitempty"`
+	From          int64                  `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`                   // Inclusive integer timestamp in the server precision
+	To            int64                  `protobuf:"varint,2,opt,name=to,proto3" json:"to,omitempty"`                       // Inclusive integer timestamp in the server precision
+	Limit         uint32                 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`                 // Optional maximum number of items, 0 returns all
+	Order         Order                  `protobuf:"varint,4,opt,name=order,proto3,enum=data.Order" json:"order,omitempty"` // Order by timestamp, ascending by default
+	Filter        *Filter                `protobuf:"bytes,5,opt,name=filter,proto3" json:"filter,omitempty"`                // Optional series and label selector
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Data) Reset() {
-	*x = Data{}
+func (x *ListDataByTimeRangeRequestV2) Reset() {
+	*x = ListDataByTimeRangeRequestV2{}
 	mi := &file_proto_data_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Data) String() string {
+func (x *ListDataByTimeRangeRequestV2) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Data) ProtoMessage() {}
+func (*ListDataByTimeRangeRequestV2) ProtoMessage() {}
 
-func (x *Data) ProtoReflect() protoreflect.Message {
+func (x *ListDataByTimeRangeRequestV2) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_data_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -157,54 +538,69 @@ func (x *Data) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Data.ProtoReflect.Descriptor instead.
-func (*Data) Descriptor() ([]byte, []int) {
+// Deprecated: Use ListDataByTimeRangeRequestV2.ProtoReflect.Descriptor instead.
+func (*ListDataByTimeRangeRequestV2) Descriptor() ([]byte, []int) {
 	return file_proto_data_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *Data) GetId() string {
+func (x *ListDataByTimeRangeRequestV2) GetFrom() int64 {
 	if x != nil {
-		return x.Id
+		return x.From
 	}
-	return ""
+	return 0
 }
 
-func (x *Data) GetTimestamp() int64 {
+func (x *ListDataByTimeRangeRequestV2) GetTo() int64 {
 	if x != nil {
-		return x.Timestamp
+		return x.To
 	}
 	return 0
 }
 
-func (x *Data) GetMax() int32 {
+func (x *ListDataByTimeRangeRequestV2) GetLimit() uint32 {
 	if x != nil {
-		return x.Max
+		return x.Limit
 	}
 	return 0
 }
 
-// Packet response
-type ListDataByTimeRangeResponse struct {
+func (x *ListDataByTimeRangeRequestV2) GetOrder() Order {
+	if x != nil {
+		return x.Order
+	}
+	return Order_ORDER_ASC
+}
+
+func (x *ListDataByTimeRangeRequestV2) GetFilter() *Filter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+// Series and label selector
+type Filter struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	DataItems     []*Data                `protobuf:"bytes,1,rep,name=data_items,json=dataItems,proto3" json:"data_items,omitempty"`
+	Series        string                 `protobuf:"bytes,1,opt,name=series,proto3" json:"series,omitempty"`     // Optional series name
+	Matchers      []*LabelMatcher        `protobuf:"bytes,2,rep,name=matchers,proto3" json:"matchers,omitempty"` // Optional label matchers, all must match
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListDataByTimeRangeResponse) Reset() {
-	*x = ListDataByTimeRangeResponse{}
+func (x *Filter) Reset() {
+	*x = Filter{}
 	mi := &file_proto_data_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListDataByTimeRangeResponse) String() string {
+func (x *Filter) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListDataByTimeRangeResponse) ProtoMessage() {}
+func (*Filter) ProtoMessage() {}
 
-func (x *ListDataByTimeRangeResponse) ProtoReflect() protoreflect.Message {
+func (x *Filter) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_data_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -216,90 +612,1553 @@ func (x *ListDataByTimeRangeResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListDataByTimeRangeResponse.ProtoReflect.Descriptor instead.
-func (*ListDataByTimeRangeResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use Filter.ProtoReflect.Descriptor instead.
+func (*Filter) Descriptor() ([]byte, []int) {
 	return file_proto_data_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *ListDataByTimeRangeResponse) GetDataItems() []*Data {
+func (x *Filter) GetSeries() string {
 	if x != nil {
-		return x.DataItems
+		return x.Series
 	}
-	return nil
+	return ""
 }
 
-var File_proto_data_proto protoreflect.FileDescriptor
-
-const file_proto_data_proto_rawDesc = "" +
-	"\n" +
-	"\x10proto/data.proto\x12\x04data\"$\n" +
-	"\x12GetDataByIDRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"@\n" +
-	"\x1aListDataByTimeRangeRequest\x12\x12\n" +
-	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
-	"\x02to\x18\x02 \x01(\tR\x02to\"F\n" +
-	"\x04Data\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1c\n" +
-	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\x12\x10\n" +
-	"\x03max\x18\x03 \x01(\x05R\x03max\"H\n" +
-	"\x1bListDataByTimeRangeResponse\x12)\n" +
-	"\n" +
-	"data_items\x18\x01 \x03(\v2\n" +
-	".data.DataR\tdataItems2\xa6\x01\n" +
-	"\vDataService\x127\n" +
-	"\vGetDataById\x12\x18.data.GetDataByIDRequest\x1a\n" +
-	".data.Data(\x010\x01\x12^\n" +
-	"\x13ListDataByTimeRange\x12 .data.ListDataByTimeRangeRequest\x1a!.data.ListDataByTimeRangeResponse(\x010\x01B\x06Z\x04./pbb\x06proto3"
-
-var (
-	file_proto_data_proto_rawDescOnce sync.Once
-	file_proto_data_proto_rawDescData []byte
-)
+func (x *Filter) GetMatchers() []*LabelMatcher {
+	if x != nil {
+		return x.Matchers
+	}
+	return nil
+}
 
-func file_proto_data_proto_rawDescGZIP() []byte {
-	file_proto_data_proto_rawDescOnce.Do(func() {
-		file_proto_data_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_data_proto_rawDesc), len(file_proto_data_proto_rawDesc)))
-	})
-	return file_proto_data_proto_rawDescData
+// Label selector
+type LabelMatcher struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type          LabelMatcher_Type      `protobuf:"varint,2,opt,name=type,proto3,enum=data.LabelMatcher_Type" json:"type,omitempty"`
+	Value         string                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-var file_proto_data_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
-var file_proto_data_proto_goTypes = []any{
-	(*GetDataByIDRequest)(nil),          // 0: data.GetDataByIDRequest
-	(*ListDataByTimeRangeRequest)(nil),  // 1: data.ListDataByTimeRangeRequest
-	(*Data)(nil),                        // 2: data.Data
-	(*ListDataByTimeRangeResponse)(nil), // 3: data.ListDataByTimeRangeResponse
+func (x *LabelMatcher) Reset() {
+	*x = LabelMatcher{}
+	mi := &file_proto_data_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
-var file_proto_data_proto_depIdxs = []int32{
-	2, // 0: data.ListDataByTimeRangeResponse.data_items:type_name -> data.Data
-	0, // 1: data.DataService.GetDataById:input_type -> data.GetDataByIDRequest
-	1, // 2: data.DataService.ListDataByTimeRange:input_type -> data.ListDataByTimeRangeRequest
-	2, // 3: data.DataService.GetDataById:output_type -> data.Data
-	3, // 4: data.DataService.ListDataByTimeRange:output_type -> data.ListDataByTimeRangeResponse
-	3, // [3:5] is the sub-list for method output_type
-	1, // [1:3] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+
+func (x *LabelMatcher) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func init() { file_proto_data_proto_init() }
-func file_proto_data_proto_init() {
+func (*LabelMatcher) ProtoMessage() {}
+
+func (x *LabelMatcher) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_data_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LabelMatcher.ProtoReflect.Descriptor instead.
+func (*LabelMatcher) Descriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *LabelMatcher) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *LabelMatcher) GetType() LabelMatcher_Type {
+	if x != nil {
+		return x.Type
+	}
+	return LabelMatcher_EQUAL
+}
+
+func (x *LabelMatcher) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+// Single response or part of packet response
+type Data struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Timestamp int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // Integer timestamp in the server precision
+	Max       int32                  `protobuf:"varint,3,opt,name=max,proto3" json:"max,omitempty"`             // Legacy: max_value saturated to the int32 range (floats truncated), kept for old clients
+	Tenant    string                 `protobuf:"bytes,4,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	Series    string                 `protobuf:"bytes,5,opt,name=series,proto3" json:"series,omitempty"`
+	Labels    map[string]string      `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Types that are valid to be assigned to MaxValue:
+	//
+	//	*Data_MaxInt64
+	//	*Data_MaxFloat64
+	MaxValue      isData_MaxValue        `protobuf_oneof:"max_value"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=time,proto3" json:"time,omitempty"`                                        // Same instant as `timestamp`, set in API responses
+	Status        ItemStatus             `protobuf:"varint,10,opt,name=status,proto3,enum=data.ItemStatus" json:"status,omitempty"`             // Item status in GetDataById streams and batches; only `id` is set if not OK
+	Error         string                 `protobuf:"bytes,11,opt,name=error,proto3" json:"error,omitempty"`                                     // Error message if status is not OK
+	AnomalyScore  float64                `protobuf:"fixed64,12,opt,name=anomaly_score,json=anomalyScore,proto3" json:"anomaly_score,omitempty"` // Deviation of max from the recent series behavior in standard deviations, 0 during warm-up
+	Anomalous     bool                   `protobuf:"varint,13,opt,name=anomalous,proto3" json:"anomalous,omitempty"`                            // anomaly_score reached the detection threshold
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Data) Reset() {
+	*x = Data{}
+	mi := &file_proto_data_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Data) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Data) ProtoMessage() {}
+
+func (x *Data) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_data_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Data.ProtoReflect.Descriptor instead.
+func (*Data) Descriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Data) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Data) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *Data) GetMax() int32 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+func (x *Data) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+func (x *Data) GetSeries() string {
+	if x != nil {
+		return x.Series
+	}
+	return ""
+}
+
+func (x *Data) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Data) GetMaxValue() isData_MaxValue {
+	if x != nil {
+		return x.MaxValue
+	}
+	return nil
+}
+
+func (x *Data) GetMaxInt64() int64 {
+	if x != nil {
+		if x, ok := x.MaxValue.(*Data_MaxInt64); ok {
+			return x.MaxInt64
+		}
+	}
+	return 0
+}
+
+func (x *Data) GetMaxFloat64() float64 {
+	if x != nil {
+		if x, ok := x.MaxValue.(*Data_MaxFloat64); ok {
+			return x.MaxFloat64
+		}
+	}
+	return 0
+}
+
+func (x *Data) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Data) GetStatus() ItemStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ItemStatus_ITEM_STATUS_OK
+}
+
+func (x *Data) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Data) GetAnomalyScore() float64 {
+	if x != nil {
+		return x.AnomalyScore
+	}
+	return 0
+}
+
+func (x *Data) GetAnomalous() bool {
+	if x != nil {
+		return x.Anomalous
+	}
+	return false
+}
+
+type isData_MaxValue interface {
+	isData_MaxValue()
+}
+
+type Data_MaxInt64 struct {
+	MaxInt64 int64 `protobuf:"varint,7,opt,name=max_int64,json=maxInt64,proto3,oneof"`
+}
+
+type Data_MaxFloat64 struct {
+	MaxFloat64 float64 `protobuf:"fixed64,8,opt,name=max_float64,json=maxFloat64,proto3,oneof"`
+}
+
+func (*Data_MaxInt64) isData_MaxValue() {}
+
+func (*Data_MaxFloat64) isData_MaxValue() {}
+
+// Packet response
+type ListDataByTimeRangeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DataItems     []*Data                `protobuf:"bytes,1,rep,name=data_items,json=dataItems,proto3" json:"data_items,omitempty"`
+	Status        ItemStatus             `protobuf:"varint,2,opt,name=status,proto3,enum=data.ItemStatus" json:"status,omitempty"` // Request status in ListDataByTimeRange streams
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`                         // Error message if status is not OK
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDataByTimeRangeResponse) Reset() {
+	*x = ListDataByTimeRangeResponse{}
+	mi := &file_proto_data_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDataByTimeRangeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDataByTimeRangeResponse) ProtoMessage() {}
+
+func (x *ListDataByTimeRangeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_data_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDataByTimeRangeResponse.ProtoReflect.Descriptor instead.
+func (*ListDataByTimeRangeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListDataByTimeRangeResponse) GetDataItems() []*Data {
+	if x != nil {
+		return x.DataItems
+	}
+	return nil
+}
+
+func (x *ListDataByTimeRangeResponse) GetStatus() ItemStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ItemStatus_ITEM_STATUS_OK
+}
+
+func (x *ListDataByTimeRangeResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Batch of IDs to get
+type BatchGetDataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetDataRequest) Reset() {
+	*x = BatchGetDataRequest{}
+	mi := &file_proto_data_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetDataRequest) ProtoMessage() {}
+
+func (x *BatchGetDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_data_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetDataRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetDataRequest) Descriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BatchGetDataRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+// Batch response
+type BatchGetDataResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*Data                `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`                             // One item with status per requested ID, in request order
+	MissingIds    []string               `protobuf:"bytes,2,rep,name=missing_ids,json=missingIds,proto3" json:"missing_ids,omitempty"` // Requested IDs without a record
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetDataResponse) Reset() {
+	*x = BatchGetDataResponse{}
+	mi := &file_proto_data_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetDataResponse) ProtoMessage() {}
+
+func (x *BatchGetDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_data_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetDataResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetDataResponse) Descriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BatchGetDataResponse) GetItems() []*Data {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *BatchGetDataResponse) GetMissingIds() []string {
+	if x != nil {
+		return x.MissingIds
+	}
+	return nil
+}
+
+// Record to delete
+type DeleteDataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDataRequest) Reset() {
+	*x = DeleteDataRequest{}
+	mi := &file_proto_data_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDataRequest) ProtoMessage() {}
+
+func (x *DeleteDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_data_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDataRequest.ProtoReflect.Descriptor instead.
+func (*DeleteDataRequest) Descriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeleteDataRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Time range to delete (admin)
+type DeleteDataByTimeRangeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          int64                  `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"` // Inclusive integer timestamp in the server precision
+	To            int64                  `protobuf:"varint,2,opt,name=to,proto3" json:"to,omitempty"`     // Inclusive integer timestamp in the server precision
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDataByTimeRangeRequest) Reset() {
+	*x = DeleteDataByTimeRangeRequest{}
+	mi := &file_proto_data_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDataByTimeRangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDataByTimeRangeRequest) ProtoMessage() {}
+
+func (x *DeleteDataByTimeRangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_data_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDataByTimeRangeRequest.ProtoReflect.Descriptor instead.
+func (*DeleteDataByTimeRangeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeleteDataByTimeRangeRequest) GetFrom() int64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *DeleteDataByTimeRangeRequest) GetTo() int64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+// Delete result
+type DeleteDataResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deleted       int64                  `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"` // Number of removed records
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDataResponse) Reset() {
+	*x = DeleteDataResponse{}
+	mi := &file_proto_data_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDataResponse) ProtoMessage() {}
+
+func (x *DeleteDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_data_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDataResponse.ProtoReflect.Descriptor instead.
+func (*DeleteDataResponse) Descriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DeleteDataResponse) GetDeleted() int64 {
+	if x != nil {
+		return x.Deleted
+	}
+	return 0
+}
+
+// Live subscription to newly stored records
+type SubscribeDataRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Filter             *Filter                `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`                       // Optional series and label selector
+	MinMax             *float64               `protobuf:"fixed64,2,opt,name=min_max,json=minMax,proto3,oneof" json:"min_max,omitempty"` // Only records with max >= min_max
+	SlowConsumerPolicy SlowConsumerPolicy     `protobuf:"varint,3,opt,name=slow_consumer_policy,json=slowConsumerPolicy,proto3,enum=data.SlowConsumerPolicy" json:"slow_consumer_policy,omitempty"`
+	BufferSize         uint32                 `protobuf:"varint,4,opt,name=buffer_size,json=bufferSize,proto3" json:"buffer_size,omitempty"` // Records buffered for the subscriber, 0 or above the server maximum uses the maximum
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *SubscribeDataRequest) Reset() {
+	*x = SubscribeDataRequest{}
+	mi := &file_proto_data_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeDataRequest) ProtoMessage() {}
+
+func (x *SubscribeDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_data_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeDataRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeDataRequest) Descriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SubscribeDataRequest) GetFilter() *Filter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *SubscribeDataRequest) GetMinMax() float64 {
+	if x != nil && x.MinMax != nil {
+		return *x.MinMax
+	}
+	return 0
+}
+
+func (x *SubscribeDataRequest) GetSlowConsumerPolicy() SlowConsumerPolicy {
+	if x != nil {
+		return x.SlowConsumerPolicy
+	}
+	return SlowConsumerPolicy_SLOW_CONSUMER_POLICY_DROP
+}
+
+func (x *SubscribeDataRequest) GetBufferSize() uint32 {
+	if x != nil {
+		return x.BufferSize
+	}
+	return 0
+}
+
+// Downsampled range query
+type DownsampleDataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          int64                  `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`     // Inclusive integer timestamp in the server precision
+	To            int64                  `protobuf:"varint,2,opt,name=to,proto3" json:"to,omitempty"`         // Inclusive integer timestamp in the server precision
+	Points        uint32                 `protobuf:"varint,3,opt,name=points,proto3" json:"points,omitempty"` // Maximum records per series, 3 to 10000; 0 uses 500
+	Filter        *Filter                `protobuf:"bytes,4,opt,name=filter,proto3" json:"filter,omitempty"`  // Optional series and label selector
+	Method        DownsampleMethod       `protobuf:"varint,5,opt,name=method,proto3,enum=data.DownsampleMethod" json:"method,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownsampleDataRequest) Reset() {
+	*x = DownsampleDataRequest{}
+	mi := &file_proto_data_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownsampleDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownsampleDataRequest) ProtoMessage() {}
+
+func (x *DownsampleDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_data_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownsampleDataRequest.ProtoReflect.Descriptor instead.
+func (*DownsampleDataRequest) Descriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DownsampleDataRequest) GetFrom() int64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *DownsampleDataRequest) GetTo() int64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *DownsampleDataRequest) GetPoints() uint32 {
+	if x != nil {
+		return x.Points
+	}
+	return 0
+}
+
+func (x *DownsampleDataRequest) GetFilter() *Filter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *DownsampleDataRequest) GetMethod() DownsampleMethod {
+	if x != nil {
+		return x.Method
+	}
+	return DownsampleMethod_DOWNSAMPLE_METHOD_LTTB
+}
+
+// Window results query
+type ListWindowsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          int64                  `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`                      // Inclusive window start in the server precision
+	To            int64                  `protobuf:"varint,2,opt,name=to,proto3" json:"to,omitempty"`                          // Inclusive window start in the server precision
+	Limit         uint32                 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`                    // Optional maximum number of items, 0 returns all
+	Order         Order                  `protobuf:"varint,4,opt,name=order,proto3,enum=data.Order" json:"order,omitempty"`    // Order by window start, ascending by default
+	Filter        *Filter                `protobuf:"bytes,5,opt,name=filter,proto3" json:"filter,omitempty"`                   // Optional series and label selector
+	Kind          WindowKind             `protobuf:"varint,6,opt,name=kind,proto3,enum=data.WindowKind" json:"kind,omitempty"` // Optional window kind
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWindowsRequest) Reset() {
+	*x = ListWindowsRequest{}
+	mi := &file_proto_data_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWindowsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWindowsRequest) ProtoMessage() {}
+
+func (x *ListWindowsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_data_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWindowsRequest.ProtoReflect.Descriptor instead.
+func (*ListWindowsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListWindowsRequest) GetFrom() int64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *ListWindowsRequest) GetTo() int64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *ListWindowsRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListWindowsRequest) GetOrder() Order {
+	if x != nil {
+		return x.Order
+	}
+	return Order_ORDER_ASC
+}
+
+func (x *ListWindowsRequest) GetFilter() *Filter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *ListWindowsRequest) GetKind() WindowKind {
+	if x != nil {
+		return x.Kind
+	}
+	return WindowKind_WINDOW_KIND_UNSPECIFIED
+}
+
+// Aggregate of the max values of the records of one series within a window
+type WindowResult struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // Stable across revisions
+	Kind   WindowKind             `protobuf:"varint,2,opt,name=kind,proto3,enum=data.WindowKind" json:"kind,omitempty"`
+	Start  int64                  `protobuf:"varint,3,opt,name=start,proto3" json:"start,omitempty"` // Inclusive, in the server precision
+	End    int64                  `protobuf:"varint,4,opt,name=end,proto3" json:"end,omitempty"`     // Exclusive, in the server precision
+	Tenant string                 `protobuf:"bytes,5,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	Series string                 `protobuf:"bytes,6,opt,name=series,proto3" json:"series,omitempty"`
+	Labels map[string]string      `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Count  int64                  `protobuf:"varint,8,opt,name=count,proto3" json:"count,omitempty"`
+	// Types that are valid to be assigned to MinValue:
+	//
+	//	*WindowResult_MinInt64
+	//	*WindowResult_MinFloat64
+	MinValue isWindowResult_MinValue `protobuf_oneof:"min_value"`
+	// Types that are valid to be assigned to MaxValue:
+	//
+	//	*WindowResult_MaxInt64
+	//	*WindowResult_MaxFloat64
+	MaxValue      isWindowResult_MaxValue `protobuf_oneof:"max_value"`
+	Sum           float64                 `protobuf:"fixed64,13,opt,name=sum,proto3" json:"sum,omitempty"`
+	Avg           float64                 `protobuf:"fixed64,14,opt,name=avg,proto3" json:"avg,omitempty"`
+	Revision      uint32                  `protobuf:"varint,15,opt,name=revision,proto3" json:"revision,omitempty"`                   // 0 for the first emission, incremented by every late update
+	StartTime     *timestamppb.Timestamp  `protobuf:"bytes,16,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"` // Same instant as `start`
+	EndTime       *timestamppb.Timestamp  `protobuf:"bytes,17,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`       // Same instant as `end`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WindowResult) Reset() {
+	*x = WindowResult{}
+	mi := &file_proto_data_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WindowResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WindowResult) ProtoMessage() {}
+
+func (x *WindowResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_data_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WindowResult.ProtoReflect.Descriptor instead.
+func (*WindowResult) Descriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *WindowResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *WindowResult) GetKind() WindowKind {
+	if x != nil {
+		return x.Kind
+	}
+	return WindowKind_WINDOW_KIND_UNSPECIFIED
+}
+
+func (x *WindowResult) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *WindowResult) GetEnd() int64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *WindowResult) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+func (x *WindowResult) GetSeries() string {
+	if x != nil {
+		return x.Series
+	}
+	return ""
+}
+
+func (x *WindowResult) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *WindowResult) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *WindowResult) GetMinValue() isWindowResult_MinValue {
+	if x != nil {
+		return x.MinValue
+	}
+	return nil
+}
+
+func (x *WindowResult) GetMinInt64() int64 {
+	if x != nil {
+		if x, ok := x.MinValue.(*WindowResult_MinInt64); ok {
+			return x.MinInt64
+		}
+	}
+	return 0
+}
+
+func (x *WindowResult) GetMinFloat64() float64 {
+	if x != nil {
+		if x, ok := x.MinValue.(*WindowResult_MinFloat64); ok {
+			return x.MinFloat64
+		}
+	}
+	return 0
+}
+
+func (x *WindowResult) GetMaxValue() isWindowResult_MaxValue {
+	if x != nil {
+		return x.MaxValue
+	}
+	return nil
+}
+
+func (x *WindowResult) GetMaxInt64() int64 {
+	if x != nil {
+		if x, ok := x.MaxValue.(*WindowResult_MaxInt64); ok {
+			return x.MaxInt64
+		}
+	}
+	return 0
+}
+
+func (x *WindowResult) GetMaxFloat64() float64 {
+	if x != nil {
+		if x, ok := x.MaxValue.(*WindowResult_MaxFloat64); ok {
+			return x.MaxFloat64
+		}
+	}
+	return 0
+}
+
+func (x *WindowResult) GetSum() float64 {
+	if x != nil {
+		return x.Sum
+	}
+	return 0
+}
+
+func (x *WindowResult) GetAvg() float64 {
+	if x != nil {
+		return x.Avg
+	}
+	return 0
+}
+
+func (x *WindowResult) GetRevision() uint32 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *WindowResult) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *WindowResult) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+type isWindowResult_MinValue interface {
+	isWindowResult_MinValue()
+}
+
+type WindowResult_MinInt64 struct {
+	MinInt64 int64 `protobuf:"varint,9,opt,name=min_int64,json=minInt64,proto3,oneof"`
+}
+
+type WindowResult_MinFloat64 struct {
+	MinFloat64 float64 `protobuf:"fixed64,10,opt,name=min_float64,json=minFloat64,proto3,oneof"`
+}
+
+func (*WindowResult_MinInt64) isWindowResult_MinValue() {}
+
+func (*WindowResult_MinFloat64) isWindowResult_MinValue() {}
+
+type isWindowResult_MaxValue interface {
+	isWindowResult_MaxValue()
+}
+
+type WindowResult_MaxInt64 struct {
+	MaxInt64 int64 `protobuf:"varint,11,opt,name=max_int64,json=maxInt64,proto3,oneof"`
+}
+
+type WindowResult_MaxFloat64 struct {
+	MaxFloat64 float64 `protobuf:"fixed64,12,opt,name=max_float64,json=maxFloat64,proto3,oneof"`
+}
+
+func (*WindowResult_MaxInt64) isWindowResult_MaxValue() {}
+
+func (*WindowResult_MaxFloat64) isWindowResult_MaxValue() {}
+
+// Window results
+type ListWindowsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Windows       []*WindowResult        `protobuf:"bytes,1,rep,name=windows,proto3" json:"windows,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWindowsResponse) Reset() {
+	*x = ListWindowsResponse{}
+	mi := &file_proto_data_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWindowsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWindowsResponse) ProtoMessage() {}
+
+func (x *ListWindowsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_data_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWindowsResponse.ProtoReflect.Descriptor instead.
+func (*ListWindowsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListWindowsResponse) GetWindows() []*WindowResult {
+	if x != nil {
+		return x.Windows
+	}
+	return nil
+}
+
+// Raw input pack submitted by producers
+type Pack struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Data          []int64                `protobuf:"varint,3,rep,packed,name=data,proto3" json:"data,omitempty"` // Samples of int64 packs
+	Series        string                 `protobuf:"bytes,4,opt,name=series,proto3" json:"series,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	FloatData     []float64              `protobuf:"fixed64,6,rep,packed,name=float_data,json=floatData,proto3" json:"float_data,omitempty"`             // Samples of float64 packs
+	ValueType     ValueType              `protobuf:"varint,7,opt,name=value_type,json=valueType,proto3,enum=data.ValueType" json:"value_type,omitempty"` // Declared value type; float_data implies VALUE_TYPE_FLOAT64
+	Time          *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=time,proto3" json:"time,omitempty"`                                                 // Takes precedence over the integer `timestamp`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Pack) Reset() {
+	*x = Pack{}
+	mi := &file_proto_data_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pack) ProtoMessage() {}
+
+func (x *Pack) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_data_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pack.ProtoReflect.Descriptor instead.
+func (*Pack) Descriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Pack) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Pack) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *Pack) GetData() []int64 {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Pack) GetSeries() string {
+	if x != nil {
+		return x.Series
+	}
+	return ""
+}
+
+func (x *Pack) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Pack) GetFloatData() []float64 {
+	if x != nil {
+		return x.FloatData
+	}
+	return nil
+}
+
+func (x *Pack) GetValueType() ValueType {
+	if x != nil {
+		return x.ValueType
+	}
+	return ValueType_VALUE_TYPE_UNSPECIFIED
+}
+
+func (x *Pack) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+// Acknowledgement of a stored pack
+type IngestPackResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Status        ItemStatus             `protobuf:"varint,2,opt,name=status,proto3,enum=data.ItemStatus" json:"status,omitempty"` // Pack status, `id` is empty if not OK
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`                         // Error message if status is not OK
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IngestPackResponse) Reset() {
+	*x = IngestPackResponse{}
+	mi := &file_proto_data_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IngestPackResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestPackResponse) ProtoMessage() {}
+
+func (x *IngestPackResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_data_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestPackResponse.ProtoReflect.Descriptor instead.
+func (*IngestPackResponse) Descriptor() ([]byte, []int) {
+	return file_proto_data_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *IngestPackResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *IngestPackResponse) GetStatus() ItemStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ItemStatus_ITEM_STATUS_OK
+}
+
+func (x *IngestPackResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_proto_data_proto protoreflect.FileDescriptor
+
+const file_proto_data_proto_rawDesc = "" +
+	"\n" +
+	"\x10proto/data.proto\x12\x04data\x1a\x1fgoogle/protobuf/timestamp.proto\"$\n" +
+	"\x12GetDataByIDRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xf6\x01\n" +
+	"\x1aListDataByTimeRangeRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x16\n" +
+	"\x06series\x18\x03 \x01(\tR\x06series\x12.\n" +
+	"\bmatchers\x18\x04 \x03(\v2\x12.data.LabelMatcherR\bmatchers\x127\n" +
+	"\tfrom_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\bfromTime\x123\n" +
+	"\ato_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x06toTime\"\xa1\x01\n" +
+	"\x1cListDataByTimeRangeRequestV2\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\x03R\x02to\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\rR\x05limit\x12!\n" +
+	"\x05order\x18\x04 \x01(\x0e2\v.data.OrderR\x05order\x12$\n" +
+	"\x06filter\x18\x05 \x01(\v2\f.data.FilterR\x06filter\"P\n" +
+	"\x06Filter\x12\x16\n" +
+	"\x06series\x18\x01 \x01(\tR\x06series\x12.\n" +
+	"\bmatchers\x18\x02 \x03(\v2\x12.data.LabelMatcherR\bmatchers\"\xa1\x01\n" +
+	"\fLabelMatcher\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12+\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x17.data.LabelMatcher.TypeR\x04type\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\tR\x05value\":\n" +
+	"\x04Type\x12\t\n" +
+	"\x05EQUAL\x10\x00\x12\r\n" +
+	"\tNOT_EQUAL\x10\x01\x12\t\n" +
+	"\x05REGEX\x10\x02\x12\r\n" +
+	"\tNOT_REGEX\x10\x03\"\xe3\x03\n" +
+	"\x04Data\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\x12\x10\n" +
+	"\x03max\x18\x03 \x01(\x05R\x03max\x12\x16\n" +
+	"\x06tenant\x18\x04 \x01(\tR\x06tenant\x12\x16\n" +
+	"\x06series\x18\x05 \x01(\tR\x06series\x12.\n" +
+	"\x06labels\x18\x06 \x03(\v2\x16.data.Data.LabelsEntryR\x06labels\x12\x1d\n" +
+	"\tmax_int64\x18\a \x01(\x03H\x00R\bmaxInt64\x12!\n" +
+	"\vmax_float64\x18\b \x01(\x01H\x00R\n" +
+	"maxFloat64\x12.\n" +
+	"\x04time\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12(\n" +
+	"\x06status\x18\n" +
+	" \x01(\x0e2\x10.data.ItemStatusR\x06status\x12\x14\n" +
+	"\x05error\x18\v \x01(\tR\x05error\x12#\n" +
+	"\ranomaly_score\x18\f \x01(\x01R\fanomalyScore\x12\x1c\n" +
+	"\tanomalous\x18\r \x01(\bR\tanomalous\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\v\n" +
+	"\tmax_value\"\x88\x01\n" +
+	"\x1bListDataByTimeRangeResponse\x12)\n" +
+	"\n" +
+	"data_items\x18\x01 \x03(\v2\n" +
+	".data.DataR\tdataItems\x12(\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x10.data.ItemStatusR\x06status\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"'\n" +
+	"\x13BatchGetDataRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\"Y\n" +
+	"\x14BatchGetDataResponse\x12 \n" +
+	"\x05items\x18\x01 \x03(\v2\n" +
+	".data.DataR\x05items\x12\x1f\n" +
+	"\vmissing_ids\x18\x02 \x03(\tR\n" +
+	"missingIds\"#\n" +
+	"\x11DeleteDataRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"B\n" +
+	"\x1cDeleteDataByTimeRangeRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\x03R\x02to\".\n" +
+	"\x12DeleteDataResponse\x12\x18\n" +
+	"\adeleted\x18\x01 \x01(\x03R\adeleted\"\xd3\x01\n" +
+	"\x14SubscribeDataRequest\x12$\n" +
+	"\x06filter\x18\x01 \x01(\v2\f.data.FilterR\x06filter\x12\x1c\n" +
+	"\amin_max\x18\x02 \x01(\x01H\x00R\x06minMax\x88\x01\x01\x12J\n" +
+	"\x14slow_consumer_policy\x18\x03 \x01(\x0e2\x18.data.SlowConsumerPolicyR\x12slowConsumerPolicy\x12\x1f\n" +
+	"\vbuffer_size\x18\x04 \x01(\rR\n" +
+	"bufferSizeB\n" +
+	"\n" +
+	"\b_min_max\"\xa9\x01\n" +
+	"\x15DownsampleDataRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\x03R\x02to\x12\x16\n" +
+	"\x06points\x18\x03 \x01(\rR\x06points\x12$\n" +
+	"\x06filter\x18\x04 \x01(\v2\f.data.FilterR\x06filter\x12.\n" +
+	"\x06method\x18\x05 \x01(\x0e2\x16.data.DownsampleMethodR\x06method\"\xbd\x01\n" +
+	"\x12ListWindowsRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\x03R\x02to\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\rR\x05limit\x12!\n" +
+	"\x05order\x18\x04 \x01(\x0e2\v.data.OrderR\x05order\x12$\n" +
+	"\x06filter\x18\x05 \x01(\v2\f.data.FilterR\x06filter\x12$\n" +
+	"\x04kind\x18\x06 \x01(\x0e2\x10.data.WindowKindR\x04kind\"\xf5\x04\n" +
+	"\fWindowResult\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12$\n" +
+	"\x04kind\x18\x02 \x01(\x0e2\x10.data.WindowKindR\x04kind\x12\x14\n" +
+	"\x05start\x18\x03 \x01(\x03R\x05start\x12\x10\n" +
+	"\x03end\x18\x04 \x01(\x03R\x03end\x12\x16\n" +
+	"\x06tenant\x18\x05 \x01(\tR\x06tenant\x12\x16\n" +
+	"\x06series\x18\x06 \x01(\tR\x06series\x126\n" +
+	"\x06labels\x18\a \x03(\v2\x1e.data.WindowResult.LabelsEntryR\x06labels\x12\x14\n" +
+	"\x05count\x18\b \x01(\x03R\x05count\x12\x1d\n" +
+	"\tmin_int64\x18\t \x01(\x03H\x00R\bminInt64\x12!\n" +
+	"\vmin_float64\x18\n" +
+	" \x01(\x01H\x00R\n" +
+	"minFloat64\x12\x1d\n" +
+	"\tmax_int64\x18\v \x01(\x03H\x01R\bmaxInt64\x12!\n" +
+	"\vmax_float64\x18\f \x01(\x01H\x01R\n" +
+	"maxFloat64\x12\x10\n" +
+	"\x03sum\x18\r \x01(\x01R\x03sum\x12\x10\n" +
+	"\x03avg\x18\x0e \x01(\x01R\x03avg\x12\x1a\n" +
+	"\brevision\x18\x0f \x01(\rR\brevision\x129\n" +
+	"\n" +
+	"start_time\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\v\n" +
+	"\tmin_valueB\v\n" +
+	"\tmax_value\"C\n" +
+	"\x13ListWindowsResponse\x12,\n" +
+	"\awindows\x18\x01 \x03(\v2\x12.data.WindowResultR\awindows\"\xca\x02\n" +
+	"\x04Pack\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\x12\x12\n" +
+	"\x04data\x18\x03 \x03(\x03R\x04data\x12\x16\n" +
+	"\x06series\x18\x04 \x01(\tR\x06series\x12.\n" +
+	"\x06labels\x18\x05 \x03(\v2\x16.data.Pack.LabelsEntryR\x06labels\x12\x1d\n" +
+	"\n" +
+	"float_data\x18\x06 \x03(\x01R\tfloatData\x12.\n" +
+	"\n" +
+	"value_type\x18\a \x01(\x0e2\x0f.data.ValueTypeR\tvalueType\x12.\n" +
+	"\x04time\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"d\n" +
+	"\x12IngestPackResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12(\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x10.data.ItemStatusR\x06status\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error*k\n" +
+	"\n" +
+	"ItemStatus\x12\x12\n" +
+	"\x0eITEM_STATUS_OK\x10\x00\x12\x19\n" +
+	"\x15ITEM_STATUS_NOT_FOUND\x10\x01\x12\x17\n" +
+	"\x13ITEM_STATUS_INVALID\x10\x02\x12\x15\n" +
+	"\x11ITEM_STATUS_ERROR\x10\x03*&\n" +
+	"\x05Order\x12\r\n" +
+	"\tORDER_ASC\x10\x00\x12\x0e\n" +
+	"\n" +
+	"ORDER_DESC\x10\x01*U\n" +
+	"\tValueType\x12\x1a\n" +
+	"\x16VALUE_TYPE_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10VALUE_TYPE_INT64\x10\x01\x12\x16\n" +
+	"\x12VALUE_TYPE_FLOAT64\x10\x02*X\n" +
+	"\x12SlowConsumerPolicy\x12\x1d\n" +
+	"\x19SLOW_CONSUMER_POLICY_DROP\x10\x00\x12#\n" +
+	"\x1fSLOW_CONSUMER_POLICY_DISCONNECT\x10\x01*L\n" +
+	"\x10DownsampleMethod\x12\x1a\n" +
+	"\x16DOWNSAMPLE_METHOD_LTTB\x10\x00\x12\x1c\n" +
+	"\x18DOWNSAMPLE_METHOD_MINMAX\x10\x01*[\n" +
+	"\n" +
+	"WindowKind\x12\x1b\n" +
+	"\x17WINDOW_KIND_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13WINDOW_KIND_SLIDING\x10\x01\x12\x17\n" +
+	"\x13WINDOW_KIND_SESSION\x10\x022\xcf\a\n" +
+	"\vDataService\x127\n" +
+	"\vGetDataById\x12\x18.data.GetDataByIDRequest\x1a\n" +
+	".data.Data(\x010\x01\x12^\n" +
+	"\x13ListDataByTimeRange\x12 .data.ListDataByTimeRangeRequest\x1a!.data.ListDataByTimeRangeResponse(\x010\x01\x12b\n" +
+	"\x15ListDataByTimeRangeV2\x12\".data.ListDataByTimeRangeRequestV2\x1a!.data.ListDataByTimeRangeResponse(\x010\x01\x127\n" +
+	"\vIngestPacks\x12\n" +
+	".data.Pack\x1a\x18.data.IngestPackResponse(\x010\x01\x12/\n" +
+	"\aGetData\x12\x18.data.GetDataByIDRequest\x1a\n" +
+	".data.Data\x12E\n" +
+	"\fBatchGetData\x12\x19.data.BatchGetDataRequest\x1a\x1a.data.BatchGetDataResponse\x12Q\n" +
+	"\bListData\x12\".data.ListDataByTimeRangeRequestV2\x1a!.data.ListDataByTimeRangeResponse\x12?\n" +
+	"\n" +
+	"DeleteData\x12\x17.data.DeleteDataRequest\x1a\x18.data.DeleteDataResponse\x12U\n" +
+	"\x15DeleteDataByTimeRange\x12\".data.DeleteDataByTimeRangeRequest\x1a\x18.data.DeleteDataResponse\x129\n" +
+	"\rSubscribeData\x12\x1a.data.SubscribeDataRequest\x1a\n" +
+	".data.Data0\x01\x12V\n" +
+	"\rListAnomalies\x12\".data.ListDataByTimeRangeRequestV2\x1a!.data.ListDataByTimeRangeResponse\x12B\n" +
+	"\vListWindows\x12\x18.data.ListWindowsRequest\x1a\x19.data.ListWindowsResponse\x12P\n" +
+	"\x0eDownsampleData\x12\x1b.data.DownsampleDataRequest\x1a!.data.ListDataByTimeRangeResponseB\x06Z\x04./pbb\x06proto3"
+
+var (
+	file_proto_data_proto_rawDescOnce sync.Once
+	file_proto_data_proto_rawDescData []byte
+)
+
+func file_proto_data_proto_rawDescGZIP() []byte {
+	file_proto_data_proto_rawDescOnce.Do(func() {
+		file_proto_data_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_data_proto_rawDesc), len(file_proto_data_proto_rawDesc)))
+	})
+	return file_proto_data_proto_rawDescData
+}
+
+var file_proto_data_proto_enumTypes = make([]protoimpl.EnumInfo, 7)
+var file_proto_data_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_proto_data_proto_goTypes = []any{
+	(ItemStatus)(0),                      // 0: data.ItemStatus
+	(Order)(0),                           // 1: data.Order
+	(ValueType)(0),                       // 2: data.ValueType
+	(SlowConsumerPolicy)(0),              // 3: data.SlowConsumerPolicy
+	(DownsampleMethod)(0),                // 4: data.DownsampleMethod
+	(WindowKind)(0),                      // 5: data.WindowKind
+	(LabelMatcher_Type)(0),               // 6: data.LabelMatcher.Type
+	(*GetDataByIDRequest)(nil),           // 7: data.GetDataByIDRequest
+	(*ListDataByTimeRangeRequest)(nil),   // 8: data.ListDataByTimeRangeRequest
+	(*ListDataByTimeRangeRequestV2)(nil), // 9: data.ListDataByTimeRangeRequestV2
+	(*Filter)(nil),                       // 10: data.Filter
+	(*LabelMatcher)(nil),                 // 11: data.LabelMatcher
+	(*Data)(nil),                         // 12: data.Data
+	(*ListDataByTimeRangeResponse)(nil),  // 13: data.ListDataByTimeRangeResponse
+	(*BatchGetDataRequest)(nil),          // 14: data.BatchGetDataRequest
+	(*BatchGetDataResponse)(nil),         // 15: data.BatchGetDataResponse
+	(*DeleteDataRequest)(nil),            // 16: data.DeleteDataRequest
+	(*DeleteDataByTimeRangeRequest)(nil), // 17: data.DeleteDataByTimeRangeRequest
+	(*DeleteDataResponse)(nil),           // 18: data.DeleteDataResponse
+	(*SubscribeDataRequest)(nil),         // 19: data.SubscribeDataRequest
+	(*DownsampleDataRequest)(nil),        // 20: data.DownsampleDataRequest
+	(*ListWindowsRequest)(nil),           // 21: data.ListWindowsRequest
+	(*WindowResult)(nil),                 // 22: data.WindowResult
+	(*ListWindowsResponse)(nil),          // 23: data.ListWindowsResponse
+	(*Pack)(nil),                         // 24: data.Pack
+	(*IngestPackResponse)(nil),           // 25: data.IngestPackResponse
+	nil,                                  // 26: data.Data.LabelsEntry
+	nil,                                  // 27: data.WindowResult.LabelsEntry
+	nil,                                  // 28: data.Pack.LabelsEntry
+	(*timestamppb.Timestamp)(nil),        // 29: google.protobuf.Timestamp
+}
+var file_proto_data_proto_depIdxs = []int32{
+	11, // 0: data.ListDataByTimeRangeRequest.matchers:type_name -> data.LabelMatcher
+	29, // 1: data.ListDataByTimeRangeRequest.from_time:type_name -> google.protobuf.Timestamp
+	29, // 2: data.ListDataByTimeRangeRequest.to_time:type_name -> google.protobuf.Timestamp
+	1,  // 3: data.ListDataByTimeRangeRequestV2.order:type_name -> data.Order
+	10, // 4: data.ListDataByTimeRangeRequestV2.filter:type_name -> data.Filter
+	11, // 5: data.Filter.matchers:type_name -> data.LabelMatcher
+	6,  // 6: data.LabelMatcher.type:type_name -> data.LabelMatcher.Type
+	26, // 7: data.Data.labels:type_name -> data.Data.LabelsEntry
+	29, // 8: data.Data.time:type_name -> google.protobuf.Timestamp
+	0,  // 9: data.Data.status:type_name -> data.ItemStatus
+	12, // 10: data.ListDataByTimeRangeResponse.data_items:type_name -> data.Data
+	0,  // 11: data.ListDataByTimeRangeResponse.status:type_name -> data.ItemStatus
+	12, // 12: data.BatchGetDataResponse.items:type_name -> data.Data
+	10, // 13: data.SubscribeDataRequest.filter:type_name -> data.Filter
+	3,  // 14: data.SubscribeDataRequest.slow_consumer_policy:type_name -> data.SlowConsumerPolicy
+	10, // 15: data.DownsampleDataRequest.filter:type_name -> data.Filter
+	4,  // 16: data.DownsampleDataRequest.method:type_name -> data.DownsampleMethod
+	1,  // 17: data.ListWindowsRequest.order:type_name -> data.Order
+	10, // 18: data.ListWindowsRequest.filter:type_name -> data.Filter
+	5,  // 19: data.ListWindowsRequest.kind:type_name -> data.WindowKind
+	5,  // 20: data.WindowResult.kind:type_name -> data.WindowKind
+	27, // 21: data.WindowResult.labels:type_name -> data.WindowResult.LabelsEntry
+	29, // 22: data.WindowResult.start_time:type_name -> google.protobuf.Timestamp
+	29, // 23: data.WindowResult.end_time:type_name -> google.protobuf.Timestamp
+	22, // 24: data.ListWindowsResponse.windows:type_name -> data.WindowResult
+	28, // 25: data.Pack.labels:type_name -> data.Pack.LabelsEntry
+	2,  // 26: data.Pack.value_type:type_name -> data.ValueType
+	29, // 27: data.Pack.time:type_name -> google.protobuf.Timestamp
+	0,  // 28: data.IngestPackResponse.status:type_name -> data.ItemStatus
+	7,  // 29: data.DataService.GetDataById:input_type -> data.GetDataByIDRequest
+	8,  // 30: data.DataService.ListDataByTimeRange:input_type -> data.ListDataByTimeRangeRequest
+	9,  // 31: data.DataService.ListDataByTimeRangeV2:input_type -> data.ListDataByTimeRangeRequestV2
+	24, // 32: data.DataService.IngestPacks:input_type -> data.Pack
+	7,  // 33: data.DataService.GetData:input_type -> data.GetDataByIDRequest
+	14, // 34: data.DataService.BatchGetData:input_type -> data.BatchGetDataRequest
+	9,  // 35: data.DataService.ListData:input_type -> data.ListDataByTimeRangeRequestV2
+	16, // 36: data.DataService.DeleteData:input_type -> data.DeleteDataRequest
+	17, // 37: data.DataService.DeleteDataByTimeRange:input_type -> data.DeleteDataByTimeRangeRequest
+	19, // 38: data.DataService.SubscribeData:input_type -> data.SubscribeDataRequest
+	9,  // 39: data.DataService.ListAnomalies:input_type -> data.ListDataByTimeRangeRequestV2
+	21, // 40: data.DataService.ListWindows:input_type -> data.ListWindowsRequest
+	20, // 41: data.DataService.DownsampleData:input_type -> data.DownsampleDataRequest
+	12, // 42: data.DataService.GetDataById:output_type -> data.Data
+	13, // 43: data.DataService.ListDataByTimeRange:output_type -> data.ListDataByTimeRangeResponse
+	13, // 44: data.DataService.ListDataByTimeRangeV2:output_type -> data.ListDataByTimeRangeResponse
+	25, // 45: data.DataService.IngestPacks:output_type -> data.IngestPackResponse
+	12, // 46: data.DataService.GetData:output_type -> data.Data
+	15, // 47: data.DataService.BatchGetData:output_type -> data.BatchGetDataResponse
+	13, // 48: data.DataService.ListData:output_type -> data.ListDataByTimeRangeResponse
+	18, // 49: data.DataService.DeleteData:output_type -> data.DeleteDataResponse
+	18, // 50: data.DataService.DeleteDataByTimeRange:output_type -> data.DeleteDataResponse
+	12, // 51: data.DataService.SubscribeData:output_type -> data.Data
+	13, // 52: data.DataService.ListAnomalies:output_type -> data.ListDataByTimeRangeResponse
+	23, // 53: data.DataService.ListWindows:output_type -> data.ListWindowsResponse
+	13, // 54: data.DataService.DownsampleData:output_type -> data.ListDataByTimeRangeResponse
+	42, // [42:55] is the sub-list for method output_type
+	29, // [29:42] is the sub-list for method input_type
+	29, // [29:29] is the sub-list for extension type_name
+	29, // [29:29] is the sub-list for extension extendee
+	0,  // [0:29] is the sub-list for field type_name
+}
+
+func init() { file_proto_data_proto_init() }
+func file_proto_data_proto_init() {
 	if File_proto_data_proto != nil {
 		return
 	}
+	file_proto_data_proto_msgTypes[5].OneofWrappers = []any{
+		(*Data_MaxInt64)(nil),
+		(*Data_MaxFloat64)(nil),
+	}
+	file_proto_data_proto_msgTypes[12].OneofWrappers = []any{}
+	file_proto_data_proto_msgTypes[15].OneofWrappers = []any{
+		(*WindowResult_MinInt64)(nil),
+		(*WindowResult_MinFloat64)(nil),
+		(*WindowResult_MaxInt64)(nil),
+		(*WindowResult_MaxFloat64)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_data_proto_rawDesc), len(file_proto_data_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   4,
+			NumEnums:      7,
+			NumMessages:   22,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_data_proto_goTypes,
 		DependencyIndexes: file_proto_data_proto_depIdxs,
+		EnumInfos:         file_proto_data_proto_enumTypes,
 		MessageInfos:      file_proto_data_proto_msgTypes,
 	}.Build()
 	File_proto_data_proto = out.File
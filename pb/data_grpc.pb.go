@@ -22,8 +22,19 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	DataService_GetDataById_FullMethodName         = "/data.DataService/GetDataById"
-	DataService_ListDataByTimeRange_FullMethodName = "/data.DataService/ListDataByTimeRange"
+	DataService_GetDataById_FullMethodName           = "/data.DataService/GetDataById"
+	DataService_ListDataByTimeRange_FullMethodName   = "/data.DataService/ListDataByTimeRange"
+	DataService_ListDataByTimeRangeV2_FullMethodName = "/data.DataService/ListDataByTimeRangeV2"
+	DataService_IngestPacks_FullMethodName           = "/data.DataService/IngestPacks"
+	DataService_GetData_FullMethodName               = "/data.DataService/GetData"
+	DataService_BatchGetData_FullMethodName          = "/data.DataService/BatchGetData"
+	DataService_ListData_FullMethodName              = "/data.DataService/ListData"
+	DataService_DeleteData_FullMethodName            = "/data.DataService/DeleteData"
+	DataService_DeleteDataByTimeRange_FullMethodName = "/data.DataService/DeleteDataByTimeRange"
+	DataService_SubscribeData_FullMethodName         = "/data.DataService/SubscribeData"
+	DataService_ListAnomalies_FullMethodName         = "/data.DataService/ListAnomalies"
+	DataService_ListWindows_FullMethodName           = "/data.DataService/ListWindows"
+	DataService_DownsampleData_FullMethodName        = "/data.DataService/DownsampleData"
 )
 
 // DataServiceClient is the client API for DataService service.
@@ -32,6 +43,17 @@ const (
 type DataServiceClient interface {
 	GetDataById(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[GetDataByIDRequest, Data], error)
 	ListDataByTimeRange(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ListDataByTimeRangeRequest, ListDataByTimeRangeResponse], error)
+	ListDataByTimeRangeV2(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ListDataByTimeRangeRequestV2, ListDataByTimeRangeResponse], error)
+	IngestPacks(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Pack, IngestPackResponse], error)
+	GetData(ctx context.Context, in *GetDataByIDRequest, opts ...grpc.CallOption) (*Data, error)
+	BatchGetData(ctx context.Context, in *BatchGetDataRequest, opts ...grpc.CallOption) (*BatchGetDataResponse, error)
+	ListData(ctx context.Context, in *ListDataByTimeRangeRequestV2, opts ...grpc.CallOption) (*ListDataByTimeRangeResponse, error)
+	DeleteData(ctx context.Context, in *DeleteDataRequest, opts ...grpc.CallOption) (*DeleteDataResponse, error)
+	DeleteDataByTimeRange(ctx context.Context, in *DeleteDataByTimeRangeRequest, opts ...grpc.CallOption) (*DeleteDataResponse, error)
+	SubscribeData(ctx context.Context, in *SubscribeDataRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Data], error)
+	ListAnomalies(ctx context.Context, in *ListDataByTimeRangeRequestV2, opts ...grpc.CallOption) (*ListDataByTimeRangeResponse, error)
+	ListWindows(ctx context.Context, in *ListWindowsRequest, opts ...grpc.CallOption) (*ListWindowsResponse, error)
+	DownsampleData(ctx context.Context, in *DownsampleDataRequest, opts ...grpc.CallOption) (*ListDataByTimeRangeResponse, error)
 }
 
 type dataServiceClient struct {
@@ -68,12 +90,148 @@ func (c *dataServiceClient) ListDataByTimeRange(ctx context.Context, opts ...grp
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type DataService_ListDataByTimeRangeClient = grpc.BidiStreamingClient[ListDataByTimeRangeRequest, ListDataByTimeRangeResponse]
 
+func (c *dataServiceClient) ListDataByTimeRangeV2(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ListDataByTimeRangeRequestV2, ListDataByTimeRangeResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DataService_ServiceDesc.Streams[2], DataService_ListDataByTimeRangeV2_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListDataByTimeRangeRequestV2, ListDataByTimeRangeResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DataService_ListDataByTimeRangeV2Client = grpc.BidiStreamingClient[ListDataByTimeRangeRequestV2, ListDataByTimeRangeResponse]
+
+func (c *dataServiceClient) IngestPacks(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Pack, IngestPackResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DataService_ServiceDesc.Streams[3], DataService_IngestPacks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[Pack, IngestPackResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DataService_IngestPacksClient = grpc.BidiStreamingClient[Pack, IngestPackResponse]
+
+func (c *dataServiceClient) GetData(ctx context.Context, in *GetDataByIDRequest, opts ...grpc.CallOption) (*Data, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Data)
+	err := c.cc.Invoke(ctx, DataService_GetData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataServiceClient) BatchGetData(ctx context.Context, in *BatchGetDataRequest, opts ...grpc.CallOption) (*BatchGetDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetDataResponse)
+	err := c.cc.Invoke(ctx, DataService_BatchGetData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataServiceClient) ListData(ctx context.Context, in *ListDataByTimeRangeRequestV2, opts ...grpc.CallOption) (*ListDataByTimeRangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDataByTimeRangeResponse)
+	err := c.cc.Invoke(ctx, DataService_ListData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataServiceClient) DeleteData(ctx context.Context, in *DeleteDataRequest, opts ...grpc.CallOption) (*DeleteDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteDataResponse)
+	err := c.cc.Invoke(ctx, DataService_DeleteData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataServiceClient) DeleteDataByTimeRange(ctx context.Context, in *DeleteDataByTimeRangeRequest, opts ...grpc.CallOption) (*DeleteDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteDataResponse)
+	err := c.cc.Invoke(ctx, DataService_DeleteDataByTimeRange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataServiceClient) SubscribeData(ctx context.Context, in *SubscribeDataRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Data], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DataService_ServiceDesc.Streams[4], DataService_SubscribeData_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeDataRequest, Data]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DataService_SubscribeDataClient = grpc.ServerStreamingClient[Data]
+
+func (c *dataServiceClient) ListAnomalies(ctx context.Context, in *ListDataByTimeRangeRequestV2, opts ...grpc.CallOption) (*ListDataByTimeRangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDataByTimeRangeResponse)
+	err := c.cc.Invoke(ctx, DataService_ListAnomalies_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataServiceClient) ListWindows(ctx context.Context, in *ListWindowsRequest, opts ...grpc.CallOption) (*ListWindowsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWindowsResponse)
+	err := c.cc.Invoke(ctx, DataService_ListWindows_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataServiceClient) DownsampleData(ctx context.Context, in *DownsampleDataRequest, opts ...grpc.CallOption) (*ListDataByTimeRangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDataByTimeRangeResponse)
+	err := c.cc.Invoke(ctx, DataService_DownsampleData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DataServiceServer is the server API for DataService service.
 // All implementations must embed UnimplementedDataServiceServer
 // for forward compatibility.
 type DataServiceServer interface {
 	GetDataById(grpc.BidiStreamingServer[GetDataByIDRequest, Data]) error
 	ListDataByTimeRange(grpc.BidiStreamingServer[ListDataByTimeRangeRequest, ListDataByTimeRangeResponse]) error
+	ListDataByTimeRangeV2(grpc.BidiStreamingServer[ListDataByTimeRangeRequestV2, ListDataByTimeRangeResponse]) error
+	IngestPacks(grpc.BidiStreamingServer[Pack, IngestPackResponse]) error
+	GetData(context.Context, *GetDataByIDRequest) (*Data, error)
+	BatchGetData(context.Context, *BatchGetDataRequest) (*BatchGetDataResponse, error)
+	ListData(context.Context, *ListDataByTimeRangeRequestV2) (*ListDataByTimeRangeResponse, error)
+	DeleteData(context.Context, *DeleteDataRequest) (*DeleteDataResponse, error)
+	DeleteDataByTimeRange(context.Context, *DeleteDataByTimeRangeRequest) (*DeleteDataResponse, error)
+	SubscribeData(*SubscribeDataRequest, grpc.ServerStreamingServer[Data]) error
+	ListAnomalies(context.Context, *ListDataByTimeRangeRequestV2) (*ListDataByTimeRangeResponse, error)
+	ListWindows(context.Context, *ListWindowsRequest) (*ListWindowsResponse, error)
+	DownsampleData(context.Context, *DownsampleDataRequest) (*ListDataByTimeRangeResponse, error)
 	mustEmbedUnimplementedDataServiceServer()
 }
 
@@ -90,6 +248,39 @@ func (UnimplementedDataServiceServer) GetDataById(grpc.BidiStreamingServer[GetDa
 func (UnimplementedDataServiceServer) ListDataByTimeRange(grpc.BidiStreamingServer[ListDataByTimeRangeRequest, ListDataByTimeRangeResponse]) error {
 	return status.Errorf(codes.Unimplemented, "method ListDataByTimeRange not implemented")
 }
+func (UnimplementedDataServiceServer) ListDataByTimeRangeV2(grpc.BidiStreamingServer[ListDataByTimeRangeRequestV2, ListDataByTimeRangeResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ListDataByTimeRangeV2 not implemented")
+}
+func (UnimplementedDataServiceServer) IngestPacks(grpc.BidiStreamingServer[Pack, IngestPackResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method IngestPacks not implemented")
+}
+func (UnimplementedDataServiceServer) GetData(context.Context, *GetDataByIDRequest) (*Data, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetData not implemented")
+}
+func (UnimplementedDataServiceServer) BatchGetData(context.Context, *BatchGetDataRequest) (*BatchGetDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetData not implemented")
+}
+func (UnimplementedDataServiceServer) ListData(context.Context, *ListDataByTimeRangeRequestV2) (*ListDataByTimeRangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListData not implemented")
+}
+func (UnimplementedDataServiceServer) DeleteData(context.Context, *DeleteDataRequest) (*DeleteDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteData not implemented")
+}
+func (UnimplementedDataServiceServer) DeleteDataByTimeRange(context.Context, *DeleteDataByTimeRangeRequest) (*DeleteDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteDataByTimeRange not implemented")
+}
+func (UnimplementedDataServiceServer) SubscribeData(*SubscribeDataRequest, grpc.ServerStreamingServer[Data]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeData not implemented")
+}
+func (UnimplementedDataServiceServer) ListAnomalies(context.Context, *ListDataByTimeRangeRequestV2) (*ListDataByTimeRangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAnomalies not implemented")
+}
+func (UnimplementedDataServiceServer) ListWindows(context.Context, *ListWindowsRequest) (*ListWindowsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWindows not implemented")
+}
+func (UnimplementedDataServiceServer) DownsampleData(context.Context, *DownsampleDataRequest) (*ListDataByTimeRangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DownsampleData not implemented")
+}
 func (UnimplementedDataServiceServer) mustEmbedUnimplementedDataServiceServer() {}
 func (UnimplementedDataServiceServer) testEmbeddedByValue()                     {}
 
@@ -125,13 +316,215 @@ func _DataService_ListDataByTimeRange_Handler(srv interface{}, stream grpc.Serve
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type DataService_ListDataByTimeRangeServer = grpc.BidiStreamingServer[ListDataByTimeRangeRequest, ListDataByTimeRangeResponse]
 
+func _DataService_ListDataByTimeRangeV2_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DataServiceServer).ListDataByTimeRangeV2(&grpc.GenericServerStream[ListDataByTimeRangeRequestV2, ListDataByTimeRangeResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DataService_ListDataByTimeRangeV2Server = grpc.BidiStreamingServer[ListDataByTimeRangeRequestV2, ListDataByTimeRangeResponse]
+
+func _DataService_IngestPacks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DataServiceServer).IngestPacks(&grpc.GenericServerStream[Pack, IngestPackResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DataService_IngestPacksServer = grpc.BidiStreamingServer[Pack, IngestPackResponse]
+
+func _DataService_GetData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDataByIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataServiceServer).GetData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataService_GetData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataServiceServer).GetData(ctx, req.(*GetDataByIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataService_BatchGetData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataServiceServer).BatchGetData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataService_BatchGetData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataServiceServer).BatchGetData(ctx, req.(*BatchGetDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataService_ListData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDataByTimeRangeRequestV2)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataServiceServer).ListData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataService_ListData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataServiceServer).ListData(ctx, req.(*ListDataByTimeRangeRequestV2))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataService_DeleteData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataServiceServer).DeleteData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataService_DeleteData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataServiceServer).DeleteData(ctx, req.(*DeleteDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataService_DeleteDataByTimeRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDataByTimeRangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataServiceServer).DeleteDataByTimeRange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataService_DeleteDataByTimeRange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataServiceServer).DeleteDataByTimeRange(ctx, req.(*DeleteDataByTimeRangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataService_SubscribeData_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeDataRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DataServiceServer).SubscribeData(m, &grpc.GenericServerStream[SubscribeDataRequest, Data]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DataService_SubscribeDataServer = grpc.ServerStreamingServer[Data]
+
+func _DataService_ListAnomalies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDataByTimeRangeRequestV2)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataServiceServer).ListAnomalies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataService_ListAnomalies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataServiceServer).ListAnomalies(ctx, req.(*ListDataByTimeRangeRequestV2))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataService_ListWindows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWindowsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataServiceServer).ListWindows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataService_ListWindows_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataServiceServer).ListWindows(ctx, req.(*ListWindowsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataService_DownsampleData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DownsampleDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataServiceServer).DownsampleData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataService_DownsampleData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataServiceServer).DownsampleData(ctx, req.(*DownsampleDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DataService_ServiceDesc is the grpc.ServiceDesc for DataService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var DataService_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "data.DataService",
 	HandlerType: (*DataServiceServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetData",
+			Handler:    _DataService_GetData_Handler,
+		},
+		{
+			MethodName: "BatchGetData",
+			Handler:    _DataService_BatchGetData_Handler,
+		},
+		{
+			MethodName: "ListData",
+			Handler:    _DataService_ListData_Handler,
+		},
+		{
+			MethodName: "DeleteData",
+			Handler:    _DataService_DeleteData_Handler,
+		},
+		{
+			MethodName: "DeleteDataByTimeRange",
+			Handler:    _DataService_DeleteDataByTimeRange_Handler,
+		},
+		{
+			MethodName: "ListAnomalies",
+			Handler:    _DataService_ListAnomalies_Handler,
+		},
+		{
+			MethodName: "ListWindows",
+			Handler:    _DataService_ListWindows_Handler,
+		},
+		{
+			MethodName: "DownsampleData",
+			Handler:    _DataService_DownsampleData_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "GetDataById",
@@ -145,6 +538,23 @@ var DataService_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "ListDataByTimeRangeV2",
+			Handler:       _DataService_ListDataByTimeRangeV2_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "IngestPacks",
+			Handler:       _DataService_IngestPacks_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "SubscribeData",
+			Handler:       _DataService_SubscribeData_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "proto/data.proto",
 }
@@ -3,17 +3,20 @@
 // and managing slice operations.
 package utils
 
-import "fmt"
+import (
+	"cmp"
+	"fmt"
+)
 
-// GetMaxValue finds the maximum value in a slice of integers.
-// This function iterates through the slice and returns the largest integer value.
+// GetMaxValue finds the maximum value in a slice of integers or floats.
+// This function iterates through the slice and returns the largest value.
 // If the slice is empty, it returns an error.
 //
 // Parameters:
-//   - Data: a slice of integers to search for the maximum value
+//   - Data: a slice of ordered values (e.g. int, int64, float64) to search for the maximum value
 //
 // Returns:
-//   - int: the maximum value found in the slice
+//   - T: the maximum value found in the slice
 //   - error: an error if the slice is empty, nil otherwise
 //
 // Example:
@@ -30,9 +33,10 @@ import "fmt"
 //	if err != nil {
 //	    // err will be "slice is empty"
 //	}
-func GetMaxValue(Data []int) (int, error) {
+func GetMaxValue[T cmp.Ordered](Data []T) (T, error) {
 	if len(Data) == 0 {
-		return 0, fmt.Errorf("slice is empty")
+		var zero T
+		return zero, fmt.Errorf("slice is empty")
 	}
 
 	maxVal := Data[0]